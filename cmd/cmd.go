@@ -13,6 +13,7 @@ import (
 
 	"github.com/getgauge/gauge/env"
 	"github.com/getgauge/gauge/execution"
+	"github.com/getgauge/gauge/execution/report"
 	"github.com/getgauge/gauge/filter"
 	"github.com/getgauge/gauge/logger"
 	"github.com/getgauge/gauge/order"
@@ -140,6 +141,7 @@ func initPackageFlags() {
 	reporter.SimpleConsoleOutput = simpleConsole
 	reporter.Verbose = verbose
 	reporter.MachineReadable = machineReadable
+	reporter.Format = consoleFormat
 	execution.MachineReadable = machineReadable
 	execution.ExecuteTags = tags
 	execution.SetTableRows(rows)
@@ -149,10 +151,14 @@ func initPackageFlags() {
 	execution.TagsToFilterForParallelRun = tagsToFilterForParallelRun
 	execution.Verbose = verbose
 	execution.Strategy = strategy
+	execution.Granularity = granularity
 	filter.ExecuteTags = tags
 	order.Sorted = sort
+	order.Order = orderBy
+	order.ShuffleScenarios = shuffleScenarios
 	filter.Distribute = group
 	filter.NumberOfExecutionStreams = streams
+	filter.TimingsFile = timingsFile
 	reporter.NumberOfExecutionStreams = streams
 	validation.HideSuggestion = hideSuggestion
 	if group != -1 {
@@ -161,6 +167,16 @@ func initPackageFlags() {
 	filter.ScenariosName = scenarios
 	execution.MaxRetriesCount = maxRetriesCount
 	execution.RetryOnlyTags = retryOnlyTags
+	report.Formats = reportFormats
+	execution.Watch = watch
+	execution.ScenarioTimeout = scenarioTimeout
+	execution.StepTimeout = stepTimeout
+	execution.DryRun = dryRun
+	execution.Coordinator = coordinator
+	execution.FailFast = failFast
+	execution.MaxFailures = maxFailures
+	execution.ChangedSince = changedSince
+	execution.MetricsAddr = metricsAddr
 }
 
 var exit = func(err error, additionalText string) {
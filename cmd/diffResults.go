@@ -0,0 +1,72 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/getgauge/gauge/execution/history"
+	"github.com/spf13/cobra"
+)
+
+const slowerByDefault = 50
+
+var (
+	diffResultsCmd = &cobra.Command{
+		Use:   "diff-results [flags] <old result file> <new result file>",
+		Short: "Compare the scenario results of two runs",
+		Long: `Compare the suite results saved by two runs, e.g. a .gauge/last_run_result or a run in .gauge/history, and list the scenarios which are newly failing, newly passing, consistently failing, added, removed or significantly slower.
+The scenarios are matched by their spec path, heading and data table row. The spec paths are taken relative to the project directory of each run, so the runs can come from different checkouts of the project.`,
+		Example: `  gauge diff-results nightly-1/last_run_result nightly-2/last_run_result
+  gauge diff-results --format markdown --slower-by 100 old/last_run_result .gauge/last_run_result > comment.md`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				exit(fmt.Errorf("gauge diff-results requires the old and the new result files"), cmd.UsageString())
+			}
+			if machineReadable {
+				diffFormat = history.JSONFormat
+			}
+			if !isValidDiffFormat(diffFormat) {
+				exit(fmt.Errorf("invalid input(%s) to --format flag. Possible options are: %s", diffFormat, strings.Join(history.Formats(), ", ")), cmd.UsageString())
+			}
+			if slowerBy < 0 {
+				exit(fmt.Errorf("--slower-by cannot be negative"), cmd.UsageString())
+			}
+			previous, err := history.ReadResult(args[0])
+			if err != nil {
+				exit(fmt.Errorf("failed to read the suite result in %s. %s", args[0], err.Error()), "")
+			}
+			latest, err := history.ReadResult(args[1])
+			if err != nil {
+				exit(fmt.Errorf("failed to read the suite result in %s. %s", args[1], err.Error()), "")
+			}
+			if err := history.PrintDiff(os.Stdout, history.NewDiff(previous, latest, slowerBy), diffFormat); err != nil {
+				exit(err, "")
+			}
+		},
+		DisableAutoGenTag: true,
+	}
+	diffFormat string
+	slowerBy   int
+)
+
+func isValidDiffFormat(format string) bool {
+	for _, f := range history.Formats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	GaugeCmd.AddCommand(diffResultsCmd)
+	diffResultsCmd.Flags().StringVarP(&diffFormat, "format", "", history.TextFormat, fmt.Sprintf("Format of the diff. Possible options are: %s", strings.Join(history.Formats(), ", ")))
+	diffResultsCmd.Flags().IntVarP(&slowerBy, "slower-by", "", slowerByDefault, "Report a scenario as slower if it took this much percent, and at least a second, more in the new run")
+}
@@ -0,0 +1,76 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/history"
+	"github.com/getgauge/gauge/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	lastRunsDefault = 10
+	topDefault      = 5
+)
+
+var (
+	historyCmd = &cobra.Command{
+		Use:   "history [flags]",
+		Short: "Show the trends in the previous executions of a gauge project",
+		Long: `Show the pass rate of the previous executions, the slowest scenarios, the new failures since the previous run and the most frequently failing steps.
+Runs are added to the history when the save_execution_history property is set to true.`,
+		Example: `  gauge history
+  gauge history --last 20 --top 10`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.SetProjectRoot(args); err != nil {
+				exit(err, cmd.UsageString())
+			}
+			if lastRuns < 1 {
+				exit(fmt.Errorf("--last should be greater than 0"), cmd.UsageString())
+			}
+			loadEnvAndReinitLogger(cmd)
+			dir := history.Dir()
+			runs, err := history.Runs(dir)
+			if err != nil {
+				exit(err, "")
+			}
+			if len(runs) == 0 {
+				logger.Infof(true, "No execution history found. Set save_execution_history = true in env/default/default.properties to save the result of each run.")
+				return
+			}
+			if len(runs) > lastRuns {
+				runs = runs[len(runs)-lastRuns:]
+			}
+			var results []*gauge_messages.ProtoSuiteResult
+			for _, r := range runs {
+				res, err := history.Load(dir, r)
+				if err != nil {
+					logger.Warningf(true, "Unable to read run %s from execution history. %s", r.ID, err.Error())
+					continue
+				}
+				results = append(results, res)
+			}
+			if err := history.PrintReport(os.Stdout, history.NewReport(runs, results, top), machineReadable); err != nil {
+				exit(err, "")
+			}
+		},
+		DisableAutoGenTag: true,
+	}
+	lastRuns int
+	top      int
+)
+
+func init() {
+	GaugeCmd.AddCommand(historyCmd)
+	historyCmd.Flags().IntVarP(&lastRuns, "last", "", lastRunsDefault, "Number of previous runs to show")
+	historyCmd.Flags().IntVarP(&top, "top", "", topDefault, "Number of slowest scenarios and failing steps to show")
+}
@@ -21,14 +21,23 @@ import (
 
 var (
 	listCmd = &cobra.Command{
-		Use:     "list [flags] [args]",
-		Short:   "List specifications, scenarios or tags for a gauge project",
-		Long:    `List specifications, scenarios or tags for a gauge project`,
-		Example: `  gauge list --tags specs`,
+		Use:   "list [flags] [args]",
+		Short: "List specifications, scenarios or tags for a gauge project",
+		Long:  `List specifications, scenarios or tags for a gauge project`,
+		Example: `  gauge list --tags specs
+  gauge list --tags --expr "smoke-* & !owner:payments" specs`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := config.SetProjectRoot(args); err != nil {
 				exit(err, cmd.UsageString())
 			}
+			if exprFlag != "" {
+				if !tagsFlag {
+					exit(fmt.Errorf("--expr can only be used along with --tags"), cmd.UsageString())
+				}
+				if err := filter.ValidateTagExpression(exprFlag); err != nil {
+					exit(err, "")
+				}
+			}
 			loadEnvAndReinitLogger(cmd)
 			specs, failed := parser.ParseSpecs(getSpecsDir(args), gauge.NewConceptDictionary(), gauge.NewBuildErrors())
 			if failed {
@@ -42,7 +51,10 @@ var (
 				logger.Info(true, "[Scenarios]")
 				listScenarios(specs, print)
 			}
-			if tagsFlag {
+			if tagsFlag && exprFlag != "" {
+				logger.Infof(true, "[Scenarios matching '%s']", exprFlag)
+				listScenariosMatchingTags(specs, exprFlag, print)
+			} else if tagsFlag {
 				logger.Info(true, "[Tags]")
 				listTags(specs, print)
 			}
@@ -55,6 +67,7 @@ var (
 	tagsFlag      bool
 	specsFlag     bool
 	scenariosFlag bool
+	exprFlag      string
 )
 
 func init() {
@@ -62,6 +75,7 @@ func init() {
 	listCmd.Flags().BoolVarP(&tagsFlag, "tags", "", false, "List the tags in projects")
 	listCmd.Flags().BoolVarP(&specsFlag, "specs", "", false, "List the specifications in projects")
 	listCmd.Flags().BoolVarP(&scenariosFlag, "scenarios", "", false, "List the scenarios in projects")
+	listCmd.Flags().StringVarP(&exprFlag, "expr", "", "", "List the scenarios matching the given tag expression. Use along with --tags")
 }
 
 type handleResult func([]string)
@@ -83,6 +97,22 @@ func listTags(s []*gauge.Specification, f handleResult) {
 	f(sortedDistinctElements(allTags))
 }
 
+func listScenariosMatchingTags(s []*gauge.Specification, tagExpression string, f handleResult) {
+	matching := []string{}
+	exp := filter.NewScenarioFilterBasedOnTags(nil, tagExpression)
+	for _, spec := range s {
+		specTags := []string{}
+		specTags = appendTags(specTags, spec.Tags)
+		tagFilter := exp.ForSpec(specTags)
+		for _, scenario := range spec.Scenarios {
+			if !tagFilter.Filter(scenario) {
+				matching = append(matching, fmt.Sprintf("%s:%d %s", spec.FileName, scenario.Heading.LineNo, scenario.Heading.Value))
+			}
+		}
+	}
+	f(matching)
+}
+
 func listScenarios(s []*gauge.Specification, f handleResult) {
 	allScenarios := filter.GetAllScenarios(s)
 	f(sortedDistinctElements(allScenarios))
@@ -35,6 +35,18 @@ func TestOnlyUniqueSceanriosAreReturned(t *testing.T) {
 	})
 }
 
+func TestScenariosMatchingTagExpressionAreReturned(t *testing.T) {
+	spec := buildTestSpecification()
+	spec.FileName = "specs/example.spec"
+	spec.Scenarios[0].Heading.LineNo = 3
+	spec.Scenarios[1].Heading.LineNo = 9
+	spec.Scenarios[1].Heading.Value = "scenario2"
+
+	listScenariosMatchingTags([]*gauge.Specification{spec}, "foo & !b*", func(res []string) {
+		verifyUniqueness(res, []string{"specs/example.spec:9 scenario2"}, t)
+	})
+}
+
 func buildTestSpecification() *gauge.Specification {
 	return &gauge.Specification{
 		Heading: &gauge.Heading{
@@ -0,0 +1,51 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution"
+	"github.com/getgauge/gauge/execution/report"
+	"github.com/spf13/cobra"
+)
+
+var mergeResultsCmd = &cobra.Command{
+	Use:   "merge-results [flags] <result files>",
+	Short: "Merge the results of the groups of a sharded execution into one",
+	Long: `Merge the suite results saved by the groups of an execution sharded across machines with 'gauge run -n <streams> -g <group>'.
+
+The merged result is sent to the reporting plugins and saved as the last run result of the project, along with the failures to execute with 'gauge run --failed'.
+The exit code is the one the execution of all the groups together would have had.`,
+	Example: `  gauge merge-results shard1/.gauge/last_run_result shard2/.gauge/last_run_result
+  gauge merge-results --report html results/*/last_run_result`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			exit(fmt.Errorf("gauge merge-results requires the result files to merge"), cmd.UsageString())
+		}
+		if err := config.SetProjectRoot([]string{}); err != nil {
+			exit(err, cmd.UsageString())
+		}
+		loadEnvAndReinitLogger(cmd)
+		report.Formats = reportFormats
+		if err := report.Validate(report.ConfiguredFormats()); err != nil {
+			exit(err, cmd.UsageString())
+		}
+		installMissingPlugins(installPlugins, false)
+		os.Exit(execution.MergeResults(args))
+	},
+	DisableAutoGenTag: true,
+}
+
+func init() {
+	GaugeCmd.AddCommand(mergeResultsCmd)
+	mergeResultsCmd.Flags().StringVarP(&environment, environmentName, "e", environmentDefault, "Specifies the environment to use")
+	mergeResultsCmd.Flags().StringSliceVarP(&reportFormats, reportName, "", []string{}, fmt.Sprintf("Generate reports without a reporting plugin. Possible options are: %s", strings.Join(report.SupportedFormats(), ", ")))
+}
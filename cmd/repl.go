@@ -0,0 +1,39 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution"
+	"github.com/spf13/cobra"
+)
+
+var replCmd = &cobra.Command{
+	Use:   "repl [flags]",
+	Short: "Interactively execute steps against the project's runner",
+	Long: `Start the language runner of the project and execute the steps typed at the prompt, printing their results, messages and screenshots.
+The data stores are kept across the steps till they are reset. Type :help at the prompt for the commands.`,
+	Example: `  gauge repl
+  gauge repl --env ci`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.SetProjectRoot(args); err != nil {
+			exit(err, cmd.UsageString())
+		}
+		loadEnvAndReinitLogger(cmd)
+		ensureScreenshotsDir()
+		installMissingPlugins(installPlugins, true)
+		execution.Repl(os.Stdin, os.Stdout)
+	},
+	DisableAutoGenTag: true,
+}
+
+func init() {
+	GaugeCmd.AddCommand(replCmd)
+	replCmd.Flags().StringVarP(&environment, environmentName, "e", environmentDefault, "Specifies the environment to use")
+}
@@ -11,13 +11,17 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 	gauge "github.com/getgauge/gauge/gauge"
 	"github.com/getgauge/gauge/config"
 	"github.com/getgauge/gauge/env"
 	"github.com/getgauge/gauge/execution"
+	"github.com/getgauge/gauge/execution/report"
 	"github.com/getgauge/gauge/execution/rerun"
 	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/order"
 	"github.com/getgauge/gauge/plugin/install"
+	"github.com/getgauge/gauge/reporter"
 	"github.com/getgauge/gauge/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -35,6 +39,7 @@ const (
 	tagsDefault            = ""
 	rowsDefault            = ""
 	strategyDefault        = "lazy"
+	granularityDefault     = "spec"
 	onlyDefault            = ""
 	groupDefault           = -1
 	maxRetriesCountDefault = 1
@@ -53,6 +58,7 @@ const (
 	tagsName            = "tags"
 	rowsName            = "table-rows"
 	strategyName        = "strategy"
+	granularityName     = "granularity"
 	groupName           = "group"
 	maxRetriesCountName = "max-retries-count"
 	retryOnlyTagsName   = "retry-only"
@@ -61,9 +67,23 @@ const (
 	failSafeName        = "fail-safe"
 	skipCommandSaveName = "skip-save"
 	scenarioName        = "scenario"
+	reportName          = "report"
+	timingsName         = "timings"
+	watchName           = "watch"
+	scenarioTimeoutName = "scenario-timeout"
+	stepTimeoutName     = "step-timeout"
+	dryRunName          = "dry-run"
+	coordinatorName     = "coordinator"
+	failFastName        = "fail-fast"
+	maxFailuresName     = "max-failures"
+	orderName           = "order"
+	changedSinceName    = "changed-since"
+	shuffleName         = "shuffle-scenarios"
+	metricsAddrName     = "metrics-addr"
+	formatName          = "format"
 )
 
-var overrideRerunFlags = []string{verboseName, simpleConsoleName, machineReadableName, dirName, logLevelName}
+var overrideRerunFlags = []string{verboseName, simpleConsoleName, machineReadableName, dirName, logLevelName, dryRunName}
 var streamsDefault = util.NumberOfCores()
 
 var (
@@ -104,6 +124,7 @@ var (
 	tagsToFilterForParallelRun string
 	rows                       string
 	strategy                   string
+	granularity                string
 	streams                    int
 	maxRetriesCount            int
 	retryOnlyTags              string
@@ -112,6 +133,20 @@ var (
 	skipCommandSave            bool
 	scenarios                  []string
 	scenarioNameDefault        []string
+	reportFormats              []string
+	timingsFile                string
+	watch                      bool
+	scenarioTimeout            time.Duration
+	stepTimeout                time.Duration
+	dryRun                     bool
+	coordinator                string
+	failFast                   bool
+	maxFailures                int
+	orderBy                    string
+	shuffleScenarios           bool
+	changedSince               string
+	metricsAddr                string
+	consoleFormat              string
 )
 
 func init() {
@@ -133,7 +168,10 @@ func init() {
 	}
 	f.IntVarP(&group, groupName, "g", groupDefault, "Specify which group of specification to execute based on -n flag")
 	f.StringVarP(&strategy, strategyName, "", strategyDefault, "Set the parallelization strategy for execution. Possible options are: `eager`, `lazy`")
+	f.StringVarP(&granularity, granularityName, "", granularityDefault, "Set the unit of work scheduled on the parallel streams. Possible options are: spec, scenario")
 	f.BoolVarP(&sort, sortName, "s", sortDefault, "Run specs in Alphabetical Order")
+	f.StringVarP(&orderBy, orderName, "", "", "Set the order in which the specs are executed. Possible options are: random[:seed], failed-first")
+	f.BoolVarP(&shuffleScenarios, shuffleName, "", false, "Shuffle the scenarios within each spec as well, when executing the specs in random order")
 	f.BoolVarP(&installPlugins, installPluginsName, "i", installPluginsDefault, "Install All Missing Plugins")
 	f.BoolVarP(&failed, failedName, "f", failedDefault, "Run only the scenarios failed in previous run. This cannot be used in conjunction with any other argument")
 	f.BoolVarP(&repeat, repeatName, "", repeatDefault, "Repeat last run. This cannot be used in conjunction with any other argument")
@@ -146,6 +184,18 @@ func init() {
 	}
 
 	f.StringArrayVar(&scenarios, scenarioName, scenarioNameDefault, "Set scenarios for running specs with scenario name")
+	f.StringVarP(&timingsFile, timingsName, "", "", "Balance specs across parallel streams and groups using the execution times saved in the given result file (e.g. .gauge/last_run_result)")
+	f.BoolVarP(&watch, watchName, "", false, "Keep the runner alive and re-run the scenarios affected by changes to specs, concepts and env properties")
+	f.DurationVarP(&scenarioTimeout, scenarioTimeoutName, "", 0, "Fail a scenario which runs longer than the given duration (e.g. 30s, 2m). Can be overridden by a timeout:<duration> tag on a spec or scenario")
+	f.DurationVarP(&stepTimeout, stepTimeoutName, "", 0, "Fail a step which runs longer than the given duration (e.g. 10s). Can be overridden by a step-timeout:<duration> tag on a spec or scenario")
+	f.BoolVarP(&failFast, failFastName, "", false, "Stop starting new specs and scenarios after the first failed scenario. The remaining scenarios are reported as skipped")
+	f.IntVarP(&maxFailures, maxFailuresName, "", 0, "Stop starting new specs and scenarios after the given number of failed scenarios. The remaining scenarios are reported as skipped")
+	f.StringVarP(&changedSince, changedSinceName, "", "", "Execute only the scenarios affected by the changes to specs, concepts and implementation files since the given git ref (e.g. main, HEAD~1)")
+	f.BoolVarP(&dryRun, dryRunName, "", false, "Print the specs, scenarios, table rows and parallel streams that would be executed after applying the filters, without starting the runner")
+	f.StringVarP(&consoleFormat, formatName, "", "", fmt.Sprintf("Print the progress of the execution in a format understood by a CI server. Possible options are: %s", strings.Join(reporter.Formats(), ", ")))
+	f.StringVarP(&metricsAddr, metricsAddrName, "", "", "Serve the progress of the execution as Prometheus metrics on the given address (e.g. :9464) while the specs run")
+	f.StringVarP(&coordinator, coordinatorName, "", "", "Serve the specs on the given address (e.g. :7000) to workers started with 'gauge worker <host:port>', and report their merged results. The workers authenticate with the token in GAUGE_COORDINATOR_TOKEN, or a generated one, but the connection is not encrypted. Only serve on a network trusted with the specs and results")
+	f.StringSliceVarP(&reportFormats, reportName, "", []string{}, fmt.Sprintf("Generate reports without a reporting plugin. Possible options are: %s", strings.Join(report.SupportedFormats(), ", ")))
 }
 
 func executeFailed(cmd *cobra.Command) {
@@ -178,6 +228,11 @@ func handleFlags(cmd *cobra.Command, args []string) {
 		}
 	})
 
+	os.Args = argsWithFlagValues(cmd, args)
+}
+
+// argsWithFlagValues replaces the values of the flags in the given args with the current values of the flags
+func argsWithFlagValues(cmd *cobra.Command, args []string) []string {
 	for i := 0; i <= len(args)-1; i++ {
 		f := lookupFlagFromArgs(cmd, args[i])
 		if f == nil {
@@ -194,7 +249,7 @@ func handleFlags(cmd *cobra.Command, args []string) {
 			}
 		}
 	}
-	os.Args = args
+	return args
 }
 
 func lookupFlagFromArgs(cmd *cobra.Command, arg string) *pflag.Flag {
@@ -231,7 +286,19 @@ func execute(cmd *cobra.Command, args []string) {
 	if parallel && tagsToFilterForParallelRun != "" && !env.AllowFilteredParallelExecution() {
 		logger.Fatal(true, "Filtered parallel execution is a experimental feature. It can be enabled via allow_filtered_parallel_execution property.")
 	}
+	if err := order.Init(); err != nil {
+		exit(err, "")
+	}
+	if order.Order != orderBy {
+		// the seed picked for the random order is saved along with the command, so that --repeat and --failed use the same order
+		if err := cmd.Flags().Set(orderName, order.Order); err == nil {
+			os.Args = argsWithFlagValues(cmd, os.Args)
+		}
+	}
 	specs := getSpecsDir(args)
+	if dryRun {
+		os.Exit(execution.ExecuteSpecs(specs))
+	}
 	rerun.SaveState(os.Args[1:], specs)
 
 	if !skipCommandSave {
@@ -0,0 +1,44 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution"
+	"github.com/spf13/cobra"
+)
+
+var workerCmd = &cobra.Command{
+	Use:   "worker [flags] <coordinator address>",
+	Short: "Execute the specs served by a coordinator",
+	Long: `Execute the specs served by a coordinator started with 'gauge run --coordinator <address>'.
+
+The worker pulls the specs one at a time, executes them with its own runner and sends the results back to the coordinator, which reports them.
+The worker should be started in a copy of the same project, with GAUGE_COORDINATOR_TOKEN set to the token of the coordinator.
+The connection to the coordinator is not encrypted.`,
+	Example: `  GAUGE_COORDINATOR_TOKEN=<token> gauge worker ci-host:7000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit(fmt.Errorf("gauge worker requires the address of the coordinator"), cmd.UsageString())
+		}
+		if err := config.SetProjectRoot([]string{}); err != nil {
+			exit(err, cmd.UsageString())
+		}
+		loadEnvAndReinitLogger(cmd)
+		ensureScreenshotsDir()
+		os.Exit(execution.ExecuteAsWorker(args[0]))
+	},
+	DisableAutoGenTag: true,
+}
+
+func init() {
+	GaugeCmd.AddCommand(workerCmd)
+	workerCmd.Flags().StringVarP(&environment, environmentName, "e", environmentDefault, "Specifies the environment to use")
+}
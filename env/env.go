@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 
 	"strings"
@@ -34,8 +35,15 @@ const (
 	// for every run.
 	OverwriteReports = "overwrite_reports"
 	// ScreenshotOnFailure indicates if failure should invoke screenshot
-	ScreenshotOnFailure = "screenshot_on_failure"
-	saveExecutionResult = "save_execution_result"
+	ScreenshotOnFailure   = "screenshot_on_failure"
+	saveExecutionResult   = "save_execution_result"
+	saveExecutionHistory  = "save_execution_history"
+	executionHistoryLimit = "execution_history_limit"
+	maxRunnerRestarts     = "max_runner_restarts"
+	otelTracesEndpoint    = "otel_traces_endpoint"
+	otelTracesFile        = "otel_traces_file"
+	// NativeReports holds the comma separated list of report formats generated by gauge itself
+	NativeReports = "native_reports"
 	// CsvDelimiter holds delimiter used to parse csv files
 	CsvDelimiter                   = "csv_delimiter"
 	allowCaseSensitiveTags         = "allow_case_sensitive_tags"
@@ -46,13 +54,27 @@ const (
 	// GaugeScreenshotsDir holds the location of screenshots dir
 	GaugeScreenshotsDir     = "gauge_screenshots_dir"
 	gaugeSpecFileExtensions = "gauge_spec_file_extensions"
+	// StreamID holds the number of the parallel stream, it is 0 outside the parallel streams
+	StreamID = "gauge_stream_id"
 )
 
 var envVars map[string]string
 var expansionVars map[string]string
 
+// rawEnvVars holds the values of the properties before the env vars in them are expanded,
+// so that the properties can be expanded again for each parallel stream.
+var rawEnvVars map[string]string
+
+// envDirs holds the paths of the environments loaded, in the order of precedence.
+var envDirs []string
+
+var streamPropertiesFile = regexp.MustCompile(`^stream-\d+\.properties$`)
+
 var currentEnvironments = []string{}
 
+// envVarsSetByGauge holds the env vars which were not set in the shell and were set by gauge from the properties files.
+var envVarsSetByGauge = make(map[string]bool)
+
 // LoadEnv first generates the map of the env vars that needs to be set.
 // It starts by populating the map with the env passed by the user in --env flag.
 // It then adds the default values of the env vars which are required by Gauge,
@@ -65,6 +87,8 @@ func LoadEnv(envName string, errorHandler properties.ErrorHandlerFunc) error {
 
 	envVars = make(map[string]string)
 	expansionVars = make(map[string]string)
+	rawEnvVars = make(map[string]string)
+	envDirs = nil
 
 	defaultEnvLoaded := false
 	for _, env := range allEnvs {
@@ -101,6 +125,19 @@ func LoadEnv(envName string, errorHandler properties.ErrorHandlerFunc) error {
 	return nil
 }
 
+// ReloadEnv unsets the env vars that were set from the properties files by a previous LoadEnv and loads the
+// given environment again, so that changes to the properties files take effect. Env vars set in the shell are retained.
+func ReloadEnv(envName string, errorHandler properties.ErrorHandlerFunc) error {
+	for name := range envVarsSetByGauge {
+		if err := os.Unsetenv(name); err != nil {
+			return fmt.Errorf("Failed to reload env. %s", err.Error())
+		}
+	}
+	envVarsSetByGauge = make(map[string]bool)
+	currentEnvironments = []string{}
+	return LoadEnv(envName, errorHandler)
+}
+
 func loadDefaultEnvVars() {
 	addEnvVar(SpecsDir, "specs")
 	addEnvVar(GaugeReportsDir, "reports")
@@ -109,6 +146,9 @@ func loadDefaultEnvVars() {
 	addEnvVar(OverwriteReports, "true")
 	addEnvVar(ScreenshotOnFailure, "true")
 	addEnvVar(saveExecutionResult, "false")
+	addEnvVar(saveExecutionHistory, "false")
+	addEnvVar(executionHistoryLimit, "50")
+	addEnvVar(maxRunnerRestarts, "3")
 	addEnvVar(CsvDelimiter, ",")
 	addEnvVar(allowMultilineStep, "false")
 	addEnvVar(allowScenarioDatatable, "false")
@@ -117,6 +157,7 @@ func loadDefaultEnvVars() {
 	addEnvVar(GaugeScreenshotsDir, defaultScreenshotDir)
 	addEnvVar(gaugeSpecFileExtensions, ".spec, .md")
 	addEnvVar(allowCaseSensitiveTags, "false")
+	addEnvVar(StreamID, "0")
 	err := os.MkdirAll(defaultScreenshotDir, 0750)
 	if err != nil {
 		logger.Warningf(true, "Could not create screenshot dir at %s", err.Error())
@@ -133,11 +174,17 @@ func loadEnvDir(envName string) error {
 	}
 	addEnvVar(GaugeEnvironment, envName)
 	logger.Debugf(true, "'%s' set to '%s'", GaugeEnvironment, envName)
+	envDirs = append(envDirs, envDirPath)
 	files := common.FindFilesInDir(envDirPath,
-		isPropertiesFile,
+		func(p string) bool { return isPropertiesFile(p) && !isStreamPropertiesFile(envDirPath, p) },
 		func(p string, f os.FileInfo) bool { return false },
 	)
 	gaugeProperties := properties.MustLoadFiles(files, properties.UTF8, false)
+	if _, ok := gaugeProperties.Map()[StreamID]; !ok {
+		if _, _, err := gaugeProperties.Set(StreamID, "0"); err != nil {
+			return fmt.Errorf("Failed to set %s. %s", StreamID, err.Error())
+		}
+	}
 	processedProperties, err := GetProcessedPropertiesMap(gaugeProperties)
 	if err != nil {
 		return fmt.Errorf("Failed to parse properties in %s. %s", envDirPath, err.Error())
@@ -175,10 +222,58 @@ func LoadEnvProperties(propertiesMap *properties.Properties) {
 				}
 			}
 		}
+		if _, ok := rawEnvVars[propertyKey]; !ok {
+			rawEnvVars[propertyKey] = propertyValue
+		}
 		addEnvVar(propertyKey, propertiesMap.GetString(propertyKey, propertyValue))
 	}
 }
 
+// StreamEnv returns the env vars which take a different value in the given parallel stream, as key=value pairs.
+// These are the properties referring to ${gauge_stream_id}, and the properties in the env/<name>/stream-N.properties
+// files of the environments, which are not loaded otherwise. The env vars set in the shell are not overridden.
+func StreamEnv(stream int) ([]string, error) {
+	values := make(map[string]string)
+	for k, v := range rawEnvVars {
+		values[k] = v
+	}
+	overridden := make(map[string]bool)
+	for _, dir := range envDirs {
+		file := filepath.Join(dir, fmt.Sprintf("stream-%d.properties", stream))
+		if !common.FileExists(file) {
+			continue
+		}
+		l := &properties.Loader{Encoding: properties.UTF8, DisableExpansion: true}
+		p, err := l.LoadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to load %s. %s", file, err.Error())
+		}
+		for k, v := range p.Map() {
+			if !overridden[k] && !setInShell(k) {
+				values[k], overridden[k] = v, true
+			}
+		}
+	}
+	values[StreamID] = strconv.Itoa(stream)
+	p := properties.NewProperties()
+	for k, v := range values {
+		if _, _, err := p.Set(k, v); err != nil {
+			return nil, fmt.Errorf("Failed to expand %s for stream %d. %s", k, stream, err.Error())
+		}
+	}
+	var vars []string
+	for k := range values {
+		if k == StreamID || setInShell(k) {
+			continue
+		}
+		if v := p.GetString(k, ""); v != os.Getenv(k) {
+			vars = append(vars, k+"="+v)
+		}
+	}
+	sort.Strings(vars)
+	return vars, nil
+}
+
 func checkEnvVarsExpanded() error {
 	for key, value := range expansionVars {
 		if _, ok := envVars[key]; ok {
@@ -235,6 +330,11 @@ func isPropertiesFile(path string) bool {
 	return filepath.Ext(path) == ".properties"
 }
 
+// isStreamPropertiesFile tells if the file holds the properties of a parallel stream, i.e. env/<name>/stream-N.properties
+func isStreamPropertiesFile(envDirPath, path string) bool {
+	return filepath.Dir(path) == filepath.Clean(envDirPath) && streamPropertiesFile.MatchString(filepath.Base(path))
+}
+
 func setEnvVars() error {
 	for name, value := range envVars {
 		if !isPropertySet(name) {
@@ -242,6 +342,7 @@ func setEnvVars() error {
 			if err != nil {
 				return fmt.Errorf("%s", err.Error())
 			}
+			envVarsSetByGauge[name] = true
 		}
 	}
 	return nil
@@ -251,6 +352,10 @@ func isPropertySet(property string) bool {
 	return len(os.Getenv(property)) > 0
 }
 
+func setInShell(property string) bool {
+	return isPropertySet(property) && !envVarsSetByGauge[property]
+}
+
 // comma-separated value of environments
 func CurrentEnvironments() string {
 	if len(currentEnvironments) == 0 {
@@ -270,6 +375,17 @@ func convertToBool(property string, defaultValue bool) bool {
 	return boolValue
 }
 
+func convertToInt(property string, defaultValue int) int {
+	v := os.Getenv(property)
+	intValue, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		logger.Warningf(true, "Incorrect value for %s in property file. Cannot convert %s to integer.", property, v)
+		logger.Warningf(true, "Using default value %v for property %s.", defaultValue, property)
+		return defaultValue
+	}
+	return intValue
+}
+
 // AllowFilteredParallelExecution - feature toggle for filtered parallel execution
 var AllowFilteredParallelExecution = func() bool {
 	return convertToBool(allowFilteredParallelExecution, false)
@@ -290,6 +406,47 @@ var SaveExecutionResult = func() bool {
 	return convertToBool(saveExecutionResult, false)
 }
 
+// SaveExecutionHistory determines if the result of each run should be added to the execution history
+var SaveExecutionHistory = func() bool {
+	return convertToBool(saveExecutionHistory, false)
+}
+
+// ExecutionHistoryLimit is the number of runs kept in the execution history
+var ExecutionHistoryLimit = func() int {
+	return convertToInt(executionHistoryLimit, 50)
+}
+
+// MaxRunnerRestarts is the number of times a runner which crashed is restarted during an execution, per parallel stream
+var MaxRunnerRestarts = func() int {
+	return convertToInt(maxRunnerRestarts, 3)
+}
+
+// OtelTracesEndpoint is the OTLP/HTTP endpoint the trace of the execution is exported to, if set
+var OtelTracesEndpoint = func() string {
+	return strings.TrimSpace(os.Getenv(otelTracesEndpoint))
+}
+
+// OtelTracesFile is the file the trace of the execution is written to in the OTLP JSON format, if set
+var OtelTracesFile = func() string {
+	return strings.TrimSpace(os.Getenv(otelTracesFile))
+}
+
+// ShouldOverwriteReports determines if reports of a previous run should be replaced
+var ShouldOverwriteReports = func() bool {
+	return convertToBool(OverwriteReports, true)
+}
+
+// NativeReportFormats returns the report formats that gauge should generate without a reporting plugin
+var NativeReportFormats = func() []string {
+	var formats []string
+	for _, f := range strings.Split(os.Getenv(NativeReports), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			formats = append(formats, f)
+		}
+	}
+	return formats
+}
+
 // EnableMultiThreadedExecution determines if threads should be used instead of process
 // for each parallel stream
 var EnableMultiThreadedExecution = func() bool {
@@ -7,6 +7,7 @@
 package env
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -380,3 +381,53 @@ func (s *MySuite) TestLoadEnvWithAcyclicProperties(c *C) {
 	c.Assert(os.Getenv("e"), Equals, "foo")
 	c.Assert(os.Getenv("f"), Equals, "foo")
 }
+
+func (s *MySuite) TestReloadEnvPicksUpChangedProperties(c *C) {
+	os.Clearenv()
+	dir, err := ioutil.TempDir("", "reload")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	envDir := filepath.Join(dir, "env", common.DefaultEnvDir)
+	c.Assert(os.MkdirAll(envDir, common.NewDirectoryPermissions), IsNil)
+	propertiesFile := filepath.Join(envDir, "default.properties")
+	c.Assert(ioutil.WriteFile(propertiesFile, []byte("base_url=http://one\nremoved=yes"), common.NewFilePermissions), IsNil)
+	os.Setenv("from_shell", "shell")
+	config.ProjectRoot = dir
+
+	c.Assert(LoadEnv(common.DefaultEnvDir, nil), IsNil)
+	c.Assert(os.Getenv("base_url"), Equals, "http://one")
+
+	c.Assert(ioutil.WriteFile(propertiesFile, []byte("base_url=http://two\nfrom_shell=properties"), common.NewFilePermissions), IsNil)
+	c.Assert(ReloadEnv(common.DefaultEnvDir, nil), IsNil)
+
+	c.Assert(os.Getenv("base_url"), Equals, "http://two")
+	c.Assert(os.Getenv("removed"), Equals, "")
+	c.Assert(os.Getenv("from_shell"), Equals, "shell")
+}
+
+func (s *MySuite) TestStreamEnvResolvesStreamIDAndStreamProperties(c *C) {
+	os.Clearenv()
+	dir, err := ioutil.TempDir("", "streams")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	envDir := filepath.Join(dir, "env", "ci")
+	c.Assert(os.MkdirAll(envDir, common.NewDirectoryPermissions), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(envDir, "ci.properties"),
+		[]byte("db_name=test_${gauge_stream_id}\ndb_url=db/${db_name}\nbrowser=chrome\nport=${gauge_stream_id}\nhost=localhost"), common.NewFilePermissions), IsNil)
+	c.Assert(ioutil.WriteFile(filepath.Join(envDir, "stream-2.properties"), []byte("browser=firefox\nhost=remote"), common.NewFilePermissions), IsNil)
+	os.Setenv("port", "8080")
+	config.ProjectRoot = dir
+
+	c.Assert(LoadEnv("ci", nil), IsNil)
+
+	c.Assert(os.Getenv("db_name"), Equals, "test_0")
+	c.Assert(os.Getenv("db_url"), Equals, "db/test_0")
+	c.Assert(os.Getenv("browser"), Equals, "chrome")
+	c.Assert(os.Getenv(StreamID), Equals, "0")
+	stream1, err := StreamEnv(1)
+	c.Assert(err, IsNil)
+	c.Assert(stream1, DeepEquals, []string{"db_name=test_1", "db_url=db/test_1"})
+	stream2, err := StreamEnv(2)
+	c.Assert(err, IsNil)
+	c.Assert(stream2, DeepEquals, []string{"browser=firefox", "db_name=test_2", "db_url=db/test_2", "host=remote"})
+}
@@ -0,0 +1,187 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"sort"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/changes"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/parser"
+	"github.com/getgauge/gauge/runner"
+	"github.com/getgauge/gauge/util"
+	"github.com/getgauge/gauge/validation"
+)
+
+// ChangedSince if set executes only the scenarios affected by the changes to the working tree since the given git ref.
+var ChangedSince string
+
+// affectedByChanges returns the specs with only the scenarios affected by the changes since ChangedSince. A scenario is
+// affected if its lines in the spec changed, or if it uses a changed concept or a step whose implementation changed.
+// All the scenarios of a spec are affected if the lines before the first scenario or after the last one changed.
+// Changes to the implementation files are not considered if the runner is nil.
+func affectedByChanges(specs []*gauge.Specification, dict *gauge.ConceptDictionary, r runner.Runner, errMap *gauge.BuildErrors) ([]*gauge.Specification, error) {
+	set, err := changes.Since(ChangedSince, config.ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+	affected := changedConcepts(set, dict)
+	if r != nil {
+		for step := range changedSteps(set, r) {
+			affected[step] = true
+		}
+	}
+	var selected []*gauge.Specification
+	for _, spec := range specs {
+		selection := changedScenarios(spec, set.Changed(absPath(spec.FileName)), affected)
+		if len(selection) == 0 {
+			continue
+		}
+		s, _ := spec.Filter(selection)
+		if errs, ok := errMap.SpecErrs[spec]; ok {
+			errMap.SpecErrs[s] = errs
+		}
+		selected = append(selected, s)
+	}
+	return selected, nil
+}
+
+func specsAffectedByChanges(res *validation.ValidationResult) *gauge.SpecCollection {
+	dict, _, err := parser.ParseConcepts()
+	if err == nil {
+		var specs []*gauge.Specification
+		if specs, err = affectedByChanges(res.SpecCollection.Specs(), dict, res.Runner, res.ErrMap); err == nil {
+			logger.Infof(true, "Executing the scenarios affected by the changes since %s.", ChangedSince)
+			return gauge.NewSpecCollection(specs, false)
+		}
+	}
+	if e := res.Runner.Kill(); e != nil {
+		logger.Errorf(false, "unable to kill runner: %s", e.Error())
+	}
+	logger.Fatalf(true, "Unable to find the changes since %s. %s", ChangedSince, err.Error())
+	return nil
+}
+
+func changedScenarios(spec *gauge.Specification, file *changes.File, affected map[string]bool) scenarioSelection {
+	all := stepsUseConcepts(spec.Contexts, affected) || stepsUseConcepts(spec.TearDownSteps, affected)
+	if file != nil && !all {
+		preambleEnd, teardownStart := math.MaxInt32, 1
+		for _, scn := range spec.Scenarios {
+			if scn.Span.Start-1 < preambleEnd {
+				preambleEnd = scn.Span.Start - 1
+			}
+			if scn.Span.End+1 > teardownStart {
+				teardownStart = scn.Span.End + 1
+			}
+		}
+		all = len(spec.Scenarios) == 0 || file.Touches(1, preambleEnd) || file.Touches(teardownStart, math.MaxInt32)
+	}
+	selection := make(scenarioSelection)
+	for _, scn := range spec.Scenarios {
+		if all || (file != nil && file.Touches(scn.Span.Start, scn.Span.End)) || stepsUseConcepts(scn.Steps, affected) {
+			selection[scn] = true
+		}
+	}
+	return selection
+}
+
+// changedConcepts returns the concepts whose definition changed, along with the concepts removed since the ref.
+// The steps using a removed concept are no longer concepts, but their scenarios are affected all the same.
+func changedConcepts(set *changes.Set, dict *gauge.ConceptDictionary) map[string]bool {
+	concepts := make(map[string]bool)
+	byFile := make(map[string][]*gauge.Concept)
+	for _, c := range dict.ConceptsMap {
+		file := absPath(c.FileName)
+		byFile[file] = append(byFile[file], c)
+	}
+	for file, cs := range byFile {
+		f := set.Changed(file)
+		if f == nil {
+			continue
+		}
+		// a concept spans till the line before the next concept in the file
+		sort.Slice(cs, func(i, j int) bool { return cs[i].ConceptStep.LineNo < cs[j].ConceptStep.LineNo })
+		for i, c := range cs {
+			end := math.MaxInt32
+			if i+1 < len(cs) {
+				end = cs[i+1].ConceptStep.LineNo - 1
+			}
+			if f.Touches(c.ConceptStep.LineNo, end) {
+				concepts[c.ConceptStep.Value] = true
+			}
+		}
+	}
+	previous := append([]string{}, set.Deleted...)
+	for file, f := range set.Files {
+		if !f.All {
+			previous = append(previous, file)
+		}
+	}
+	for _, file := range previous {
+		if !util.IsConcept(file) {
+			continue
+		}
+		text, err := set.Previous(file)
+		if err != nil {
+			logger.Debugf(true, "Unable to read %s at %s: %s", file, set.Ref, err.Error())
+			continue
+		}
+		steps, _ := new(parser.ConceptParser).Parse(text, file)
+		for _, s := range steps {
+			if _, ok := dict.ConceptsMap[s.Value]; !ok {
+				concepts[s.Value] = true
+			}
+		}
+	}
+	return concepts
+}
+
+// changedSteps asks the runner for the steps implemented in the changed implementation files, and returns the steps
+// whose implementation changed.
+func changedSteps(set *changes.Set, r runner.Runner) map[string]bool {
+	steps := make(map[string]bool)
+	res, err := r.ExecuteMessageWithTimeout(&gauge_messages.Message{MessageType: gauge_messages.Message_ImplementationFileListRequest})
+	if err != nil {
+		logger.Warningf(true, "Unable to get the implementation files from the runner, changes to them are not considered. %s", err.Error())
+		return steps
+	}
+	for _, file := range res.GetImplementationFileListResponse().GetImplementationFilePaths() {
+		file = absPath(file)
+		f := set.Changed(file)
+		if f == nil {
+			continue
+		}
+		res, err := r.ExecuteMessageWithTimeout(&gauge_messages.Message{MessageType: gauge_messages.Message_StepPositionsRequest,
+			StepPositionsRequest: &gauge_messages.StepPositionsRequest{FilePath: file}})
+		if err == nil && res.GetStepPositionsResponse().GetError() != "" {
+			err = errors.New(res.GetStepPositionsResponse().GetError())
+		}
+		if err != nil {
+			logger.Warningf(true, "Unable to get the steps implemented in %s, changes to it are not considered. %s", file, err.Error())
+			continue
+		}
+		for _, p := range res.GetStepPositionsResponse().GetStepPositions() {
+			if f.Touches(int(p.GetSpan().GetStart()), int(p.GetSpan().GetEnd())) {
+				steps[p.GetStepValue()] = true
+			}
+		}
+	}
+	return steps
+}
+
+func absPath(file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(config.ProjectRoot, file)
+}
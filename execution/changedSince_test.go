@@ -0,0 +1,71 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"testing"
+
+	"github.com/getgauge/gauge/execution/changes"
+	"github.com/getgauge/gauge/gauge"
+)
+
+// changedSpec has a context on line 3, scenarios on lines 5-7 and 9-11, and a tear down step on line 14
+func changedSpec() *gauge.Specification {
+	concept := &gauge.Step{Value: "a concept", IsConcept: true, ConceptSteps: []*gauge.Step{{Value: "nested step {}"}}}
+	return &gauge.Specification{
+		FileName:      "changed.spec",
+		Contexts:      []*gauge.Step{{Value: "context step"}},
+		TearDownSteps: []*gauge.Step{{Value: "tear down step"}},
+		Scenarios: []*gauge.Scenario{
+			{Heading: &gauge.Heading{Value: "First"}, Span: &gauge.Span{Start: 5, End: 7}, Steps: []*gauge.Step{{Value: "first step"}}},
+			{Heading: &gauge.Heading{Value: "Second"}, Span: &gauge.Span{Start: 9, End: 11}, Steps: []*gauge.Step{concept}},
+		},
+	}
+}
+
+func selected(spec *gauge.Specification, s scenarioSelection) []string {
+	var headings []string
+	for _, scn := range spec.Scenarios {
+		if s[scn] {
+			headings = append(headings, scn.Heading.Value)
+		}
+	}
+	return headings
+}
+
+func TestScenariosWithChangedLinesAreSelected(t *testing.T) {
+	spec := changedSpec()
+	got := selected(spec, changedScenarios(spec, &changes.File{Lines: [][2]int{{10, 10}}}, nil))
+	if len(got) != 1 || got[0] != "Second" {
+		t.Errorf("Expected only the changed scenario to be selected, got %v", got)
+	}
+}
+
+func TestAllScenariosAreSelectedIfContextsOrTearDownChanged(t *testing.T) {
+	spec := changedSpec()
+	for _, lines := range [][2]int{{3, 3}, {14, 14}} {
+		if got := selected(spec, changedScenarios(spec, &changes.File{Lines: [][2]int{lines}}, nil)); len(got) != 2 {
+			t.Errorf("Expected all the scenarios to be selected for a change to lines %v, got %v", lines, got)
+		}
+	}
+	if got := selected(spec, changedScenarios(spec, nil, map[string]bool{"tear down step": true})); len(got) != 2 {
+		t.Errorf("Expected all the scenarios to be selected for a changed tear down step, got %v", got)
+	}
+}
+
+func TestScenariosUsingChangedStepsAreSelected(t *testing.T) {
+	spec := changedSpec()
+	if got := selected(spec, changedScenarios(spec, nil, map[string]bool{"nested step {}": true})); len(got) != 1 || got[0] != "Second" {
+		t.Errorf("Expected the scenario using the step through a concept to be selected, got %v", got)
+	}
+	if got := selected(spec, changedScenarios(spec, nil, map[string]bool{"a concept": true})); len(got) != 1 || got[0] != "Second" {
+		t.Errorf("Expected the scenario using the concept to be selected, got %v", got)
+	}
+	if got := selected(spec, changedScenarios(spec, nil, map[string]bool{"unused step": true})); len(got) != 0 {
+		t.Errorf("Expected no scenario to be selected, got %v", got)
+	}
+}
@@ -0,0 +1,175 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+// Package changes finds the files and lines changed in the working tree since a git ref, using the local git binary.
+package changes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// File holds the lines of a file changed since the ref. All is set if the file is new, in which case every line is changed.
+type File struct {
+	All   bool
+	Lines [][2]int
+}
+
+// Touches tells if any line from start to end, both inclusive, is changed.
+func (f *File) Touches(start, end int) bool {
+	if f.All {
+		return true
+	}
+	for _, l := range f.Lines {
+		if l[0] <= end && start <= l[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// Set holds the changed and deleted files, by absolute path.
+type Set struct {
+	Ref     string
+	Files   map[string]*File
+	Deleted []string
+	root    string
+}
+
+// Since returns the changes to the files in the working tree of the repository containing dir, since the given ref.
+// Untracked files which are not ignored are considered new.
+func Since(ref, dir string) (*Set, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	s := &Set{Ref: ref, Files: make(map[string]*File), root: strings.TrimSpace(root)}
+	diff, err := git(dir, "diff", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", "--unified=0", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.parseDiff(diff); err != nil {
+		return nil, err
+	}
+	untracked, err := git(dir, "ls-files", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range strings.Split(untracked, "\n") {
+		if f = strings.TrimSpace(f); f != "" {
+			s.Files[s.abs(f)] = &File{All: true}
+		}
+	}
+	return s, nil
+}
+
+// Changed returns the changes to the given file, or nil if it did not change.
+func (s *Set) Changed(file string) *File {
+	if f, ok := s.Files[file]; ok {
+		return f
+	}
+	// the paths given by git have the symbolic links resolved
+	if resolved, err := filepath.EvalSymlinks(file); err == nil {
+		return s.Files[resolved]
+	}
+	return nil
+}
+
+// Previous returns the contents of the file at the ref. It returns an error if the file did not exist at the ref.
+func (s *Set) Previous(file string) (string, error) {
+	rel, err := filepath.Rel(s.root, file)
+	if err != nil {
+		return "", err
+	}
+	return git(s.root, "show", fmt.Sprintf("%s:%s", s.Ref, filepath.ToSlash(rel)))
+}
+
+func (s *Set) abs(file string) string {
+	return filepath.Join(s.root, filepath.FromSlash(file))
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// parseDiff reads the changed lines from a diff with no context lines. The lines removed are recorded as a change
+// to the lines around them in the new file.
+func (s *Set) parseDiff(diff string) error {
+	var current *File
+	var previous string
+	// the removed and added lines can look like the header lines, which are only read before the first hunk of a file
+	header := false
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current, header = nil, true
+		case strings.HasPrefix(line, "@@ "):
+			header = false
+			if current == nil {
+				continue
+			}
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return fmt.Errorf("invalid hunk header in git diff: %s", line)
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			if count == 0 {
+				current.Lines = append(current.Lines, [2]int{start, start + 1})
+			} else {
+				current.Lines = append(current.Lines, [2]int{start, start + count - 1})
+			}
+		case !header:
+			continue
+		case strings.HasPrefix(line, "--- "):
+			previous = unquote(strings.TrimPrefix(strings.TrimPrefix(line, "--- "), "a/"))
+		case strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if name == "/dev/null" {
+				s.Deleted = append(s.Deleted, s.abs(previous))
+				current = nil
+				continue
+			}
+			current = &File{}
+			s.Files[s.abs(unquote(strings.TrimPrefix(name, "b/")))] = current
+		}
+	}
+	return scanner.Err()
+}
+
+// unquote removes the quotes git adds around paths with special characters, along with the a/ or b/ prefix
+func unquote(name string) string {
+	if strings.HasPrefix(name, `"`) {
+		if u, err := strconv.Unquote(name); err == nil {
+			return strings.TrimPrefix(strings.TrimPrefix(u, "a/"), "b/")
+		}
+	}
+	return name
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s failed: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s failed: %s", args[0], err.Error())
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,132 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package changes
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const diff = `diff --git a/specs/a.spec b/specs/a.spec
+index 1..2 100644
+--- a/specs/a.spec
++++ b/specs/a.spec
+@@ -3 +3 @@ heading
+-* old step
++* new step
+@@ -10,2 +9,0 @@
+--- removed line which looks like a header
+-* removed step
+diff --git a/specs/b.spec b/specs/b.spec
+deleted file mode 100644
+--- a/specs/b.spec
++++ /dev/null
+@@ -1,2 +0,0 @@
+-# B
+-+++ b/not/a/file
+diff --git "a/specs/c d.spec" "b/specs/c d.spec"
+new file mode 100644
+--- /dev/null
++++ "b/specs/c d.spec"
+@@ -0,0 +1,4 @@
++# C
+`
+
+func TestParseDiff(t *testing.T) {
+	s := &Set{Files: make(map[string]*File), root: filepath.FromSlash("/project")}
+	if err := s.parseDiff(diff); err != nil {
+		t.Fatal(err)
+	}
+
+	a := s.Files[filepath.FromSlash("/project/specs/a.spec")]
+	if a == nil || !reflect.DeepEqual(a.Lines, [][2]int{{3, 3}, {9, 10}}) {
+		t.Errorf("Expected lines 3 and 9-10 of a.spec to be changed, got %v", a)
+	}
+	if len(s.Deleted) != 1 || s.Deleted[0] != filepath.FromSlash("/project/specs/b.spec") {
+		t.Errorf("Expected b.spec to be deleted, got %v", s.Deleted)
+	}
+	if c := s.Files[filepath.FromSlash("/project/specs/c d.spec")]; c == nil || !c.Touches(1, 1) || c.Touches(5, 8) {
+		t.Errorf("Expected lines 1-4 of c d.spec to be changed, got %v", c)
+	}
+	if len(s.Files) != 2 {
+		t.Errorf("Expected 2 changed files, got %v", s.Files)
+	}
+}
+
+func TestTouches(t *testing.T) {
+	f := &File{Lines: [][2]int{{5, 7}}}
+	for _, c := range []struct {
+		start, end int
+		want       bool
+	}{{1, 4, false}, {1, 5, true}, {6, 6, true}, {7, 20, true}, {8, 20, false}} {
+		if got := f.Touches(c.start, c.end); got != c.want {
+			t.Errorf("Touches(%d, %d) = %t, want %t", c.start, c.end, got, c.want)
+		}
+	}
+	if !(&File{All: true}).Touches(100, 200) {
+		t.Error("Expected all the lines of a new file to be changed")
+	}
+}
+
+func TestSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "changes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=gauge", "-c", "user.email=gauge@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s", args, out)
+		}
+	}
+	write := func(name, contents string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q")
+	write("a.spec", "# A\n\n## One\n* step\n")
+	write("b.cpt", "# Concept\n* step\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "first")
+	write("a.spec", "# A\n\n## One\n* changed step\n")
+	write("new.spec", "# New\n")
+	if err := os.Remove(filepath.Join(dir, "b.cpt")); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Since("HEAD", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a := s.Changed(filepath.Join(dir, "a.spec")); a == nil || !a.Touches(4, 4) || a.Touches(1, 3) {
+		t.Errorf("Expected line 4 of a.spec to be changed, got %v", a)
+	}
+	if n := s.Changed(filepath.Join(dir, "new.spec")); n == nil || !n.All {
+		t.Errorf("Expected the untracked file to be new, got %v", n)
+	}
+	if len(s.Deleted) != 1 || s.Deleted[0] != filepath.Join(dir, "b.cpt") {
+		t.Errorf("Expected b.cpt to be deleted, got %v", s.Deleted)
+	}
+	if text, err := s.Previous(filepath.Join(dir, "b.cpt")); err != nil || text != "# Concept\n* step\n" {
+		t.Errorf("Expected the contents of b.cpt at HEAD, got %q, %v", text, err)
+	}
+	if _, err := Since("no-such-ref", dir); err == nil {
+		t.Error("Expected an error for an unknown ref")
+	}
+}
@@ -0,0 +1,71 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"fmt"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/runner"
+)
+
+const runnerCrashedMessage = "The runner stopped unexpectedly."
+
+type crashRecoverer interface {
+	recoverFromCrash() error
+}
+
+// recoverFromCrash restarts the runner after it crashed, as long as it has not been restarted more than max_runner_restarts times.
+func (r *restartableRunner) recoverFromCrash() error {
+	if r.crashes >= env.MaxRunnerRestarts() {
+		return fmt.Errorf("the runner was already restarted %d times, the remaining specs will be skipped", r.crashes)
+	}
+	r.crashes++
+	logger.Warningf(true, "The runner stopped unexpectedly, restarting it (%d/%d).", r.crashes, env.MaxRunnerRestarts())
+	return r.restart()
+}
+
+func runnerCrashed(r runner.Runner) bool {
+	return r.Info().Killed || !r.Alive()
+}
+
+// recoverRunner restarts the runner if it crashed, so that the remaining specs can be executed.
+// It returns false if the runner is not alive and could not be restarted.
+// A runner shared by parallel streams is not restarted as the other streams are still using it, nor is a runner
+// killed after the execution was interrupted.
+func recoverRunner(r runner.Runner) bool {
+	if !runnerCrashed(r) {
+		return true
+	}
+	rr, ok := r.(crashRecoverer)
+	if !ok || isInterrupted() || (InParallel && r.IsMultithreaded()) {
+		return false
+	}
+	if err := rr.recoverFromCrash(); err != nil {
+		logger.Errorf(true, "Failed to recover from the runner crash. %s", err.Error())
+		return false
+	}
+	return true
+}
+
+// recordRunnerCrash fails the execution result of the step or hook which was in progress when the runner crashed, so that
+// the crash is reported on it. It tells if the runner crashed, in which case nothing else is to be executed by it.
+func recordRunnerCrash(r runner.Runner, res *gauge_messages.ProtoExecutionResult) bool {
+	if !runnerCrashed(r) {
+		return false
+	}
+	res.Failed = true
+	res.RecoverableError = false
+	if res.GetErrorMessage() == "" {
+		res.ErrorMessage = runnerCrashedMessage
+	} else {
+		res.ErrorMessage = fmt.Sprintf("%s %s", runnerCrashedMessage, res.GetErrorMessage())
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"os"
+	"testing"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/gauge"
+)
+
+type crashingMockRunner struct {
+	*mockRunner
+	alive      bool
+	recoveries int
+}
+
+func (r *crashingMockRunner) Alive() bool {
+	return r.alive
+}
+
+func (r *crashingMockRunner) recoverFromCrash() error {
+	r.recoveries++
+	r.alive = true
+	return nil
+}
+
+func TestRunnerCrashFailsStepInProgressAndRestartsRunner(t *testing.T) {
+	MaxRetriesCount = 1
+	step := func(text string) *gauge.Step {
+		return &gauge.Step{Value: text, LineText: text, Fragments: []*gauge_messages.Fragment{{FragmentType: gauge_messages.Fragment_Text, Text: text}}}
+	}
+	crashing, next := step("crash the runner"), step("next step")
+	scn := &gauge.Scenario{Heading: &gauge.Heading{Value: "Crashing"}, Steps: []*gauge.Step{crashing, next}, Items: []gauge.Item{crashing, next}, Tags: &gauge.Tags{}, Span: &gauge.Span{}}
+	spec := &gauge.Specification{Heading: &gauge.Heading{Value: "Crashing Spec"}, FileName: "crashing.spec", Tags: &gauge.Tags{}, Scenarios: []*gauge.Scenario{scn}}
+	r := &crashingMockRunner{mockRunner: &mockRunner{}, alive: true}
+	var executed []gauge_messages.Message_MessageType
+	r.ExecuteAndGetStatusFunc = func(m *gauge_messages.Message) *gauge_messages.ProtoExecutionResult {
+		executed = append(executed, m.MessageType)
+		if m.MessageType == gauge_messages.Message_ExecuteStep {
+			r.alive = false
+			return &gauge_messages.ProtoExecutionResult{Failed: true, ErrorMessage: "connection reset"}
+		}
+		return &gauge_messages.ProtoExecutionResult{}
+	}
+	h := &mockPluginHandler{NotifyPluginsfunc: func(m *gauge_messages.Message) {}, GracefullyKillPluginsfunc: func() {}}
+	se := newSpecExecutor(spec, r, h, gauge.NewBuildErrors(), 0)
+
+	res, _ := se.executeScenario(scn)
+
+	if !res.GetFailed() {
+		t.Error("Expected the scenario in progress to fail when the runner crashes")
+	}
+	if got := res.ProtoScenario.GetScenarioItems()[0].GetStep().GetStepExecutionResult().GetExecutionResult().GetErrorMessage(); got != runnerCrashedMessage+" connection reset" {
+		t.Errorf("Expected the crash to be reported on the step in progress, got %s", got)
+	}
+	if res.ProtoScenario.GetPostHookFailure() != nil {
+		t.Errorf("Expected no after scenario hook failure, got %s", res.ProtoScenario.GetPostHookFailure().GetErrorMessage())
+	}
+	if last := executed[len(executed)-1]; last != gauge_messages.Message_ExecuteStep {
+		t.Errorf("Expected nothing to be executed by the crashed runner, got %s", last)
+	}
+	if r.recoveries != 1 || !r.Alive() {
+		t.Errorf("Expected the runner to be restarted once, got %d", r.recoveries)
+	}
+}
+
+func TestSpecIsExecutedAfterRunnerIsRestarted(t *testing.T) {
+	r := &crashingMockRunner{mockRunner: &mockRunner{ExecuteAndGetStatusFunc: func(m *gauge_messages.Message) *gauge_messages.ProtoExecutionResult {
+		return &gauge_messages.ProtoExecutionResult{}
+	}}}
+	h := &mockPluginHandler{NotifyPluginsfunc: func(m *gauge_messages.Message) {}, GracefullyKillPluginsfunc: func() {}}
+	se := newSpecExecutor(exampleSpecWithScenarios, r, h, gauge.NewBuildErrors(), 0)
+
+	res := se.execute(true, true, true)
+
+	if res.Skipped || r.recoveries != 1 {
+		t.Errorf("Expected the spec to be executed after restarting the runner, skipped: %t, restarts: %d", res.Skipped, r.recoveries)
+	}
+}
+
+func TestRunnerIsNotRestartedBeyondLimit(t *testing.T) {
+	old := os.Getenv("max_runner_restarts")
+	os.Setenv("max_runner_restarts", "2")
+	defer os.Setenv("max_runner_restarts", old)
+	r := &restartableRunner{runner: &mockRunner{}, crashes: 2}
+
+	if err := r.recoverFromCrash(); err == nil {
+		t.Error("Expected the runner not to be restarted more than max_runner_restarts times")
+	}
+	if r.crashes != 2 {
+		t.Errorf("Expected no restart to be attempted, got %d", r.crashes)
+	}
+}
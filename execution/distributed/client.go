@@ -0,0 +1,116 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	connectTimeout = 30 * time.Second
+	oneGB          = 1024 * 1024 * 1024
+)
+
+// CallTimeout is how long a worker waits for the coordinator to respond to a call, so that a worker
+// does not hang if the coordinator does.
+var CallTimeout = time.Minute
+
+// Client is the connection of a worker to the coordinator
+type Client struct {
+	conn     *grpc.ClientConn
+	token    string
+	workerID string
+}
+
+// Dial connects to the coordinator at the given address. The token is sent with every call to authenticate the worker.
+func Dial(addr, token string) (*Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec{}), grpc.MaxCallRecvMsgSize(oneGB), grpc.MaxCallSendMsgSize(oneGB)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to coordinator at %s. %s", addr, err.Error())
+	}
+	return &Client{conn: conn, token: token}, nil
+}
+
+func (c *Client) invoke(name string, req, res interface{}) error {
+	ctx, cancel := context.WithTimeout(metadata.AppendToOutgoingContext(context.Background(), tokenKey, c.token), CallTimeout)
+	defer cancel()
+	return c.conn.Invoke(ctx, method(name), req, res)
+}
+
+// Register adds the worker to the coordinator, and returns the settings to execute the specs with
+func (c *Client) Register(name string) (*RegisterResponse, error) {
+	res := &RegisterResponse{}
+	if err := c.invoke("Register", &RegisterRequest{Name: name}, res); err != nil {
+		return nil, err
+	}
+	c.workerID = res.WorkerID
+	return res, nil
+}
+
+// Next returns the next spec to execute
+func (c *Client) Next() (*NextResponse, error) {
+	res := &NextResponse{}
+	if err := c.invoke("Next", &workerRequest{WorkerID: c.workerID}, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Complete sends the results of the given spec to the coordinator
+func (c *Client) Complete(spec *Spec, results []*gauge_messages.ProtoSpecResult) error {
+	req := &completeRequest{WorkerID: c.workerID, SpecID: spec.ID}
+	for _, r := range results {
+		b, err := proto.Marshal(r)
+		if err != nil {
+			return err
+		}
+		req.Results = append(req.Results, b)
+	}
+	return c.invoke("Complete", req, &empty{})
+}
+
+// Finish tells the coordinator that the worker is done, along with the failures of its suite hooks and any other errors
+func (c *Client) Finish(preSuite, postSuite *gauge_messages.ProtoHookFailure, errs []error) error {
+	req := &finishRequest{WorkerID: c.workerID}
+	var err error
+	if preSuite != nil {
+		if req.PreSuite, err = proto.Marshal(preSuite); err != nil {
+			return err
+		}
+	}
+	if postSuite != nil {
+		if req.PostSuite, err = proto.Marshal(postSuite); err != nil {
+			return err
+		}
+	}
+	for _, e := range errs {
+		req.Errors = append(req.Errors, e.Error())
+	}
+	return c.invoke("Finish", req, &empty{})
+}
+
+// Heartbeat tells the coordinator that the worker is alive
+func (c *Client) Heartbeat() error {
+	return c.invoke("Heartbeat", &workerRequest{WorkerID: c.workerID}, &empty{})
+}
+
+// Close closes the connection to the coordinator
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
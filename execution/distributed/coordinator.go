@@ -0,0 +1,399 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package distributed
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxAttempts is the number of workers a spec is handed to, before it is reported as not executed.
+const maxAttempts = 3
+
+var (
+	// HeartbeatInterval is how often the workers send a heartbeat
+	HeartbeatInterval = 2 * time.Second
+	// WorkerTimeout is how long the coordinator waits for a heartbeat before requeuing the specs of a worker
+	WorkerTimeout = 10 * time.Second
+	// RegistrationTimeout is how long the coordinator waits for a worker to connect while specs are left and no worker is executing them,
+	// e.g. as the workers failed to start or all of them died, before reporting the specs as not executed
+	RegistrationTimeout = 5 * time.Minute
+)
+
+// SpecResults are the results of a spec executed by a worker. A spec with a data table has a result for each row.
+type SpecResults struct {
+	Worker  string
+	Spec    *Spec
+	Results []*gauge_messages.ProtoSpecResult
+}
+
+// WorkerResult holds the suite hook failures and errors of a worker
+type WorkerResult struct {
+	Worker    string
+	PreSuite  *gauge_messages.ProtoHookFailure
+	PostSuite *gauge_messages.ProtoHookFailure
+	Errors    []string
+}
+
+// Result is the outcome of a distributed execution. Unexecuted holds the specs that could not be executed,
+// either because the workers executing it died too many times, because all the workers failed to run the before suite hook,
+// because no worker was executing them for the registration timeout, or because the execution was stopped.
+type Result struct {
+	Specs      []*SpecResults
+	Workers    []*WorkerResult
+	Unexecuted []*Spec
+}
+
+type worker struct {
+	id             string
+	name           string
+	lastSeen       time.Time
+	inFlight       map[int]*Spec
+	finished       bool
+	dead           bool
+	preSuiteFailed bool
+}
+
+// Coordinator serves the specs to the workers and collects their results
+type Coordinator struct {
+	// Completed, if set, is called with the results of each spec as the workers send them
+	Completed           func(*SpecResults)
+	mu                  sync.Mutex
+	token               string
+	tableRows           string
+	heartbeatInterval   time.Duration
+	workerTimeout       time.Duration
+	registrationTimeout time.Duration
+	idleSince           time.Time
+	queue               []*Spec
+	workers             map[string]*worker
+	pending             int
+	result              *Result
+	done                chan struct{}
+	closed              bool
+	stopped             bool
+	server              *grpc.Server
+}
+
+// NewCoordinator creates a coordinator serving the given specs to the workers authenticating with the token.
+// The table rows are passed on to the workers.
+func NewCoordinator(specs []*Spec, tableRows, token string) *Coordinator {
+	c := &Coordinator{
+		token:               token,
+		tableRows:           tableRows,
+		heartbeatInterval:   HeartbeatInterval,
+		workerTimeout:       WorkerTimeout,
+		registrationTimeout: RegistrationTimeout,
+		idleSince:           time.Now(),
+		queue:               specs,
+		workers:             make(map[string]*worker),
+		pending:             len(specs),
+		result:              &Result{},
+		done:                make(chan struct{}),
+	}
+	for i, s := range specs {
+		s.ID = i + 1
+	}
+	if c.pending == 0 {
+		c.close()
+	}
+	return c
+}
+
+// Serve accepts connections from the workers on the listener. It returns once Wait stops the server.
+func (c *Coordinator) Serve(lis net.Listener) error {
+	c.mu.Lock()
+	c.server = grpc.NewServer(grpc.ForceServerCodec(codec{}), grpc.MaxRecvMsgSize(oneGB), grpc.MaxSendMsgSize(oneGB))
+	c.server.RegisterService(&serviceDesc, c)
+	c.mu.Unlock()
+	go c.reapDeadWorkers()
+	go c.failIfIdle()
+	return c.server.Serve(lis)
+}
+
+// Wait blocks until all the specs have been executed and the workers are done, stops the server and returns the results.
+func (c *Coordinator) Wait() *Result {
+	<-c.done
+	c.mu.Lock()
+	server := c.server
+	c.mu.Unlock()
+	if server != nil {
+		server.GracefulStop()
+	}
+	return c.result
+}
+
+// Stop ends the execution once the specs in progress are executed and the workers are done. The specs not yet
+// handed to a worker are not executed.
+func (c *Coordinator) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.result.Unexecuted = append(c.result.Unexecuted, c.queue...)
+	c.pending -= len(c.queue)
+	c.queue = nil
+	c.stopped = true
+	c.checkDone()
+}
+
+func (c *Coordinator) reapDeadWorkers() {
+	ticker := time.NewTicker(c.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-ticker.C:
+			c.reap(now)
+		}
+	}
+}
+
+// failIfIdle ends the execution if no worker executes the specs left for the registration timeout, so that the coordinator does not
+// wait forever e.g. if the workers failed to start or all of them died.
+func (c *Coordinator) failIfIdle() {
+	timer := time.NewTimer(c.registrationTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-timer.C:
+			c.mu.Lock()
+			wait := c.checkIdle(now)
+			c.mu.Unlock()
+			if wait <= 0 {
+				return
+			}
+			timer.Reset(wait)
+		}
+	}
+}
+
+// checkIdle reports the specs left as not executed and ends the execution, if no worker has been executing them for the
+// registration timeout since idleSince, which is zero while a worker is live. Otherwise it returns how long to wait before
+// checking again.
+func (c *Coordinator) checkIdle(now time.Time) time.Duration {
+	if c.closed {
+		return 0
+	}
+	if c.idleSince.IsZero() {
+		return c.registrationTimeout
+	}
+	if idle := now.Sub(c.idleSince); idle < c.registrationTimeout {
+		return c.registrationTimeout - idle
+	}
+	logger.Errorf(true, "No worker executed the specifications for %s, %d specifications are not executed.", c.registrationTimeout, len(c.queue))
+	c.result.Unexecuted = append(c.result.Unexecuted, c.queue...)
+	c.queue, c.pending = nil, 0
+	c.close()
+	return 0
+}
+
+// reap requeues the specs of the workers that have not been seen for longer than the worker timeout
+func (c *Coordinator) reap(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, w := range c.workers {
+		if w.finished || w.dead || now.Sub(w.lastSeen) <= c.workerTimeout {
+			continue
+		}
+		w.dead = true
+		logger.Warningf(true, "Worker %s (%s) stopped responding.", w.id, w.name)
+		c.requeue(w)
+	}
+	c.checkDone()
+}
+
+func (c *Coordinator) requeue(w *worker) {
+	for id, s := range w.inFlight {
+		delete(w.inFlight, id)
+		if s.attempts >= maxAttempts {
+			logger.Errorf(true, "Not executing %s, as it was handed to %d workers which stopped responding.", s.File, s.attempts)
+			c.result.Unexecuted = append(c.result.Unexecuted, s)
+			c.pending--
+			continue
+		}
+		if c.stopped {
+			c.result.Unexecuted = append(c.result.Unexecuted, s)
+			c.pending--
+			continue
+		}
+		logger.Infof(true, "Queuing %s again.", s.File)
+		c.queue = append([]*Spec{s}, c.queue...)
+	}
+}
+
+// checkDone ends the execution once all the specs are executed and the workers are done. If specs are left and
+// all the workers are done as the before suite hook failed for some of them, the remaining specs are not executed.
+func (c *Coordinator) checkDone() {
+	if c.closed {
+		return
+	}
+	live, preSuiteFailed := 0, false
+	for _, w := range c.workers {
+		if !w.dead && !w.finished {
+			live++
+		}
+		preSuiteFailed = preSuiteFailed || w.preSuiteFailed
+	}
+	if live > 0 {
+		return
+	}
+	if c.pending > 0 {
+		if !preSuiteFailed {
+			// the specs left wait for a worker to connect, till the registration timeout
+			if c.idleSince.IsZero() {
+				c.idleSince = time.Now()
+			}
+			return
+		}
+		c.result.Unexecuted = append(c.result.Unexecuted, c.queue...)
+		c.queue, c.pending = nil, 0
+	}
+	c.close()
+}
+
+func (c *Coordinator) close() {
+	c.closed = true
+	close(c.done)
+}
+
+// authenticate checks that the call is made with the token of the coordinator
+func (c *Coordinator) authenticate(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(tokenKey)
+	if len(tokens) != 1 || subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(c.token)) != 1 {
+		return status.Errorf(codes.Unauthenticated, "invalid coordinator token, set %s to the token of the coordinator", TokenEnv)
+	}
+	return nil
+}
+
+func (c *Coordinator) worker(id string) (*worker, error) {
+	w, ok := c.workers[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown worker %s", id)
+	}
+	if w.dead {
+		return nil, status.Errorf(codes.FailedPrecondition, "worker %s stopped responding and its specs were handed to other workers", id)
+	}
+	w.lastSeen = time.Now()
+	return w, nil
+}
+
+func (c *Coordinator) register(req *RegisterRequest) (*RegisterResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stream := len(c.workers) + 1
+	w := &worker{id: fmt.Sprintf("worker-%d", stream), name: req.Name, lastSeen: time.Now(), inFlight: make(map[int]*Spec)}
+	c.workers[w.id] = w
+	c.idleSince = time.Time{}
+	logger.Infof(true, "Worker %s (%s) connected.", w.id, w.name)
+	return &RegisterResponse{WorkerID: w.id, Stream: stream, TableRows: c.tableRows, HeartbeatInterval: c.heartbeatInterval}, nil
+}
+
+func (c *Coordinator) next(req *workerRequest) (*NextResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, err := c.worker(req.WorkerID)
+	if err != nil {
+		return nil, err
+	}
+	if c.closed || c.stopped || c.pending == 0 {
+		return &NextResponse{Done: true}, nil
+	}
+	if len(c.queue) == 0 {
+		return &NextResponse{Wait: true}, nil
+	}
+	s := c.queue[0]
+	c.queue = c.queue[1:]
+	s.attempts++
+	w.inFlight[s.ID] = s
+	logger.Debugf(true, "Sending %s to worker %s.", s.File, w.id)
+	return &NextResponse{Spec: s}, nil
+}
+
+func (c *Coordinator) complete(req *completeRequest) (*empty, error) {
+	var results []*gauge_messages.ProtoSpecResult
+	for _, b := range req.Results {
+		r := &gauge_messages.ProtoSpecResult{}
+		if err := proto.Unmarshal(b, r); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid spec result. %s", err.Error())
+		}
+		results = append(results, r)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, err := c.worker(req.WorkerID)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := w.inFlight[req.SpecID]
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "spec %d is not being executed by worker %s", req.SpecID, w.id)
+	}
+	delete(w.inFlight, req.SpecID)
+	c.pending--
+	res := &SpecResults{Worker: w.id, Spec: s, Results: results}
+	c.result.Specs = append(c.result.Specs, res)
+	if c.Completed != nil {
+		c.Completed(res)
+	}
+	return &empty{}, nil
+}
+
+func (c *Coordinator) finish(req *finishRequest) (*empty, error) {
+	res := &WorkerResult{Worker: req.WorkerID, Errors: req.Errors}
+	for _, h := range []struct {
+		b []byte
+		f **gauge_messages.ProtoHookFailure
+	}{{req.PreSuite, &res.PreSuite}, {req.PostSuite, &res.PostSuite}} {
+		if len(h.b) == 0 {
+			continue
+		}
+		*h.f = &gauge_messages.ProtoHookFailure{}
+		if err := proto.Unmarshal(h.b, *h.f); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid hook failure. %s", err.Error())
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, err := c.worker(req.WorkerID)
+	if err != nil {
+		return nil, err
+	}
+	w.finished = true
+	w.preSuiteFailed = res.PreSuite != nil
+	c.requeue(w)
+	c.result.Workers = append(c.result.Workers, res)
+	logger.Infof(true, "Worker %s (%s) finished.", w.id, w.name)
+	c.checkDone()
+	return &empty{}, nil
+}
+
+func (c *Coordinator) heartbeat(req *workerRequest) (*empty, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.worker(req.WorkerID); err != nil {
+		return nil, err
+	}
+	return &empty{}, nil
+}
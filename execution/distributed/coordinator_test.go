@@ -0,0 +1,354 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package distributed
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const testToken = "secret"
+
+func specs(files ...string) []*Spec {
+	var s []*Spec
+	for _, f := range files {
+		s = append(s, &Spec{File: f})
+	}
+	return s
+}
+
+func mustMarshal(t *testing.T, m proto.Message) []byte {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func serve(t *testing.T, c *Coordinator) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go c.Serve(lis) // nolint
+	return lis.Addr().String()
+}
+
+func connect(t *testing.T, addr, name string) *Client {
+	client, err := Dial(addr, testToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Register(name); err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// work executes the specs served to the client until there are none left
+func work(client *Client, executed func(*Spec)) error {
+	for {
+		next, err := client.Next()
+		if err != nil {
+			return err
+		}
+		if next.Done {
+			return client.Finish(nil, nil, nil)
+		}
+		if next.Wait {
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		executed(next.Spec)
+		res := &gauge_messages.ProtoSpecResult{ProtoSpec: &gauge_messages.ProtoSpec{FileName: next.Spec.File}, ScenarioCount: int32(len(next.Spec.Lines))}
+		if err := client.Complete(next.Spec, []*gauge_messages.ProtoSpecResult{res}); err != nil {
+			return err
+		}
+	}
+}
+
+func TestSpecsAreExecutedByAllWorkers(t *testing.T) {
+	c := NewCoordinator([]*Spec{{File: "specs/a.spec", Lines: []int{3, 8}}, {File: "specs/b.spec"}, {File: "specs/c.spec"}, {File: "specs/d.spec"}}, "1-2", testToken)
+	addr := serve(t, c)
+
+	var mu sync.Mutex
+	executedBy := make(map[string]string)
+	wg := &sync.WaitGroup{}
+	for _, name := range []string{"first", "second", "third"} {
+		client := connect(t, addr, name)
+		wg.Add(1)
+		go func(name string, client *Client) {
+			defer wg.Done()
+			defer client.Close()
+			err := work(client, func(s *Spec) {
+				mu.Lock()
+				defer mu.Unlock()
+				executedBy[s.File] = name
+			})
+			if err != nil {
+				t.Errorf("Worker %s failed. %s", name, err.Error())
+			}
+		}(name, client)
+	}
+	res := c.Wait()
+	wg.Wait()
+
+	if len(executedBy) != 4 {
+		t.Errorf("Expected 4 specs to be executed, got %v", executedBy)
+	}
+	var files []string
+	for _, s := range res.Specs {
+		files = append(files, s.Results[0].GetProtoSpec().GetFileName())
+		if s.Spec.File == "specs/a.spec" && s.Results[0].GetScenarioCount() != 2 {
+			t.Errorf("Expected the lines of specs/a.spec to be sent to the worker, got %v", s.Spec.Lines)
+		}
+	}
+	sort.Strings(files)
+	if len(files) != 4 || files[0] != "specs/a.spec" || files[3] != "specs/d.spec" {
+		t.Errorf("Expected results of all the specs, got %v", files)
+	}
+	if len(res.Workers) != 3 {
+		t.Errorf("Expected all the workers to finish, got %d", len(res.Workers))
+	}
+	if len(res.Unexecuted) != 0 {
+		t.Errorf("Expected all the specs to be executed, got %v", res.Unexecuted)
+	}
+}
+
+func TestRegisterSendsSettingsToWorkers(t *testing.T) {
+	c := NewCoordinator(specs("a.spec"), "2-4", testToken)
+	first, _ := c.register(&RegisterRequest{Name: "first"})
+	second, _ := c.register(&RegisterRequest{Name: "second"})
+
+	if first.WorkerID == second.WorkerID || first.Stream != 1 || second.Stream != 2 {
+		t.Errorf("Expected each worker to get its own id and stream, got %v and %v", first, second)
+	}
+	if first.TableRows != "2-4" {
+		t.Errorf("Expected table rows 2-4, got %s", first.TableRows)
+	}
+}
+
+func TestSpecsOfDeadWorkerAreExecutedByAnotherWorker(t *testing.T) {
+	oldInterval, oldTimeout := HeartbeatInterval, WorkerTimeout
+	HeartbeatInterval, WorkerTimeout = 10*time.Millisecond, 50*time.Millisecond
+	defer func() { HeartbeatInterval, WorkerTimeout = oldInterval, oldTimeout }()
+
+	c := NewCoordinator(specs("a.spec", "b.spec"), "", testToken)
+	addr := serve(t, c)
+	dead := connect(t, addr, "dead")
+	defer dead.Close()
+	next, err := dead.Next()
+	if err != nil || next.Spec == nil {
+		t.Fatalf("Expected a spec, got %v, %v", next, err)
+	}
+	lost := next.Spec
+
+	alive := connect(t, addr, "alive")
+	defer alive.Close()
+	stop := make(chan struct{})
+	interval := HeartbeatInterval
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(interval):
+				alive.Heartbeat() // nolint
+			}
+		}
+	}()
+	var executed []string
+	if err := work(alive, func(s *Spec) { executed = append(executed, s.File) }); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	res := c.Wait()
+
+	if len(executed) != 2 || executed[1] != lost.File {
+		t.Errorf("Expected %s to be executed by another worker, got %v", lost.File, executed)
+	}
+	if len(res.Specs) != 2 || len(res.Unexecuted) != 0 {
+		t.Errorf("Expected 2 spec results, got %d, %d unexecuted", len(res.Specs), len(res.Unexecuted))
+	}
+	if err := dead.Complete(lost, nil); err == nil {
+		t.Error("Expected results of a dead worker to be rejected")
+	}
+}
+
+func TestSpecIsNotExecutedIfWorkersDieTooManyTimes(t *testing.T) {
+	c := NewCoordinator(specs("a.spec"), "", testToken)
+	for i := 0; i < maxAttempts; i++ {
+		reg, _ := c.register(&RegisterRequest{})
+		if next, _ := c.next(&workerRequest{WorkerID: reg.WorkerID}); next.Spec == nil {
+			t.Fatalf("Expected the spec to be handed to worker %d", i+1)
+		}
+		c.reap(time.Now().Add(WorkerTimeout + time.Second))
+	}
+
+	select {
+	case <-c.done:
+	default:
+		t.Fatal("Expected the execution to end")
+	}
+	if len(c.result.Unexecuted) != 1 || c.result.Unexecuted[0].File != "a.spec" {
+		t.Errorf("Expected a.spec to be unexecuted, got %v", c.result.Unexecuted)
+	}
+}
+
+func TestExecutionEndsIfBeforeSuiteFailsForAllWorkers(t *testing.T) {
+	c := NewCoordinator(specs("a.spec", "b.spec"), "", testToken)
+	reg, _ := c.register(&RegisterRequest{})
+	_, err := c.finish(&finishRequest{WorkerID: reg.WorkerID, PreSuite: []byte{}, Errors: []string{"boom"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c.done:
+		t.Fatal("Expected an empty hook failure to be ignored")
+	default:
+	}
+
+	reg, _ = c.register(&RegisterRequest{})
+	if _, err := c.finish(&finishRequest{WorkerID: reg.WorkerID, PreSuite: mustMarshal(t, &gauge_messages.ProtoHookFailure{ErrorMessage: "failed"})}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c.done:
+	default:
+		t.Fatal("Expected the execution to end")
+	}
+	if len(c.result.Unexecuted) != 2 {
+		t.Errorf("Expected both specs to be unexecuted, got %v", c.result.Unexecuted)
+	}
+	if c.result.Workers[1].PreSuite.GetErrorMessage() != "failed" {
+		t.Errorf("Expected the before suite failure of the worker, got %v", c.result.Workers[1].PreSuite)
+	}
+}
+
+func TestCoordinatorWithoutSpecsIsDone(t *testing.T) {
+	res := NewCoordinator(nil, "", testToken).Wait()
+	if len(res.Specs) != 0 {
+		t.Errorf("Expected no results, got %v", res.Specs)
+	}
+}
+
+func TestStoppedCoordinatorEndsOnceSpecsInProgressAreExecuted(t *testing.T) {
+	c := NewCoordinator(specs("a.spec", "b.spec", "c.spec"), "", testToken)
+	reg, _ := c.register(&RegisterRequest{})
+	next, _ := c.next(&workerRequest{WorkerID: reg.WorkerID})
+
+	c.Stop()
+
+	if n, _ := c.next(&workerRequest{WorkerID: reg.WorkerID}); !n.Done {
+		t.Error("Expected no more specs to be handed to the workers")
+	}
+	if _, err := c.complete(&completeRequest{WorkerID: reg.WorkerID, SpecID: next.Spec.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.finish(&finishRequest{WorkerID: reg.WorkerID}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c.done:
+	default:
+		t.Fatal("Expected the execution to end")
+	}
+	if len(c.result.Specs) != 1 || len(c.result.Unexecuted) != 2 {
+		t.Errorf("Expected 1 executed and 2 unexecuted specs, got %d and %d", len(c.result.Specs), len(c.result.Unexecuted))
+	}
+}
+
+func TestWorkerWithoutTheTokenIsRejected(t *testing.T) {
+	c := NewCoordinator(specs("a.spec"), "", testToken)
+	addr := serve(t, c)
+	client, err := Dial(addr, "guess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err := client.Register("intruder"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected the worker to be rejected, got %v", err)
+	}
+	if len(c.workers) != 0 {
+		t.Errorf("Expected no worker to be registered, got %d", len(c.workers))
+	}
+	c.Stop()
+}
+
+func TestSpecsAreNotExecutedIfNoWorkerConnects(t *testing.T) {
+	old := RegistrationTimeout
+	RegistrationTimeout = 50 * time.Millisecond
+	defer func() { RegistrationTimeout = old }()
+	c := NewCoordinator(specs("a.spec", "b.spec"), "", testToken)
+	serve(t, c)
+
+	res := c.Wait()
+
+	if len(res.Unexecuted) != 2 {
+		t.Errorf("Expected both specs to be unexecuted, got %v", res.Unexecuted)
+	}
+}
+
+func TestSpecsAreNotExecutedIfTheOnlyWorkerFinishesWithErrors(t *testing.T) {
+	old := RegistrationTimeout
+	RegistrationTimeout = 50 * time.Millisecond
+	defer func() { RegistrationTimeout = old }()
+	c := NewCoordinator(specs("a.spec", "b.spec"), "", testToken)
+	serve(t, c)
+	reg, _ := c.register(&RegisterRequest{Name: "worker"})
+	if _, err := c.finish(&finishRequest{WorkerID: reg.WorkerID, Errors: []string{"Failed to start runner."}}); err != nil {
+		t.Fatal(err)
+	}
+
+	res := c.Wait()
+
+	if len(res.Unexecuted) != 2 {
+		t.Errorf("Expected both specs to be unexecuted, got %v", res.Unexecuted)
+	}
+	if len(res.Workers) != 1 || len(res.Workers[0].Errors) != 1 {
+		t.Errorf("Expected the errors of the worker, got %v", res.Workers)
+	}
+}
+
+func TestIdleTimeoutIsResetWhenAWorkerConnects(t *testing.T) {
+	c := NewCoordinator(specs("a.spec"), "", testToken)
+	c.register(&RegisterRequest{}) // nolint
+
+	if wait := c.checkIdle(time.Now().Add(2 * RegistrationTimeout)); wait != RegistrationTimeout {
+		t.Errorf("Expected the coordinator to keep waiting while a worker is live, got %s", wait)
+	}
+	select {
+	case <-c.done:
+		t.Fatal("Expected the execution to go on")
+	default:
+	}
+}
+
+func TestCallsToAnUnresponsiveCoordinatorTimeOut(t *testing.T) {
+	old := CallTimeout
+	CallTimeout = 50 * time.Millisecond
+	defer func() { CallTimeout = old }()
+	c := NewCoordinator(specs("a.spec"), "", testToken)
+	addr := serve(t, c)
+	client := connect(t, addr, "worker")
+	defer client.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := client.Heartbeat(); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected the heartbeat to time out, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+// Package distributed lets the specs of an execution be run by workers on several machines.
+// A coordinator serves a queue of specs over gRPC, and each worker pulls the next spec once it is done with
+// the previous one, executes it with its own language runner and sends the results back.
+// Workers send heartbeats, and the specs of a worker that stops responding are handed to another worker.
+//
+// The service is defined by hand, and the messages are encoded as JSON, as they are internal to gauge.
+// Spec results are sent as serialized ProtoSpecResult messages.
+//
+// The workers authenticate with a token shared with the coordinator, which is sent as metadata with every call.
+// The connection is not encrypted, so the coordinator should only be reachable from the network of the workers.
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	serviceName = "gauge.distributed.Coordinator"
+	codecName   = "gauge-json"
+	tokenKey    = "gauge-coordinator-token"
+)
+
+// TokenEnv is the environment variable holding the token the workers authenticate with
+const TokenEnv = "GAUGE_COORDINATOR_TOKEN"
+
+// Spec is a spec file, or the scenarios at the given lines of a spec file, to be executed by a worker.
+// The file path is relative to the project root.
+type Spec struct {
+	ID       int    `json:"id"`
+	File     string `json:"file"`
+	Lines    []int  `json:"lines,omitempty"`
+	attempts int
+}
+
+// RegisterRequest is sent by a worker when it connects to the coordinator
+type RegisterRequest struct {
+	Name string `json:"name"`
+}
+
+// RegisterResponse holds the settings the worker should execute the specs with
+type RegisterResponse struct {
+	WorkerID          string        `json:"workerId"`
+	Stream            int           `json:"stream"`
+	TableRows         string        `json:"tableRows,omitempty"`
+	HeartbeatInterval time.Duration `json:"heartbeatInterval"`
+}
+
+type workerRequest struct {
+	WorkerID string `json:"workerId"`
+}
+
+// NextResponse holds the next spec to execute. Wait is set if the queue is empty but specs are still being executed
+// by other workers, as they are queued again if a worker dies. Done is set once there is nothing left to execute.
+type NextResponse struct {
+	Spec *Spec `json:"spec,omitempty"`
+	Wait bool  `json:"wait,omitempty"`
+	Done bool  `json:"done,omitempty"`
+}
+
+type completeRequest struct {
+	WorkerID string   `json:"workerId"`
+	SpecID   int      `json:"specId"`
+	Results  [][]byte `json:"results"`
+}
+
+type finishRequest struct {
+	WorkerID  string   `json:"workerId"`
+	PreSuite  []byte   `json:"preSuite,omitempty"`
+	PostSuite []byte   `json:"postSuite,omitempty"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+type empty struct{}
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+type coordinatorServer interface {
+	authenticate(context.Context) error
+	register(*RegisterRequest) (*RegisterResponse, error)
+	next(*workerRequest) (*NextResponse, error)
+	complete(*completeRequest) (*empty, error)
+	finish(*finishRequest) (*empty, error)
+	heartbeat(*workerRequest) (*empty, error)
+}
+
+func unaryHandler(newRequest func() interface{}, call func(coordinatorServer, interface{}) (interface{}, error)) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := newRequest()
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		s := srv.(coordinatorServer)
+		if err := s.authenticate(ctx); err != nil {
+			return nil, err
+		}
+		return call(s, req)
+	}
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*coordinatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler: unaryHandler(func() interface{} { return &RegisterRequest{} }, func(s coordinatorServer, req interface{}) (interface{}, error) {
+				return s.register(req.(*RegisterRequest))
+			}),
+		},
+		{
+			MethodName: "Next",
+			Handler: unaryHandler(func() interface{} { return &workerRequest{} }, func(s coordinatorServer, req interface{}) (interface{}, error) {
+				return s.next(req.(*workerRequest))
+			}),
+		},
+		{
+			MethodName: "Complete",
+			Handler: unaryHandler(func() interface{} { return &completeRequest{} }, func(s coordinatorServer, req interface{}) (interface{}, error) {
+				return s.complete(req.(*completeRequest))
+			}),
+		},
+		{
+			MethodName: "Finish",
+			Handler: unaryHandler(func() interface{} { return &finishRequest{} }, func(s coordinatorServer, req interface{}) (interface{}, error) {
+				return s.finish(req.(*finishRequest))
+			}),
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler: unaryHandler(func() interface{} { return &workerRequest{} }, func(s coordinatorServer, req interface{}) (interface{}, error) {
+				return s.heartbeat(req.(*workerRequest))
+			}),
+		},
+	},
+	Metadata: "distributed",
+}
+
+func method(name string) string {
+	return "/" + serviceName + "/" + name
+}
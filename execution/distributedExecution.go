@@ -0,0 +1,372 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/distributed"
+	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/filter"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/manifest"
+	"github.com/getgauge/gauge/parser"
+	"github.com/getgauge/gauge/plugin"
+	"github.com/getgauge/gauge/reporter"
+	"github.com/getgauge/gauge/runner"
+	"github.com/getgauge/gauge/util"
+	"github.com/getgauge/gauge/validation"
+)
+
+// Coordinator is the address to serve the specs on, e.g. :7000. If set, the specs are executed by the workers
+// started with `gauge worker <address>`, and the coordinator reports the merged results.
+var Coordinator string
+
+type distributedExecution struct {
+	manifest       *manifest.Manifest
+	specCollection *gauge.SpecCollection
+	runner         runner.Runner
+	pluginHandler  plugin.Handler
+	suiteResult    *result.SuiteResult
+	startTime      time.Time
+}
+
+func newDistributedExecution(e *executionInfo) *distributedExecution {
+	return &distributedExecution{
+		manifest:       e.manifest,
+		specCollection: e.specs,
+		runner:         e.runner,
+		pluginHandler:  e.pluginHandler,
+	}
+}
+
+func (e *distributedExecution) run() *result.SuiteResult {
+	// the runner is only needed to validate the specs, as the workers execute them with their own runners.
+	if err := e.runner.Kill(); err != nil {
+		logger.Debugf(true, "Failed to kill runner. %s", err.Error())
+	}
+	lis, err := net.Listen("tcp", Coordinator)
+	if err != nil {
+		logger.Fatalf(true, "Unable to serve specs on %s. %s", Coordinator, err.Error())
+	}
+	e.startTime = time.Now()
+	event.Notify(event.NewExecutionEvent(event.SuiteStart, nil, nil, 0, &gauge_messages.ExecutionInfo{}))
+	e.pluginHandler = plugin.StartPlugins(e.manifest)
+
+	specs := distributedSpecs(e.specCollection.Specs())
+	token, generated := coordinatorToken()
+	c := distributed.NewCoordinator(specs, dataTableRows, token)
+	c.Completed = func(r *distributed.SpecResults) {
+		for _, res := range r.Results {
+			status := "passed"
+			if res.GetFailed() {
+				status = "failed"
+			}
+			logger.Infof(true, "%s %s: %d scenarios, %d failed, %d skipped (%s)", res.GetProtoSpec().GetFileName(), status,
+				res.GetScenarioCount(), res.GetScenarioFailedCount(), res.GetScenarioSkippedCount(), r.Worker)
+		}
+	}
+	onInterrupt(c.Stop)
+	go func() {
+		if err := c.Serve(lis); err != nil {
+			logger.Errorf(true, "Coordinator stopped. %s", err.Error())
+		}
+	}()
+	port := lis.Addr().(*net.TCPAddr).Port
+	if generated {
+		logger.Infof(true, "Serving %d specifications on %s. Start the workers with: %s=%s gauge worker <host>:%d", len(specs), lis.Addr().String(), distributed.TokenEnv, token, port)
+	} else {
+		logger.Infof(true, "Serving %d specifications on %s. Start the workers with %s set: gauge worker <host>:%d", len(specs), lis.Addr().String(), distributed.TokenEnv, port)
+	}
+	e.aggregateResults(c.Wait())
+	e.finish()
+	return e.suiteResult
+}
+
+// coordinatorToken returns the token the workers should authenticate with. A random token is generated if none is set.
+func coordinatorToken() (string, bool) {
+	if t := os.Getenv(distributed.TokenEnv); t != "" {
+		return t, false
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logger.Fatalf(true, "Unable to generate a token for the workers, set %s instead. %s", distributed.TokenEnv, err.Error())
+	}
+	return hex.EncodeToString(b), true
+}
+
+// distributedSpecs creates the specs to be served to the workers. The specs created for the rows of a data table are served as one,
+// and the scenarios to execute are identified by the line of their heading, as they are filtered by the coordinator.
+func distributedSpecs(specs []*gauge.Specification) []*distributed.Spec {
+	var ds []*distributed.Spec
+	sc := gauge.NewSpecCollection(filter.OrderByEstimatedTime(specs), true)
+	for sc.HasNext() {
+		group := sc.Next()
+		s := &distributed.Spec{File: filepath.ToSlash(util.RelPathToProjectRoot(group[0].FileName))}
+		lines := make(map[int]bool)
+		for _, spec := range group {
+			for _, scn := range spec.Scenarios {
+				if !lines[scn.Heading.LineNo] {
+					lines[scn.Heading.LineNo] = true
+					s.Lines = append(s.Lines, scn.Heading.LineNo)
+				}
+			}
+		}
+		sort.Ints(s.Lines)
+		ds = append(ds, s)
+	}
+	return ds
+}
+
+func (e *distributedExecution) aggregateResults(res *distributed.Result) {
+	r := result.NewSuiteResult(ExecuteTags, e.startTime)
+	sort.Slice(res.Specs, func(i, j int) bool { return res.Specs[i].Spec.ID < res.Specs[j].Spec.ID })
+	for _, s := range res.Specs {
+		for _, specRes := range s.Results {
+			r.AddSpecResult(specResultFromProto(specRes))
+		}
+	}
+	for _, w := range res.Workers {
+		if w.PreSuite != nil {
+			r.PreSuite = w.PreSuite
+			r.IsFailed = true
+		}
+		if w.PostSuite != nil {
+			r.PostSuite = w.PostSuite
+			r.IsFailed = true
+		}
+		for _, msg := range w.Errors {
+			r.AddUnhandledError(fmt.Errorf("%s: %s", w.Worker, msg))
+		}
+	}
+	if len(res.Unexecuted) > 0 {
+		var files []string
+		for _, s := range res.Unexecuted {
+			files = append(files, s.File)
+		}
+		message := "No worker was able to execute them"
+		if isInterrupted() {
+			message = "Execution was interrupted"
+		}
+		r.AddUnhandledError(streamExecError{specsSkipped: files, message: message})
+		r.IsFailed = true
+	}
+	r.ExecutionTime = int64(time.Since(e.startTime) / 1e6)
+	r.SetSpecsSkippedCount()
+	e.suiteResult = r
+}
+
+func specResultFromProto(r *gauge_messages.ProtoSpecResult) *result.SpecResult {
+	return &result.SpecResult{
+		ProtoSpec:            r.GetProtoSpec(),
+		ScenarioCount:        int(r.GetScenarioCount()),
+		ScenarioFailedCount:  int(r.GetScenarioFailedCount()),
+		ScenarioSkippedCount: int(r.GetScenarioSkippedCount()),
+		IsFailed:             r.GetFailed(),
+		FailedDataTableRows:  r.GetFailedDataTableRows(),
+		ExecutionTime:        r.GetExecutionTime(),
+		Skipped:              r.GetSkipped(),
+		Errors:               r.GetErrors(),
+	}
+}
+
+func (e *distributedExecution) finish() {
+	markInterrupted(e.suiteResult)
+	e.suiteResult = mergeDataTableSpecResults(e.suiteResult)
+	event.Notify(event.NewExecutionEvent(event.SuiteEnd, nil, e.suiteResult, 0, &gauge_messages.ExecutionInfo{}))
+	message := &gauge_messages.Message{
+		MessageType: gauge_messages.Message_SuiteExecutionResult,
+		SuiteExecutionResult: &gauge_messages.SuiteExecutionResult{
+			SuiteResult: gauge.ConvertToProtoSuiteResult(e.suiteResult),
+		},
+	}
+	e.pluginHandler.NotifyPlugins(message)
+	e.pluginHandler.GracefullyKillPlugins()
+}
+
+// worker executes the specs served by a coordinator, with a runner started for the worker.
+// The suite hooks are run once, before the first and after the last spec.
+type worker struct {
+	client      *distributed.Client
+	conceptDict *gauge.ConceptDictionary
+	execution   *simpleExecution
+	errs        []error
+}
+
+// ExecuteAsWorker connects to the coordinator at the given address and executes the specs it serves, until none are left.
+// The results are reported by the coordinator, so the exit code only tells if the worker was able to do its job.
+func ExecuteAsWorker(addr string) int {
+	token := os.Getenv(distributed.TokenEnv)
+	if token == "" {
+		logger.Errorf(true, "Set %s to the token of the coordinator.", distributed.TokenEnv)
+		return ExecutionFailed
+	}
+	client, err := distributed.Dial(addr, token)
+	if err != nil {
+		logger.Errorf(true, err.Error())
+		return ExecutionFailed
+	}
+	defer client.Close()
+	host, _ := os.Hostname()
+	reg, err := client.Register(fmt.Sprintf("%s:%d", host, os.Getpid()))
+	if err != nil {
+		logger.Errorf(true, "Unable to register with coordinator at %s. %s", addr, err.Error())
+		return ExecutionFailed
+	}
+	logger.Infof(true, "Connected to coordinator at %s as %s.", addr, reg.WorkerID)
+	SetTableRows(reg.TableRows)
+	stop := make(chan struct{})
+	defer close(stop)
+	go sendHeartbeats(client, reg.HeartbeatInterval, stop)
+
+	conceptDict, res, err := parser.ParseConcepts()
+	if err != nil || !res.Ok {
+		finishWorker(client, nil, nil, []error{errors.New("failed to parse concepts")})
+		return ParseFailed
+	}
+	m, err := manifest.ProjectManifest()
+	if err != nil {
+		logger.Fatalf(true, err.Error())
+	}
+	r, err := runner.Start(m, reg.Stream, make(chan bool), false)
+	if err != nil {
+		err = fmt.Errorf("failed to start runner. %s", err.Error())
+		logger.Errorf(true, err.Error())
+		finishWorker(client, nil, nil, []error{err})
+		return ExecutionFailed
+	}
+	event.InitRegistry()
+	wg := &sync.WaitGroup{}
+	reporter.ListenExecutionEvents(wg)
+	ei := newExecutionInfo(gauge.NewSpecCollection(nil, false), newRestartableRunner(r, reg.Stream), &plugin.GaugePlugins{}, gauge.NewBuildErrors(), false, reg.Stream)
+	w := &worker{client: client, conceptDict: conceptDict, execution: newSimpleExecution(ei, false, false)}
+	ok := w.run(reg.HeartbeatInterval)
+	wg.Wait()
+	if !ok {
+		return ExecutionFailed
+	}
+	return Success
+}
+
+func (w *worker) run(pollInterval time.Duration) bool {
+	e := w.execution
+	e.startTime = time.Now()
+	e.suiteResult = result.NewSuiteResult(ExecuteTags, e.startTime)
+	event.Notify(event.NewExecutionEvent(event.SuiteStart, nil, nil, 0, &gauge_messages.ExecutionInfo{}))
+	connected := true
+	if res := e.initSuiteDataStore(); res.GetFailed() {
+		w.errs = append(w.errs, fmt.Errorf("failed to initialize suite datastore. Error: %s", res.GetErrorMessage()))
+	} else {
+		e.notifyBeforeSuite()
+		if e.suiteResult.PreSuite == nil {
+			connected = w.executeSpecs(pollInterval)
+		}
+		e.notifyAfterSuite()
+	}
+	e.suiteResult.UpdateExecTime(e.startTime)
+	if connected {
+		connected = finishWorker(w.client, e.suiteResult.PreSuite, e.suiteResult.PostSuite, w.errs)
+	}
+	e.suiteResult = mergeDataTableSpecResults(e.suiteResult)
+	event.Notify(event.NewExecutionEvent(event.SuiteEnd, nil, e.suiteResult, 0, &gauge_messages.ExecutionInfo{}))
+	if err := e.runner.Kill(); err != nil {
+		logger.Errorf(true, "Failed to kill runner. %s", err.Error())
+	}
+	return connected
+}
+
+// executeSpecs pulls the specs from the coordinator until there are none left. It returns false if the coordinator can not be reached.
+func (w *worker) executeSpecs(pollInterval time.Duration) bool {
+	for {
+		next, err := w.client.Next()
+		if err != nil {
+			logger.Errorf(true, "Unable to get the next spec from coordinator. %s", err.Error())
+			return false
+		}
+		if next.Done {
+			return true
+		}
+		if next.Wait {
+			time.Sleep(pollInterval)
+			continue
+		}
+		results, err := w.execute(next.Spec)
+		if err != nil {
+			logger.Errorf(true, err.Error())
+			w.errs = append(w.errs, err)
+		}
+		if err := w.client.Complete(next.Spec, results); err != nil {
+			logger.Errorf(true, "Unable to send the results of %s to coordinator. %s", next.Spec.File, err.Error())
+			return false
+		}
+	}
+}
+
+// execute parses the spec from the project and executes the scenarios at the given lines
+func (w *worker) execute(s *distributed.Spec) ([]*gauge_messages.ProtoSpecResult, error) {
+	file := filepath.Join(config.ProjectRoot, filepath.FromSlash(s.File))
+	args := []string{file}
+	if len(s.Lines) > 0 {
+		args = nil
+		for _, l := range s.Lines {
+			args = append(args, fmt.Sprintf("%s:%d", file, l))
+		}
+	}
+	errMap := gauge.NewBuildErrors()
+	specs, _ := parser.ParseSpecs(args, w.conceptDict, errMap)
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("unable to execute %s, no scenarios found", s.File)
+	}
+	specs, errMap = validation.ValidateParsedSpecs(specs, w.execution.runner, w.conceptDict, errMap)
+	w.execution.errMaps = errMap
+	results := w.execution.executeSpecs(gauge.NewSpecCollection(specs, true))
+	w.execution.suiteResult.AddSpecResults(results)
+	var protoResults []*gauge_messages.ProtoSpecResult
+	for _, r := range results {
+		protoResults = append(protoResults, gauge.ConvertToProtoSpecResult(r))
+	}
+	return protoResults, nil
+}
+
+func finishWorker(c *distributed.Client, preSuite, postSuite *gauge_messages.ProtoHookFailure, errs []error) bool {
+	if err := c.Finish(preSuite, postSuite, errs); err != nil {
+		logger.Errorf(true, "Unable to report to coordinator. %s", err.Error())
+		return false
+	}
+	return true
+}
+
+func sendHeartbeats(c *distributed.Client, interval time.Duration, stop chan struct{}) {
+	if interval <= 0 {
+		interval = distributed.HeartbeatInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := c.Heartbeat(); err != nil {
+				logger.Debugf(true, "Failed to send heartbeat to coordinator. %s", err.Error())
+			}
+		}
+	}
+}
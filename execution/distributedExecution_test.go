@@ -0,0 +1,59 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/distributed"
+	"github.com/getgauge/gauge/gauge"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestDistributedSpecsGroupTableDrivenSpecs(c *C) {
+	oldRoot := config.ProjectRoot
+	config.ProjectRoot = "project"
+	defer func() { config.ProjectRoot = oldRoot }()
+	file := filepath.Join("project", "specs", "example.spec")
+	scn1 := &gauge.Scenario{Heading: &gauge.Heading{LineNo: 3}}
+	scn2 := &gauge.Scenario{Heading: &gauge.Heading{LineNo: 10}}
+	specs := []*gauge.Specification{
+		{FileName: file, Heading: &gauge.Heading{}, Scenarios: []*gauge.Scenario{scn2, scn1}},
+		{FileName: file, Heading: &gauge.Heading{}, Scenarios: []*gauge.Scenario{scn1, scn2}},
+	}
+
+	ds := distributedSpecs(specs)
+
+	c.Assert(ds, HasLen, 1)
+	c.Assert(ds[0].File, Equals, "specs/example.spec")
+	c.Assert(ds[0].Lines, DeepEquals, []int{3, 10})
+}
+
+func (s *MySuite) TestAggregationOfDistributedResults(c *C) {
+	e := &distributedExecution{startTime: time.Now()}
+	passed := &gauge_messages.ProtoSpecResult{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "b.spec"}, ScenarioCount: 2}
+	failed := &gauge_messages.ProtoSpecResult{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "a.spec"}, ScenarioCount: 1, ScenarioFailedCount: 1, Failed: true}
+	e.aggregateResults(&distributed.Result{
+		Specs: []*distributed.SpecResults{
+			{Worker: "worker-2", Spec: &distributed.Spec{ID: 2}, Results: []*gauge_messages.ProtoSpecResult{passed}},
+			{Worker: "worker-1", Spec: &distributed.Spec{ID: 1}, Results: []*gauge_messages.ProtoSpecResult{failed}},
+		},
+		Workers:    []*distributed.WorkerResult{{Worker: "worker-1"}, {Worker: "worker-2", Errors: []string{"boom"}}},
+		Unexecuted: []*distributed.Spec{{File: "c.spec"}},
+	})
+
+	res := e.suiteResult
+	c.Assert(res.SpecResults, HasLen, 2)
+	c.Assert(res.SpecResults[0].ProtoSpec.FileName, Equals, "a.spec")
+	c.Assert(res.SpecsFailedCount, Equals, 1)
+	c.Assert(res.IsFailed, Equals, true)
+	c.Assert(res.UnhandledErrors, HasLen, 2)
+	c.Assert(res.UnhandledErrors[0].Error(), Equals, "worker-2: boom")
+}
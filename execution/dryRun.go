@@ -0,0 +1,209 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/filter"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/parser"
+)
+
+// DryRun if true prints the specs, scenarios and data table rows that would be executed, without starting the runner.
+var DryRun bool
+
+type plannedScenario struct {
+	Heading          string `json:"heading"`
+	Line             int    `json:"line"`
+	SpecTableRow     int    `json:"specTableRow,omitempty"`
+	ScenarioTableRow int    `json:"scenarioTableRow,omitempty"`
+	SkipReason       string `json:"skipReason,omitempty"`
+}
+
+type plannedSpec struct {
+	Heading   string             `json:"heading"`
+	FileName  string             `json:"fileName"`
+	Stream    int                `json:"stream,omitempty"`
+	Scenarios []*plannedScenario `json:"scenarios"`
+}
+
+// executionPlan is what gets executed for the given flags. Streams are assigned only for the eager strategy,
+// as specs are picked up by the free streams during a lazy execution.
+type executionPlan struct {
+	Type          string         `json:"type"`
+	Parallel      bool           `json:"parallel"`
+	Strategy      string         `json:"strategy,omitempty"`
+	Granularity   string         `json:"granularity,omitempty"`
+	Streams       int            `json:"streams,omitempty"`
+	Group         int            `json:"group,omitempty"`
+	SpecCount     int            `json:"specCount"`
+	ScenarioCount int            `json:"scenarioCount"`
+	Specs         []*plannedSpec `json:"specs"`
+	specFiles     map[string]bool
+}
+
+// dryRun parses the specs and applies the filters as an execution would, and prints the resulting execution plan.
+// The specs are not validated against the runner, so unimplemented steps are not reported.
+func dryRun(specDirs []string) int {
+	conceptDict, res, err := parser.ParseConcepts()
+	if err != nil {
+		logger.Fatalf(true, "Unable to parse : %s", err.Error())
+	}
+	if !res.Ok {
+		return ParseFailed
+	}
+	errMap := gauge.NewBuildErrors()
+	specs, specsFailed := parser.ParseSpecs(specDirs, conceptDict, errMap)
+	specs = parser.GetSpecsForDataTableRows(specs, errMap)
+	if ChangedSince != "" {
+		logger.Infof(true, "Changes to the implementation files are not considered in a dry run, as the runner is not started.")
+		if specs, err = affectedByChanges(specs, conceptDict, nil, errMap); err != nil {
+			logger.Fatalf(true, "Unable to find the changes since %s. %s", ChangedSince, err.Error())
+		}
+	}
+	if len(specs) < 1 {
+		logger.Infof(true, "No specifications found in %s.", strings.Join(specDirs, ", "))
+	} else {
+		printExecutionPlan(os.Stdout, newExecutionPlan(specs, errMap))
+	}
+	if specsFailed {
+		return ParseFailed
+	}
+	return Success
+}
+
+func newExecutionPlan(specs []*gauge.Specification, errMap *gauge.BuildErrors) *executionPlan {
+	p := &executionPlan{Type: "executionPlan", Parallel: InParallel, Specs: make([]*plannedSpec, 0), specFiles: make(map[string]bool)}
+	if filter.Distribute != -1 {
+		p.Group = filter.Distribute
+	}
+	if !InParallel {
+		for _, s := range gauge.NewSpecCollection(specs, true).Specs() {
+			p.add(s, 0, errMap)
+		}
+		return p
+	}
+	p.Strategy = strings.ToLower(Strategy)
+	if isLazy() {
+		specs = filter.OrderByEstimatedTime(specs)
+	}
+	if isScenarioGranularity() {
+		p.Granularity = ScenarioGranularity
+		specs = splitByScenario(specs, errMap)
+	}
+	p.Streams = NumberOfExecutionStreams
+	if p.Streams > len(specs) {
+		p.Streams = len(specs)
+	}
+	if isLazy() {
+		for _, s := range specs {
+			p.add(s, 0, errMap)
+		}
+		return p
+	}
+	// the streams left without specs, as the specs sharing a lock are in the same stream, are not started
+	stream := 0
+	for _, sc := range filter.DistributeSpecs(specs, p.Streams) {
+		if sc == nil {
+			continue
+		}
+		stream++
+		if isScenarioGranularity() {
+			sc = gauge.NewSpecCollection(sc.Specs(), true)
+		}
+		for _, s := range sc.Specs() {
+			p.add(s, stream, errMap)
+		}
+	}
+	p.Streams = stream
+	return p
+}
+
+// add appends the scenarios of the spec to the plan. The specs created for the rows of a data table are added as one.
+func (p *executionPlan) add(spec *gauge.Specification, stream int, errMap *gauge.BuildErrors) {
+	var ps *plannedSpec
+	if n := len(p.Specs); n > 0 && p.Specs[n-1].FileName == spec.FileName && p.Specs[n-1].Stream == stream {
+		ps = p.Specs[n-1]
+	} else {
+		ps = &plannedSpec{Heading: spec.Heading.Value, FileName: spec.FileName, Stream: stream, Scenarios: make([]*plannedScenario, 0)}
+	}
+	scenarios := len(ps.Scenarios)
+	for _, scn := range spec.Scenarios {
+		if scn.SpecDataTableRow.IsInitialized() && !shouldExecuteForRow(scn.SpecDataTableRowIndex) {
+			continue
+		}
+		s := &plannedScenario{Heading: scn.Heading.Value, Line: scn.Heading.LineNo}
+		if scn.SpecDataTableRow.IsInitialized() {
+			s.SpecTableRow = scn.SpecDataTableRowIndex + 1
+		}
+		if scn.ScenarioDataTableRow.IsInitialized() {
+			s.ScenarioTableRow = scn.ScenarioDataTableRowIndex + 1
+		}
+		if errs, ok := errMap.ScenarioErrs[scn]; ok && len(errs) > 0 {
+			s.SkipReason = errs[0].Error()
+		}
+		ps.Scenarios = append(ps.Scenarios, s)
+	}
+	p.ScenarioCount += len(ps.Scenarios) - scenarios
+	if scenarios > 0 || len(ps.Scenarios) == 0 {
+		return
+	}
+	p.Specs = append(p.Specs, ps)
+	if !p.specFiles[ps.FileName] {
+		p.specFiles[ps.FileName] = true
+		p.SpecCount++
+	}
+}
+
+func printExecutionPlan(w io.Writer, p *executionPlan) {
+	if MachineReadable {
+		b, err := json.Marshal(p)
+		if err != nil {
+			logger.Fatalf(true, "Unable to write execution plan: %s", err.Error())
+		}
+		// logger is not used, as it wraps the output in a message, which breaks the json format.
+		fmt.Fprintln(w, string(b))
+		return
+	}
+	summary := fmt.Sprintf("Execution plan: %d specifications, %d scenarios", p.SpecCount, p.ScenarioCount)
+	if p.Parallel {
+		details := p.Strategy + " strategy"
+		if p.Granularity != "" {
+			details = fmt.Sprintf("%s, %s granularity", details, p.Granularity)
+		}
+		summary = fmt.Sprintf("%s in %d parallel streams (%s)", summary, p.Streams, details)
+	}
+	if p.Group > 0 {
+		summary = fmt.Sprintf("%s, group %d", summary, p.Group)
+	}
+	fmt.Fprintln(w, summary)
+	stream := -1
+	for _, s := range p.Specs {
+		if s.Stream != stream && s.Stream > 0 {
+			fmt.Fprintf(w, "\nStream %d\n", s.Stream)
+		}
+		stream = s.Stream
+		fmt.Fprintf(w, "\n  %s (%s)\n", s.Heading, s.FileName)
+		for _, scn := range s.Scenarios {
+			fmt.Fprintf(w, "    %s (line %d)%s\n", result.WithTableRows(scn.Heading, result.TableRows(scn.SpecTableRow, scn.ScenarioTableRow)), scn.Line, skipInfo(scn))
+		}
+	}
+}
+
+func skipInfo(scn *plannedScenario) string {
+	if scn.SkipReason == "" {
+		return ""
+	}
+	return " - skipped: " + scn.SkipReason
+}
@@ -0,0 +1,105 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"bytes"
+
+	"github.com/getgauge/gauge/gauge"
+	. "gopkg.in/check.v1"
+)
+
+func planSpec(file string, scenarios ...string) *gauge.Specification {
+	spec := &gauge.Specification{FileName: file, Heading: &gauge.Heading{Value: file + " heading"}}
+	for i, s := range scenarios {
+		spec.Scenarios = append(spec.Scenarios, &gauge.Scenario{Heading: &gauge.Heading{Value: s, LineNo: i + 3}})
+	}
+	return spec
+}
+
+func (s *MySuite) TestExecutionPlanForSerialRun(c *C) {
+	InParallel = false
+	specs := []*gauge.Specification{planSpec("a.spec", "first", "second"), planSpec("b.spec", "third")}
+
+	p := newExecutionPlan(specs, gauge.NewBuildErrors())
+
+	c.Assert(p.SpecCount, Equals, 2)
+	c.Assert(p.ScenarioCount, Equals, 3)
+	c.Assert(p.Specs[0].Stream, Equals, 0)
+	c.Assert(p.Specs[0].Scenarios[1], DeepEquals, &plannedScenario{Heading: "second", Line: 4})
+}
+
+func (s *MySuite) TestExecutionPlanAssignsStreamsForEagerStrategy(c *C) {
+	oldStrategy, oldStreams := Strategy, NumberOfExecutionStreams
+	InParallel, Strategy, NumberOfExecutionStreams = true, Eager, 2
+	defer func() { InParallel, Strategy, NumberOfExecutionStreams = false, oldStrategy, oldStreams }()
+	specs := []*gauge.Specification{planSpec("a.spec", "first"), planSpec("b.spec", "second"), planSpec("c.spec", "third")}
+
+	p := newExecutionPlan(specs, gauge.NewBuildErrors())
+
+	c.Assert(p.Streams, Equals, 2)
+	c.Assert(len(p.Specs), Equals, 3)
+	c.Assert(p.Specs[0].FileName, Equals, "a.spec")
+	c.Assert(p.Specs[0].Stream, Equals, 1)
+	c.Assert(p.Specs[1].FileName, Equals, "c.spec")
+	c.Assert(p.Specs[1].Stream, Equals, 1)
+	c.Assert(p.Specs[2].FileName, Equals, "b.spec")
+	c.Assert(p.Specs[2].Stream, Equals, 2)
+}
+
+func (s *MySuite) TestExecutionPlanMergesDataTableRowsAndSkipsUnselectedRows(c *C) {
+	InParallel = false
+	tableRowsIndexes = []int{1}
+	defer func() { tableRowsIndexes = nil }()
+	row := gauge.Table{}
+	row.AddHeaders([]string{"id"})
+	row.AddRowValues(row.CreateTableCells([]string{"1"}))
+	var specs []*gauge.Specification
+	for i := 0; i < 2; i++ {
+		spec := planSpec("a.spec", "first")
+		spec.Scenarios[0].SpecDataTableRow = row
+		spec.Scenarios[0].SpecDataTableRowIndex = i
+		specs = append(specs, spec)
+	}
+
+	p := newExecutionPlan(specs, gauge.NewBuildErrors())
+
+	c.Assert(p.SpecCount, Equals, 1)
+	c.Assert(p.ScenarioCount, Equals, 1)
+	c.Assert(p.Specs[0].Scenarios[0].SpecTableRow, Equals, 2)
+}
+
+func (s *MySuite) TestPrintExecutionPlan(c *C) {
+	p := &executionPlan{Parallel: true, Strategy: Eager, Streams: 1, SpecCount: 1, ScenarioCount: 1, Specs: []*plannedSpec{
+		{Heading: "Spec", FileName: "a.spec", Stream: 1, Scenarios: []*plannedScenario{{Heading: "first", Line: 3, SpecTableRow: 2}}},
+	}}
+	b := &bytes.Buffer{}
+
+	printExecutionPlan(b, p)
+
+	c.Assert(b.String(), Equals, `Execution plan: 1 specifications, 1 scenarios in 1 parallel streams (eager strategy)
+
+Stream 1
+
+  Spec (a.spec)
+    first [spec row 2] (line 3)
+`)
+}
+
+func (s *MySuite) TestPrintExecutionPlanAsJSON(c *C) {
+	MachineReadable = true
+	defer func() { MachineReadable = false }()
+	p := &executionPlan{Type: "executionPlan", SpecCount: 1, ScenarioCount: 1, Specs: []*plannedSpec{
+		{Heading: "Spec", FileName: "a.spec", Scenarios: []*plannedScenario{{Heading: "first", Line: 3}}},
+	}}
+	b := &bytes.Buffer{}
+
+	printExecutionPlan(b, p)
+
+	c.Assert(b.String(), Equals, `{"type":"executionPlan","parallel":false,"specCount":1,"scenarioCount":1,"specs":[{"heading":"Spec","fileName":"a.spec","scenarios":[{"heading":"first","line":3}]}]}
+`)
+}
@@ -43,8 +43,13 @@ import (
 	"github.com/getgauge/gauge/config"
 	"github.com/getgauge/gauge/env"
 	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/history"
+	"github.com/getgauge/gauge/execution/metrics"
+	"github.com/getgauge/gauge/execution/quarantine"
+	"github.com/getgauge/gauge/execution/report"
 	"github.com/getgauge/gauge/execution/rerun"
 	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/execution/trace"
 	"github.com/getgauge/gauge/gauge"
 	"github.com/getgauge/gauge/logger"
 	"github.com/getgauge/gauge/plugin/install"
@@ -78,6 +83,9 @@ var MachineReadable bool
 
 var ExecutionArgs []*gauge.ExecutionArg
 
+// MetricsAddr is the address to serve the execution metrics on, in the Prometheus format
+var MetricsAddr string
+
 type suiteExecutor interface {
 	run() *result.SuiteResult
 }
@@ -93,6 +101,9 @@ var ExecuteSpecs = func(specDirs []string) int {
 	if err != nil {
 		logger.Fatalf(true, err.Error())
 	}
+	if DryRun {
+		return dryRun(specDirs)
+	}
 	if config.CheckUpdates() {
 		i := &install.UpdateFacade{}
 		i.BufferUpdateDetails()
@@ -103,6 +114,17 @@ var ExecuteSpecs = func(specDirs []string) int {
 	if err != nil {
 		logger.Fatalf(true, "failed to set env %s. %s", gaugeParallelStreamCountEnv, err.Error())
 	}
+	if MetricsAddr != "" {
+		if err := metrics.Serve(MetricsAddr); err != nil {
+			logger.Fatalf(true, "Failed to serve the execution metrics on %s. %s", MetricsAddr, err.Error())
+		}
+	}
+	// the trace context is set before the runner is started, so that the runner inherits it
+	if trace.Enabled() {
+		if err := trace.Propagate(); err != nil {
+			logger.Warningf(true, "Unable to pass the trace context to the runner. %s", err.Error())
+		}
+	}
 
 	res := validation.ValidateSpecs(specDirs, false)
 	if len(res.Errs) > 0 {
@@ -111,8 +133,15 @@ var ExecuteSpecs = func(specDirs []string) int {
 		}
 		return ValidationFailed
 	}
+	if ChangedSince != "" {
+		res.SpecCollection = specsAffectedByChanges(res)
+	}
 	if res.SpecCollection.Size() < 1 {
-		logger.Infof(true, "No specifications found in %s.", strings.Join(specDirs, ", "))
+		if ChangedSince != "" {
+			logger.Infof(true, "No scenarios affected by the changes since %s.", ChangedSince)
+		} else {
+			logger.Infof(true, "No specifications found in %s.", strings.Join(specDirs, ", "))
+		}
 		err := res.Runner.Kill()
 		if err != nil {
 			logger.Errorf(false, "unable to kill runner: %s", err.Error())
@@ -122,6 +151,28 @@ var ExecuteSpecs = func(specDirs []string) int {
 		}
 		return ExecutionFailed
 	}
+	if !InParallel {
+		res.Runner = newRestartableRunner(res.Runner, 0)
+	}
+	if Watch {
+		return executeAndWatch(specDirs, res)
+	}
+	stopHandlingInterrupts := handleInterrupts()
+	defer stopHandlingInterrupts()
+	wg := listenExecutionEvents(specDirs)
+	setPlannedScenarios(res.SpecCollection)
+	defer wg.Wait()
+	ei := newExecutionInfo(res.SpecCollection, res.Runner, nil, res.ErrMap, InParallel, 0)
+
+	e := ei.getExecutor()
+	logger.Debug(true, "Run started")
+	return printExecutionResult(e.run(), res.ParseOk)
+}
+
+// listenExecutionEvents initiates the registry and registers the listeners for console reporting, rerun of failed specs,
+// saving the execution result and history, native reports, the metrics and the trace export. The returned WaitGroup is done once the listeners
+// handle the suite end, after which they return. The listeners are registered again for every execution, e.g. in watch mode.
+func listenExecutionEvents(specDirs []string) *sync.WaitGroup {
 	event.InitRegistry()
 	wg := &sync.WaitGroup{}
 	reporter.ListenExecutionEvents(wg)
@@ -129,12 +180,41 @@ var ExecuteSpecs = func(specDirs []string) int {
 	if env.SaveExecutionResult() {
 		ListenSuiteEndAndSaveResult(wg)
 	}
-	defer wg.Wait()
-	ei := newExecutionInfo(res.SpecCollection, res.Runner, nil, res.ErrMap, InParallel, 0)
+	if env.SaveExecutionHistory() {
+		history.ListenSuiteEndAndSave(wg)
+	}
+	if formats := report.ConfiguredFormats(); len(formats) > 0 {
+		report.ListenSuiteEndAndWriteReports(wg, formats)
+	}
+	if trace.Enabled() {
+		trace.ListenAndExport(wg)
+	}
+	if MetricsAddr != "" {
+		metrics.ListenExecutionEvents()
+	}
+	return wg
+}
 
-	e := ei.getExecutor()
-	logger.Debug(true, "Run started")
-	return printExecutionResult(e.run(), res.ParseOk)
+func setPlannedScenarios(sc *gauge.SpecCollection) {
+	if MetricsAddr == "" {
+		return
+	}
+	metrics.SetPlanned(plannedScenarios(sc.Specs()))
+}
+
+// plannedScenarios counts the scenarios as the execution runs them. The specs hold a scenario for each data table row,
+// of which the rows not selected by the --table-rows flag are skipped without being reported.
+func plannedScenarios(specs []*gauge.Specification) int {
+	scenarios := 0
+	for _, spec := range specs {
+		for _, scn := range spec.Scenarios {
+			if scn.SpecDataTableRow.IsInitialized() && !shouldExecuteForRow(scn.SpecDataTableRowIndex) {
+				continue
+			}
+			scenarios++
+		}
+	}
+	return scenarios
 }
 
 func writeExecutionResult(content string) {
@@ -192,9 +272,20 @@ func printExecutionResult(suiteResult *result.SuiteResult, isParsingOk bool) int
 		nPassedScenarios = 0
 	}
 
-	s := statusJSON(nExecutedSpecs, nPassedSpecs, nFailedSpecs, nSkippedSpecs, nExecutedScenarios, nPassedScenarios, nFailedScenarios, nSkippedScenarios)
+	flaky, quarantined := flakyAndQuarantinedScenarios(suiteResult)
+
+	s := statusJSON(nExecutedSpecs, nPassedSpecs, nFailedSpecs, nSkippedSpecs, nExecutedScenarios, nPassedScenarios, nFailedScenarios, nSkippedScenarios, len(flaky), len(quarantined))
 	logger.Infof(true, "Specifications:\t%d executed\t%d passed\t%d failed\t%d skipped", nExecutedSpecs, nPassedSpecs, nFailedSpecs, nSkippedSpecs)
 	logger.Infof(true, "Scenarios:\t%d executed\t%d passed\t%d failed\t%d skipped", nExecutedScenarios, nPassedScenarios, nFailedScenarios, nSkippedScenarios)
+	if len(flaky) > 0 {
+		logger.Infof(true, "Flaky scenarios (passed after retry):\n\t%s", strings.Join(flaky, "\n\t"))
+	}
+	if len(quarantined) > 0 {
+		logger.Infof(true, "Quarantined scenarios failed:\n\t%s", strings.Join(quarantined, "\n\t"))
+	}
+	if suiteResult.Interrupted {
+		logger.Warningf(true, "Execution was interrupted, the remaining scenarios were skipped.")
+	}
 	logger.Infof(true, "\nTotal time taken: %s", time.Millisecond*time.Duration(suiteResult.ExecutionTime))
 	writeExecutionResult(s)
 
@@ -202,15 +293,67 @@ func printExecutionResult(suiteResult *result.SuiteResult, isParsingOk bool) int
 		return ParseFailed
 	}
 	if suiteResult.IsFailed {
+		if !suiteResult.Interrupted && quarantine.Current().OnlyQuarantinedFailures(suiteResult) {
+			logger.Infof(true, "Ignoring the failures of quarantined scenarios.")
+			return Success
+		}
 		return ExecutionFailed
 	}
 	return Success
 }
 
+// flakyAndQuarantinedScenarios returns the scenarios that passed only after a retry and the quarantined scenarios that failed,
+// each described as `<spec file> : <scenario heading>`.
+func flakyAndQuarantinedScenarios(suiteResult *result.SuiteResult) (flaky []string, quarantined []string) {
+	q := quarantine.Current()
+	for _, specResult := range suiteResult.SpecResults {
+		spec := specResult.ProtoSpec
+		for _, scn := range result.Scenarios(spec) {
+			if result.IsFlaky(scn) {
+				flaky = append(flaky, fmt.Sprintf("%s : %s (%d attempts)", spec.GetFileName(), scn.GetScenarioHeading(), scn.GetRetriesCount()))
+			}
+		}
+		for _, heading := range q.FailedScenarios(spec) {
+			quarantined = append(quarantined, fmt.Sprintf("%s : %s", spec.GetFileName(), heading))
+		}
+	}
+	return flaky, quarantined
+}
+
 func validateFlags() error {
 	if MaxRetriesCount < 1 {
 		return fmt.Errorf("invalid input(%s) to --max-retries-count flag", strconv.Itoa(MaxRetriesCount))
 	}
+	if err := report.Validate(report.ConfiguredFormats()); err != nil {
+		return err
+	}
+	if !reporter.IsValidFormat(reporter.Format) {
+		return fmt.Errorf("invalid input(%s) to --format flag. Possible options are: %s", reporter.Format, strings.Join(reporter.Formats(), ", "))
+	}
+	if reporter.Format != "" && MachineReadable {
+		return fmt.Errorf("--format cannot be used along with --machine-readable")
+	}
+	if ScenarioTimeout < 0 || StepTimeout < 0 {
+		return fmt.Errorf("timeouts given to --scenario-timeout and --step-timeout flags cannot be negative")
+	}
+	if MaxFailures < 0 {
+		return fmt.Errorf("invalid input(%s) to --max-failures flag", strconv.Itoa(MaxFailures))
+	}
+	if FailFast && MaxFailures > 0 {
+		return fmt.Errorf("--fail-fast cannot be used along with --max-failures")
+	}
+	if DryRun && Watch {
+		return fmt.Errorf("--dry-run cannot be used along with --watch")
+	}
+	if Watch && InParallel {
+		return fmt.Errorf("--watch cannot be used along with --parallel")
+	}
+	if ChangedSince != "" && Watch {
+		return fmt.Errorf("--changed-since cannot be used along with --watch")
+	}
+	if Coordinator != "" && (InParallel || Watch) {
+		return fmt.Errorf("--coordinator cannot be used along with --parallel or --watch")
+	}
 	if !InParallel {
 		return nil
 	}
@@ -220,5 +363,8 @@ func validateFlags() error {
 	if !isValidStrategy(Strategy) {
 		return fmt.Errorf("invalid input(%s) to --strategy flag", Strategy)
 	}
+	if !isValidGranularity(Granularity) {
+		return fmt.Errorf("invalid input(%s) to --granularity flag", Granularity)
+	}
 	return nil
 }
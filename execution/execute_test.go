@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/reporter"
 
 	. "gopkg.in/check.v1"
 )
@@ -69,3 +70,30 @@ func (s *MySuite) TestValidateFlagsWithInvalidStream(c *C) {
 	err := validateFlags()
 	c.Assert(err.Error(), Equals, "invalid input(-1) to --n flag")
 }
+
+func (s *MySuite) TestValidateFlagsWithInvalidConsoleFormat(c *C) {
+	InParallel = false
+	reporter.Format = "junit"
+	defer func() { reporter.Format = "" }()
+	err := validateFlags()
+	c.Assert(err.Error(), Equals, "invalid input(junit) to --format flag. Possible options are: tap, teamcity, github")
+}
+
+func (s *MySuite) TestPlannedScenariosCountsSelectedDataTableRows(c *C) {
+	tableRowsIndexes = []int{1, 2}
+	defer func() { tableRowsIndexes = nil }()
+	row := gauge.Table{}
+	row.AddHeaders([]string{"id"})
+	row.AddRowValues(row.CreateTableCells([]string{"1"}))
+	specs := []*gauge.Specification{planSpec("b.spec", "other")}
+	for i := 0; i < 3; i++ {
+		spec := planSpec("a.spec", "first", "second")
+		for _, scn := range spec.Scenarios {
+			scn.SpecDataTableRow = row
+			scn.SpecDataTableRowIndex = i
+		}
+		specs = append(specs, spec)
+	}
+
+	c.Assert(plannedScenarios(specs), Equals, 5)
+}
@@ -7,15 +7,17 @@ import (
 )
 
 type executionStatus struct {
-	Type          string `json:"type"`
-	SpecsExecuted int    `json:"specsExecuted"`
-	SpecsPassed   int    `json:"specsPassed"`
-	SpecsFailed   int    `json:"specsFailed"`
-	SpecsSkipped  int    `json:"specsSkipped"`
-	SceExecuted   int    `json:"sceExecuted"`
-	ScePassed     int    `json:"scePassed"`
-	SceFailed     int    `json:"sceFailed"`
-	SceSkipped    int    `json:"sceSkipped"`
+	Type           string `json:"type"`
+	SpecsExecuted  int    `json:"specsExecuted"`
+	SpecsPassed    int    `json:"specsPassed"`
+	SpecsFailed    int    `json:"specsFailed"`
+	SpecsSkipped   int    `json:"specsSkipped"`
+	SceExecuted    int    `json:"sceExecuted"`
+	ScePassed      int    `json:"scePassed"`
+	SceFailed      int    `json:"sceFailed"`
+	SceSkipped     int    `json:"sceSkipped"`
+	SceFlaky       int    `json:"sceFlaky"`
+	SceQuarantined int    `json:"sceQuarantined"`
 }
 
 func (status *executionStatus) getJSON() (string, error) {
@@ -26,7 +28,7 @@ func (status *executionStatus) getJSON() (string, error) {
 	return string(j), nil
 }
 
-func statusJSON(executedSpecs, passedSpecs, failedSpecs, skippedSpecs, executedScenarios, passedScenarios, failedScenarios, skippedScenarios int) string {
+func statusJSON(executedSpecs, passedSpecs, failedSpecs, skippedSpecs, executedScenarios, passedScenarios, failedScenarios, skippedScenarios, flakyScenarios, quarantinedScenarios int) string {
 	executionStatus := &executionStatus{}
 	executionStatus.Type = "out"
 	executionStatus.SpecsExecuted = executedSpecs
@@ -37,6 +39,8 @@ func statusJSON(executedSpecs, passedSpecs, failedSpecs, skippedSpecs, executedS
 	executionStatus.ScePassed = passedScenarios
 	executionStatus.SceFailed = failedScenarios
 	executionStatus.SceSkipped = skippedScenarios
+	executionStatus.SceFlaky = flakyScenarios
+	executionStatus.SceQuarantined = quarantinedScenarios
 	s, err := executionStatus.getJSON()
 	if err != nil {
 		logger.Fatalf(true, "Unable to parse execution status information : %v", err.Error())
@@ -0,0 +1,65 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/getgauge/gauge/logger"
+)
+
+// FailFast if true stops the execution after the first failed scenario.
+var FailFast bool
+
+// MaxFailures is the number of failed scenarios after which the execution is stopped. 0 means the execution is never stopped.
+var MaxFailures int
+
+// failureCounter counts the failed scenarios across all the streams of an execution. Once the limit is reached, no new spec
+// or scenario is started, the scenarios in progress are allowed to finish and the remaining ones are skipped.
+type failureCounter struct {
+	mutex sync.Mutex
+	count int
+}
+
+var failures = &failureCounter{}
+
+func maxFailures() int {
+	if FailFast {
+		return 1
+	}
+	return MaxFailures
+}
+
+func (f *failureCounter) reset() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.count = 0
+}
+
+func (f *failureCounter) add() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.count++
+	if limit := maxFailures(); limit > 0 && f.count == limit {
+		logger.Warningf(true, "Stopping the execution as %d scenario(s) failed. The remaining scenarios will be skipped.", f.count)
+	}
+}
+
+func (f *failureCounter) limitReached() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	limit := maxFailures()
+	return limit > 0 && f.count >= limit
+}
+
+func failureLimitReason() string {
+	if FailFast {
+		return "skipped Reason: Execution stopped after the first failure as --fail-fast is set"
+	}
+	return fmt.Sprintf("skipped Reason: Execution stopped after %d failures as --max-failures is set", MaxFailures)
+}
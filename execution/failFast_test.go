@@ -0,0 +1,72 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"testing"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/gauge"
+)
+
+func newFailingScenarioRunner(messages map[gauge_messages.Message_MessageType]int) *mockRunner {
+	return &mockRunner{ExecuteAndGetStatusFunc: func(m *gauge_messages.Message) *gauge_messages.ProtoExecutionResult {
+		messages[m.MessageType]++
+		if m.MessageType == gauge_messages.Message_ScenarioExecutionStarting {
+			return &gauge_messages.ProtoExecutionResult{Failed: true, ErrorMessage: "before scenario failed"}
+		}
+		return &gauge_messages.ProtoExecutionResult{}
+	}}
+}
+
+func TestScenariosAreSkippedOnceFailureLimitIsReached(t *testing.T) {
+	MaxRetriesCount, MaxFailures = 1, 1
+	failures.reset()
+	defer func() { MaxFailures = 0; failures.reset() }()
+	messages := make(map[gauge_messages.Message_MessageType]int)
+	h := &mockPluginHandler{NotifyPluginsfunc: func(m *gauge_messages.Message) {}, GracefullyKillPluginsfunc: func() {}}
+	se := newSpecExecutor(exampleSpecWithScenarios, newFailingScenarioRunner(messages), h, gauge.NewBuildErrors(), 0)
+
+	res := se.execute(true, true, true)
+
+	if messages[gauge_messages.Message_ScenarioExecutionStarting] != 1 {
+		t.Errorf("Expected only the first scenario to be executed, got %d", messages[gauge_messages.Message_ScenarioExecutionStarting])
+	}
+	if messages[gauge_messages.Message_SpecExecutionEnding] != 1 {
+		t.Error("Expected the after spec hook of a spec in progress to be executed")
+	}
+	if res.ScenarioFailedCount != 1 || res.ScenarioSkippedCount != 1 {
+		t.Errorf("Expected 1 failed and 1 skipped scenario, got %d failed and %d skipped", res.ScenarioFailedCount, res.ScenarioSkippedCount)
+	}
+	skipped := res.ProtoSpec.Items[1].Scenario
+	if len(skipped.SkipErrors) != 1 || skipped.SkipErrors[0] != failureLimitReason() {
+		t.Errorf("Expected the scenario to be skipped as the failure limit is reached, got %v", skipped.SkipErrors)
+	}
+}
+
+func TestSpecHooksAreNotExecutedOnceFailureLimitIsReached(t *testing.T) {
+	FailFast = true
+	failures.reset()
+	failures.add()
+	defer func() { FailFast = false; failures.reset() }()
+	messages := make(map[gauge_messages.Message_MessageType]int)
+	h := &mockPluginHandler{NotifyPluginsfunc: func(m *gauge_messages.Message) {}, GracefullyKillPluginsfunc: func() {}}
+	se := newSpecExecutor(exampleSpecWithScenarios, newFailingScenarioRunner(messages), h, gauge.NewBuildErrors(), 0)
+	se.stopped = true
+
+	res := se.execute(true, true, true)
+
+	if len(messages) != 0 {
+		t.Errorf("Expected no hooks to be executed, got %v", messages)
+	}
+	if !res.Skipped || res.ScenarioSkippedCount != 2 {
+		t.Errorf("Expected the spec and its scenarios to be skipped, got %d skipped", res.ScenarioSkippedCount)
+	}
+	if got := res.ProtoSpec.Items[0].Scenario.SkipErrors[0]; got != "skipped Reason: Execution stopped after the first failure as --fail-fast is set" {
+		t.Errorf("Unexpected skip reason %s", got)
+	}
+}
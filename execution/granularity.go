@@ -0,0 +1,101 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"sort"
+	"strings"
+
+	m "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+)
+
+// Granularity is the unit of work scheduled on the parallel streams, can be either 'spec' or 'scenario'
+var Granularity string
+
+// SpecGranularity schedules whole specifications on the parallel streams.
+const SpecGranularity string = "spec"
+
+// ScenarioGranularity schedules individual scenarios on the parallel streams. The before and after spec hooks, contexts and teardowns
+// are run by each stream executing scenarios of the spec, and the results are merged into one result per spec.
+const ScenarioGranularity string = "scenario"
+
+func isScenarioGranularity() bool {
+	return strings.ToLower(Granularity) == ScenarioGranularity
+}
+
+func isValidGranularity(granularity string) bool {
+	granularity = strings.ToLower(granularity)
+	return granularity == "" || granularity == SpecGranularity || granularity == ScenarioGranularity
+}
+
+// splitByScenario creates a spec for each scenario of the given specs. The errors of a spec are copied to the specs created from it.
+func splitByScenario(specs []*gauge.Specification, errMap *gauge.BuildErrors) []*gauge.Specification {
+	var split []*gauge.Specification
+	for _, spec := range specs {
+		if len(spec.Scenarios) < 2 {
+			split = append(split, spec)
+			continue
+		}
+		for _, scn := range spec.Scenarios {
+			s := *spec
+			s.Scenarios = []*gauge.Scenario{scn}
+			s.Items = nil
+			for _, item := range spec.Items {
+				if item.Kind() != gauge.ScenarioKind || item == gauge.Item(scn) {
+					s.Items = append(s.Items, item)
+				}
+			}
+			if errs, ok := errMap.SpecErrs[spec]; ok {
+				errMap.SpecErrs[&s] = errs
+			}
+			split = append(split, &s)
+		}
+	}
+	return split
+}
+
+// orderScenarioResults orders the results of the specs created by splitByScenario as the scenarios are ordered in the spec,
+// so that they are merged in the same order. The order of the spec files is left as is.
+func orderScenarioResults(results []*result.SpecResult) {
+	type position struct{ file, row, line, scenarioRow int }
+	files := make(map[string]int)
+	positions := make(map[*result.SpecResult]position)
+	for _, r := range results {
+		file := r.ProtoSpec.GetFileName()
+		if _, ok := files[file]; !ok {
+			files[file] = len(files)
+		}
+		p := position{file: files[file]}
+		for _, item := range r.ProtoSpec.GetItems() {
+			if item.GetItemType() == m.ProtoItem_Scenario {
+				p.line = int(item.GetScenario().GetSpan().GetStart())
+				break
+			}
+			if item.GetItemType() == m.ProtoItem_TableDrivenScenario {
+				tds := item.GetTableDrivenScenario()
+				p.row, p.line, p.scenarioRow = int(tds.GetTableRowIndex()), int(tds.GetScenario().GetSpan().GetStart()), int(tds.GetScenarioTableRowIndex())
+				break
+			}
+		}
+		positions[r] = p
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := positions[results[i]], positions[results[j]]
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		if a.row != b.row {
+			return a.row < b.row
+		}
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		return a.scenarioRow < b.scenarioRow
+	})
+}
@@ -0,0 +1,144 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"errors"
+
+	m "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestSplitByScenarioCreatesSpecForEachScenario(c *C) {
+	spec := planSpec("a.spec", "first", "second")
+	comment := &gauge.Comment{Value: "comment"}
+	spec.Items = []gauge.Item{comment, spec.Scenarios[0], spec.Scenarios[1]}
+	single := planSpec("b.spec", "third")
+	errMap := gauge.NewBuildErrors()
+	errMap.SpecErrs[spec] = []error{errors.New("spec error")}
+
+	specs := splitByScenario([]*gauge.Specification{spec, single}, errMap)
+
+	c.Assert(specs, HasLen, 3)
+	c.Assert(specs[0].Scenarios, DeepEquals, []*gauge.Scenario{spec.Scenarios[0]})
+	c.Assert(specs[0].Items, DeepEquals, []gauge.Item{comment, spec.Scenarios[0]})
+	c.Assert(specs[1].Items, DeepEquals, []gauge.Item{comment, spec.Scenarios[1]})
+	c.Assert(specs[2], Equals, single)
+	c.Assert(errMap.SpecErrs[specs[1]], HasLen, 1)
+	c.Assert(spec.Scenarios, HasLen, 2)
+}
+
+func (s *MySuite) TestExecutionPlanForScenarioGranularity(c *C) {
+	oldStrategy, oldStreams := Strategy, NumberOfExecutionStreams
+	InParallel, Strategy, NumberOfExecutionStreams, Granularity = true, Eager, 2, ScenarioGranularity
+	defer func() {
+		InParallel, Strategy, NumberOfExecutionStreams, Granularity = false, oldStrategy, oldStreams, ""
+	}()
+	specs := []*gauge.Specification{planSpec("a.spec", "first", "second", "third"), planSpec("b.spec", "fourth")}
+
+	p := newExecutionPlan(specs, gauge.NewBuildErrors())
+
+	c.Assert(p.Granularity, Equals, ScenarioGranularity)
+	c.Assert(p.SpecCount, Equals, 2)
+	c.Assert(p.ScenarioCount, Equals, 4)
+	c.Assert(p.Specs, HasLen, 3)
+	c.Assert(p.Specs[0].Stream, Equals, 1)
+	c.Assert(p.Specs[0].Scenarios, HasLen, 2)
+	c.Assert(p.Specs[0].Scenarios[1].Heading, Equals, "third")
+	c.Assert(p.Specs[1].FileName, Equals, "a.spec")
+	c.Assert(p.Specs[1].Stream, Equals, 2)
+	c.Assert(p.Specs[2].FileName, Equals, "b.spec")
+}
+
+func scenarioResult(file, heading string, line int64, status m.ExecutionStatus) *result.SpecResult {
+	scn := &m.ProtoScenario{ScenarioHeading: heading, Span: &m.Span{Start: line}, ExecutionStatus: status, Failed: status == m.ExecutionStatus_FAILED}
+	return &result.SpecResult{
+		ProtoSpec: &m.ProtoSpec{FileName: file, SpecHeading: file, Items: []*m.ProtoItem{
+			{ItemType: m.ProtoItem_Comment, Comment: &m.ProtoComment{Text: "comment"}},
+			{ItemType: m.ProtoItem_Scenario, Scenario: scn},
+		}},
+		ScenarioCount: 1,
+		IsFailed:      scn.Failed,
+	}
+}
+
+func (s *MySuite) TestScenarioResultsAreMergedInSpecOrder(c *C) {
+	results := []*result.SpecResult{
+		scenarioResult("a.spec", "third", 12, m.ExecutionStatus_PASSED),
+		scenarioResult("b.spec", "other", 3, m.ExecutionStatus_PASSED),
+		scenarioResult("a.spec", "first", 3, m.ExecutionStatus_FAILED),
+		scenarioResult("a.spec", "second", 7, m.ExecutionStatus_SKIPPED),
+	}
+
+	orderScenarioResults(results)
+	merged := mergeResults(results[:3])
+
+	c.Assert(results[3].ProtoSpec.FileName, Equals, "b.spec")
+	c.Assert(merged.ProtoSpec.Items, HasLen, 4)
+	c.Assert(merged.ProtoSpec.Items[0].ItemType, Equals, m.ProtoItem_Comment)
+	c.Assert(merged.ProtoSpec.Items[1].Scenario.ScenarioHeading, Equals, "first")
+	c.Assert(merged.ProtoSpec.Items[2].Scenario.ScenarioHeading, Equals, "second")
+	c.Assert(merged.ProtoSpec.Items[3].Scenario.ScenarioHeading, Equals, "third")
+	c.Assert(merged.ScenarioCount, Equals, 3)
+	c.Assert(merged.ScenarioFailedCount, Equals, 1)
+	c.Assert(merged.ScenarioSkippedCount, Equals, 1)
+	c.Assert(merged.IsFailed, Equals, true)
+	c.Assert(merged.Skipped, Equals, false)
+}
+
+func (s *MySuite) TestSpecHooksAreRunOnceForTheScenariosOfASpecTakenByAStream(c *C) {
+	Granularity = ScenarioGranularity
+	defer func() { Granularity = "" }()
+	hooks := make(map[m.Message_MessageType]int)
+	r := &mockRunner{ExecuteAndGetStatusFunc: func(msg *m.Message) *m.ProtoExecutionResult {
+		hooks[msg.MessageType]++
+		return &m.ProtoExecutionResult{}
+	}}
+	h := &mockPluginHandler{NotifyPluginsfunc: func(msg *m.Message) {}, GracefullyKillPluginsfunc: func() {}}
+	a, b := planSpec("a.spec", "first", "second"), planSpec("b.spec", "third")
+	for _, scn := range append(a.Scenarios, b.Scenarios...) {
+		scn.Span = &gauge.Span{Start: scn.Heading.LineNo, End: scn.Heading.LineNo}
+	}
+	specs := splitByScenario([]*gauge.Specification{a, b}, gauge.NewBuildErrors())
+	se := newSimpleExecution(&executionInfo{runner: r, pluginHandler: h, errMaps: gauge.NewBuildErrors()}, false, false)
+
+	results := se.executeSpecs(gauge.NewSpecCollection(specs, false))
+
+	c.Assert(results, HasLen, 3)
+	c.Assert(hooks[m.Message_SpecExecutionStarting], Equals, 2)
+	c.Assert(hooks[m.Message_SpecExecutionEnding], Equals, 2)
+	c.Assert(hooks[m.Message_ScenarioExecutionStarting], Equals, 3)
+}
+
+func (s *MySuite) TestAfterSpecHookOfScenarioHoldingALockRunsBeforeTheLockIsReleased(c *C) {
+	Granularity = ScenarioGranularity
+	defer func() { Granularity = "" }()
+	spec := planSpec("a.spec", "first", "second")
+	spec.Tags = &gauge.Tags{RawValues: [][]string{{"lock:db"}}}
+	for _, scn := range spec.Scenarios {
+		scn.Span = &gauge.Span{Start: scn.Heading.LineNo, End: scn.Heading.LineNo}
+	}
+	q := newLockingQueue(gauge.NewSpecCollection(splitByScenario([]*gauge.Specification{spec}, gauge.NewBuildErrors()), false))
+	var afterHooks int
+	r := &mockRunner{ExecuteAndGetStatusFunc: func(msg *m.Message) *m.ProtoExecutionResult {
+		if msg.MessageType == m.Message_SpecExecutionEnding {
+			afterHooks++
+			c.Assert(q.held["db"], Equals, true)
+		}
+		return &m.ProtoExecutionResult{}
+	}}
+	h := &mockPluginHandler{NotifyPluginsfunc: func(msg *m.Message) {}, GracefullyKillPluginsfunc: func() {}}
+	se := newSimpleExecution(&executionInfo{runner: r, pluginHandler: h, errMaps: gauge.NewBuildErrors()}, false, false)
+
+	results := se.executeSpecs(q)
+
+	c.Assert(results, HasLen, 2)
+	c.Assert(afterHooks, Equals, 2)
+	c.Assert(q.held["db"], Equals, false)
+}
@@ -0,0 +1,217 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"google.golang.org/protobuf/proto"
+)
+
+// The formats in which a diff can be printed
+const (
+	TextFormat     = "text"
+	JSONFormat     = "json"
+	MarkdownFormat = "markdown"
+)
+
+// minSlowdown is the least increase in the execution time, in milliseconds, for a scenario to be reported as slower.
+// It keeps the scenarios taking a few milliseconds from being reported for the noise in their times.
+const minSlowdown = 1000
+
+// Slowdown is the execution time of a scenario in the previous and the latest run, in milliseconds
+type Slowdown struct {
+	Scenario
+	OldTime int64 `json:"oldTime"`
+	NewTime int64 `json:"newTime"`
+}
+
+// Diff is the change in the results of the scenarios from a previous run to the latest one
+type Diff struct {
+	Type                string      `json:"type"`
+	NewlyFailing        []Scenario  `json:"newlyFailing"`
+	NewlyPassing        []Scenario  `json:"newlyPassing"`
+	ConsistentlyFailing []Scenario  `json:"consistentlyFailing"`
+	Added               []Scenario  `json:"added"`
+	Removed             []Scenario  `json:"removed"`
+	Slower              []*Slowdown `json:"slower"`
+}
+
+// Formats returns the formats in which a diff can be printed
+func Formats() []string {
+	return []string{TextFormat, JSONFormat, MarkdownFormat}
+}
+
+// ReadResult reads a suite result saved as the last run result, or as a run in the execution history.
+func ReadResult(file string) (*gauge_messages.ProtoSuiteResult, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	// the runs in the history are gzip compressed
+	if len(b) > 1 && b[0] == 0x1f && b[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if b, err = ioutil.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	res := &gauge_messages.ProtoSuiteResult{}
+	if err := proto.Unmarshal(b, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// NewDiff compares the scenarios of the previous and the latest run by their spec, heading and data table row. The specs are
+// matched by their path relative to the project directory of each run, so that the runs of different checkouts of the project,
+// e.g. in different CI workspaces, can be compared. A scenario executed in both runs is slower if it took
+// slowerBy percent and at least a second more in the latest run.
+func NewDiff(previous, latest *gauge_messages.ProtoSuiteResult, slowerBy int) *Diff {
+	d := &Diff{Type: "diff", NewlyFailing: make([]Scenario, 0), NewlyPassing: make([]Scenario, 0), ConsistentlyFailing: make([]Scenario, 0),
+		Added: make([]Scenario, 0), Removed: make([]Scenario, 0), Slower: make([]*Slowdown, 0)}
+	previousResults := scenarioResults(previous)
+	oldResults := make(map[Scenario]scenarioResult)
+	for _, s := range previousResults {
+		oldResults[s.Scenario] = s
+	}
+	seen := make(map[Scenario]bool)
+	for _, s := range scenarioResults(latest) {
+		if seen[s.Scenario] {
+			continue
+		}
+		seen[s.Scenario] = true
+		o, ok := oldResults[s.Scenario]
+		if !ok {
+			d.Added = append(d.Added, s.Scenario)
+			continue
+		}
+		oldFailed, newFailed := o.status == gauge_messages.ExecutionStatus_FAILED, s.status == gauge_messages.ExecutionStatus_FAILED
+		switch {
+		case oldFailed && newFailed:
+			d.ConsistentlyFailing = append(d.ConsistentlyFailing, s.Scenario)
+		case newFailed:
+			d.NewlyFailing = append(d.NewlyFailing, s.Scenario)
+		case oldFailed && s.status == gauge_messages.ExecutionStatus_PASSED:
+			d.NewlyPassing = append(d.NewlyPassing, s.Scenario)
+		}
+		if isSlower(o, s, slowerBy) {
+			d.Slower = append(d.Slower, &Slowdown{Scenario: s.Scenario, OldTime: o.time, NewTime: s.time})
+		}
+	}
+	for _, s := range previousResults {
+		if !seen[s.Scenario] {
+			seen[s.Scenario] = true
+			d.Removed = append(d.Removed, s.Scenario)
+		}
+	}
+	return d
+}
+
+func isSlower(previous, latest scenarioResult, slowerBy int) bool {
+	if previous.status == gauge_messages.ExecutionStatus_SKIPPED || latest.status == gauge_messages.ExecutionStatus_SKIPPED {
+		return false
+	}
+	return latest.time-previous.time >= minSlowdown && latest.time*100 >= previous.time*int64(100+slowerBy)
+}
+
+// PrintDiff writes the diff in the given format. The markdown is suitable for a comment on a pull request.
+func PrintDiff(w io.Writer, d *Diff, format string) error {
+	switch format {
+	case JSONFormat:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case MarkdownFormat:
+		printMarkdownDiff(w, d)
+	default:
+		printTextDiff(w, d)
+	}
+	return nil
+}
+
+func printTextDiff(w io.Writer, d *Diff) {
+	for i, section := range diffSections(d) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s (%d)\n", section.title, len(section.scenarios))
+		if len(section.scenarios) == 0 {
+			fmt.Fprintln(w, "  None")
+		}
+		for _, e := range section.scenarios {
+			fmt.Fprintf(w, "  %s%s\n", e.scenario, e.detail)
+		}
+	}
+}
+
+func printMarkdownDiff(w io.Writer, d *Diff) {
+	fmt.Fprintln(w, "### Gauge result diff")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Change | Scenarios |")
+	fmt.Fprintln(w, "| --- | ---: |")
+	sections := diffSections(d)
+	for _, section := range sections {
+		fmt.Fprintf(w, "| %s | %d |\n", section.title, len(section.scenarios))
+	}
+	for _, section := range sections {
+		if len(section.scenarios) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n#### %s\n\n", section.title)
+		for _, e := range section.scenarios {
+			fmt.Fprintf(w, "- %s%s\n", markdownEscaper.Replace(e.scenario), e.detail)
+		}
+	}
+}
+
+var markdownEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]", "<", "&lt;", ">", "&gt;", "|", "\\|")
+
+type diffSection struct {
+	title     string
+	scenarios []diffEntry
+}
+
+type diffEntry struct {
+	scenario string
+	detail   string
+}
+
+func diffSections(d *Diff) []diffSection {
+	names := func(scenarios []Scenario) []diffEntry {
+		var e []diffEntry
+		for _, s := range scenarios {
+			e = append(e, diffEntry{scenario: s.String()})
+		}
+		return e
+	}
+	var slower []diffEntry
+	for _, s := range d.Slower {
+		slower = append(slower, diffEntry{scenario: s.String(), detail: fmt.Sprintf(": %s -> %s", duration(s.OldTime), duration(s.NewTime))})
+	}
+	return []diffSection{
+		{"Newly failing", names(d.NewlyFailing)},
+		{"Newly passing", names(d.NewlyPassing)},
+		{"Consistently failing", names(d.ConsistentlyFailing)},
+		{"Added", names(d.Added)},
+		{"Removed", names(d.Removed)},
+		{"Significantly slower", slower},
+	}
+}
@@ -0,0 +1,198 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	m "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"google.golang.org/protobuf/proto"
+)
+
+func exampleDiff() *Diff {
+	previous := suiteResult(
+		scenario("fixed", m.ExecutionStatus_FAILED, 10),
+		scenario("broken", m.ExecutionStatus_PASSED, 10),
+		scenario("still broken", m.ExecutionStatus_FAILED, 10),
+		scenario("deleted", m.ExecutionStatus_PASSED, 10),
+		scenario("slow", m.ExecutionStatus_PASSED, 1000),
+		scenario("noisy", m.ExecutionStatus_PASSED, 10),
+	)
+	latest := suiteResult(
+		scenario("fixed", m.ExecutionStatus_PASSED, 10),
+		scenario("broken", m.ExecutionStatus_FAILED, 10),
+		scenario("still broken", m.ExecutionStatus_FAILED, 10),
+		scenario("new", m.ExecutionStatus_PASSED, 10),
+		scenario("slow", m.ExecutionStatus_PASSED, 2500),
+		scenario("noisy", m.ExecutionStatus_PASSED, 500),
+	)
+	return NewDiff(previous, latest, 50)
+}
+
+func headings(scenarios []Scenario) []string {
+	var h []string
+	for _, s := range scenarios {
+		h = append(h, s.Heading)
+	}
+	return h
+}
+
+func TestNewDiff(t *testing.T) {
+	d := exampleDiff()
+
+	for name, c := range map[string]struct {
+		got, want []string
+	}{
+		"newly failing":        {headings(d.NewlyFailing), []string{"broken"}},
+		"newly passing":        {headings(d.NewlyPassing), []string{"fixed"}},
+		"consistently failing": {headings(d.ConsistentlyFailing), []string{"still broken"}},
+		"added":                {headings(d.Added), []string{"new"}},
+		"removed":              {headings(d.Removed), []string{"deleted"}},
+	} {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("Expected %s scenarios %v, got %v", name, c.want, c.got)
+		}
+	}
+	if len(d.Slower) != 1 || d.Slower[0].Heading != "slow" || d.Slower[0].OldTime != 1000 || d.Slower[0].NewTime != 2500 {
+		t.Errorf("Expected only the slow scenario to be slower, got %+v", d.Slower)
+	}
+	if d.NewlyFailing[0].Spec != "specs/example.spec" {
+		t.Errorf("Expected the scenarios to be keyed by the spec path, got %s", d.NewlyFailing[0].Spec)
+	}
+}
+
+func TestNewDiffKeepsTableDrivenScenariosApart(t *testing.T) {
+	rows := func(first, second m.ExecutionStatus) *m.ProtoSuiteResult {
+		tableDriven := func(row int32, status m.ExecutionStatus) *m.ProtoItem {
+			return &m.ProtoItem{ItemType: m.ProtoItem_TableDrivenScenario, TableDrivenScenario: &m.ProtoTableDrivenScenario{
+				Scenario: &m.ProtoScenario{ScenarioHeading: "vowels", ExecutionStatus: status}, TableRowIndex: row,
+			}}
+		}
+		return suiteResult(tableDriven(0, first), tableDriven(1, second))
+	}
+
+	d := NewDiff(rows(m.ExecutionStatus_PASSED, m.ExecutionStatus_PASSED), rows(m.ExecutionStatus_PASSED, m.ExecutionStatus_FAILED), 50)
+
+	if len(d.NewlyFailing) != 1 || d.NewlyFailing[0].Row != "spec row 2" {
+		t.Errorf("Expected only the second row to be newly failing, got %v", d.NewlyFailing)
+	}
+}
+
+func TestNewDiffMatchesSpecsOfDifferentWorkspaces(t *testing.T) {
+	run := func(root string, status m.ExecutionStatus) *m.ProtoSuiteResult {
+		spec := func(file string, items ...*m.ProtoItem) *m.ProtoSpecResult {
+			return &m.ProtoSpecResult{ProtoSpec: &m.ProtoSpec{FileName: root + file, Items: items}}
+		}
+		return &m.ProtoSuiteResult{ProjectName: "shop", SpecResults: []*m.ProtoSpecResult{
+			spec("/specs/login/login.spec", scenario("login", status, 10)),
+			spec("/specs/cart.spec", scenario("checkout", m.ExecutionStatus_PASSED, 10)),
+		}}
+	}
+
+	d := NewDiff(run("/ci/workspace-1/shop", m.ExecutionStatus_PASSED), run("/ci/workspace-2/shop", m.ExecutionStatus_FAILED), 50)
+
+	if len(d.Added) != 0 || len(d.Removed) != 0 {
+		t.Errorf("Expected the specs to match across the workspaces, got added %v and removed %v", d.Added, d.Removed)
+	}
+	if len(d.NewlyFailing) != 1 || d.NewlyFailing[0].Spec != "specs/login/login.spec" {
+		t.Errorf("Expected specs/login/login.spec to be newly failing, got %v", d.NewlyFailing)
+	}
+}
+
+func TestNewDiffMatchesSpecsWhenTheLatestRunAddsADirectory(t *testing.T) {
+	spec := func(file string, items ...*m.ProtoItem) *m.ProtoSpecResult {
+		return &m.ProtoSpecResult{ProtoSpec: &m.ProtoSpec{FileName: file, Items: items}}
+	}
+	previous := &m.ProtoSuiteResult{ProjectName: "shop", SpecResults: []*m.ProtoSpecResult{
+		spec("/ci/workspace-1/shop/specs/login/login.spec", scenario("login", m.ExecutionStatus_PASSED, 10)),
+	}}
+	latest := &m.ProtoSuiteResult{ProjectName: "shop", SpecResults: []*m.ProtoSpecResult{
+		spec("/ci/workspace-2/shop/specs/login/login.spec", scenario("login", m.ExecutionStatus_FAILED, 10)),
+		spec("/ci/workspace-2/shop/specs/cart/cart.spec", scenario("checkout", m.ExecutionStatus_PASSED, 10)),
+	}}
+
+	d := NewDiff(previous, latest, 50)
+
+	if len(d.Removed) != 0 || len(d.Added) != 1 || d.Added[0].Spec != "specs/cart/cart.spec" {
+		t.Errorf("Expected only specs/cart/cart.spec to be added, got added %v and removed %v", d.Added, d.Removed)
+	}
+	if len(d.NewlyFailing) != 1 || d.NewlyFailing[0].Spec != "specs/login/login.spec" {
+		t.Errorf("Expected specs/login/login.spec to be newly failing, got %v", d.NewlyFailing)
+	}
+}
+
+func TestPrintDiff(t *testing.T) {
+	var text bytes.Buffer
+	if err := PrintDiff(&text, exampleDiff(), TextFormat); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Newly failing (1)\n  broken (specs/example.spec)\n", "Added (1)", "Significantly slower (1)\n  slow (specs/example.spec): 1s -> 2.5s"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected the text diff to contain %q, got\n%s", want, text.String())
+		}
+	}
+
+	var md bytes.Buffer
+	if err := PrintDiff(&md, exampleDiff(), MarkdownFormat); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| Newly failing | 1 |", "#### Consistently failing\n\n- still broken (specs/example.spec)\n", "- slow (specs/example.spec): 1s -> 2.5s"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Expected the markdown diff to contain %q, got\n%s", want, md.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := PrintDiff(&js, exampleDiff(), JSONFormat); err != nil {
+		t.Fatal(err)
+	}
+	var d Diff
+	if err := json.Unmarshal(js.Bytes(), &d); err != nil {
+		t.Fatalf("Expected valid json, got %s", err.Error())
+	}
+	if d.Type != "diff" || len(d.Removed) != 1 || d.Removed[0].Heading != "deleted" {
+		t.Errorf("Expected the diff in json, got %s", js.String())
+	}
+}
+
+func TestReadResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	b, _ := proto.Marshal(suiteResult(scenario("one", m.ExecutionStatus_PASSED, 10)))
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(b)
+	zw.Close()
+
+	for name, content := range map[string][]byte{"last_run_result": b, "run.pb.gz": compressed.Bytes()} {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		res, err := ReadResult(file)
+		if err != nil {
+			t.Fatalf("Expected %s to be read, got %s", name, err.Error())
+		}
+		if got := res.GetSpecResults()[0].GetProtoSpec().GetItems()[0].GetScenario().GetScenarioHeading(); got != "one" {
+			t.Errorf("Expected the saved scenario in %s, got %q", name, got)
+		}
+	}
+	if _, err := ReadResult(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Expected an error for a missing result file")
+	}
+}
@@ -0,0 +1,300 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+// Package history keeps the results of the previous executions of a project in .gauge/history, when the
+// save_execution_history property is set. Each run is saved as a gzip compressed ProtoSuiteResult, and
+// index.json holds a summary of the runs from the oldest to the latest. Once there are more runs than
+// the execution_history_limit property allows, the oldest ones are removed. The history is locked with
+// index.lock while a run is saved, so that concurrent executions of the project do not lose each other's runs.
+package history
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getgauge/common"
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	dirName   = "history"
+	indexFile = "index.json"
+	lockFile  = "index.lock"
+	runExt    = ".pb.gz"
+	idLayout  = "20060102-150405.000000000"
+)
+
+var (
+	// lockTimeout is how long Save waits for the other executions of the project to release the history
+	lockTimeout = 30 * time.Second
+	// staleLockAge is the age after which a lock is taken to be left behind by a killed execution, and is removed
+	staleLockAge = 5 * time.Minute
+	lockInterval = 100 * time.Millisecond
+)
+
+// Run is the summary of an execution, as kept in the history index
+type Run struct {
+	ID               string    `json:"id"`
+	File             string    `json:"file"`
+	Timestamp        time.Time `json:"timestamp"`
+	Failed           bool      `json:"failed"`
+	ExecutionTime    int64     `json:"executionTime"`
+	Scenarios        int       `json:"scenarios"`
+	ScenariosPassed  int       `json:"scenariosPassed"`
+	ScenariosFailed  int       `json:"scenariosFailed"`
+	ScenariosSkipped int       `json:"scenariosSkipped"`
+}
+
+// PassRate is the percentage of scenarios that passed in the run
+func (r *Run) PassRate() float32 {
+	if r.Scenarios == 0 {
+		return 0
+	}
+	return float32(r.ScenariosPassed) * 100 / float32(r.Scenarios)
+}
+
+type index struct {
+	Runs []*Run `json:"runs"`
+}
+
+// Dir is the directory holding the execution history of the project
+func Dir() string {
+	return filepath.Join(config.ProjectRoot, common.DotGauge, dirName)
+}
+
+// ListenSuiteEndAndSave listens to the suite end event and adds the suite result to the execution history
+func ListenSuiteEndAndSave(wg *sync.WaitGroup) {
+	ch := make(chan event.ExecutionEvent)
+	event.Register(ch, event.SuiteEnd)
+	wg.Add(1)
+
+	go func() {
+		for {
+			e := <-ch
+			if e.Topic == event.SuiteEnd {
+				dir := Dir()
+				if r, err := Save(dir, gauge.ConvertToProtoSuiteResult(e.Result.(*result.SuiteResult)), env.ExecutionHistoryLimit()); err != nil {
+					logger.Errorf(true, "Failed to save execution history to %s. Reason: %s", dir, err.Error())
+				} else {
+					logger.Debugf(true, "Execution result saved to history as %s", r.File)
+				}
+				wg.Done()
+				return
+			}
+		}
+	}()
+}
+
+// Save appends the suite result to the history in dir, and removes the oldest runs so that at most limit runs are kept.
+// A limit less than 1 keeps all the runs.
+func Save(dir string, res *gauge_messages.ProtoSuiteResult, limit int) (*Run, error) {
+	if err := os.MkdirAll(dir, common.NewDirectoryPermissions); err != nil {
+		return nil, err
+	}
+	unlock, err := lock(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	b, err := proto.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	runs, indexErr := Runs(dir)
+	if indexErr != nil {
+		logger.Warningf(true, "Unable to read the execution history index, rebuilding it from the saved runs. %s", indexErr.Error())
+		runs = savedRuns(dir)
+	}
+	now := time.Now()
+	r := newRun(res, now.UTC().Format(idLayout), now)
+	if err := ioutil.WriteFile(filepath.Join(dir, r.File), buf.Bytes(), common.NewFilePermissions); err != nil {
+		return nil, err
+	}
+	runs = append(runs, r)
+	var removed []*Run
+	if limit > 0 && len(runs) > limit {
+		removed, runs = runs[:len(runs)-limit], runs[len(runs)-limit:]
+	}
+	if err := writeIndex(dir, runs); err != nil {
+		return nil, err
+	}
+	for _, old := range removed {
+		if err := os.Remove(filepath.Join(dir, old.File)); err != nil && !os.IsNotExist(err) {
+			logger.Debugf(true, "Unable to remove %s from execution history. %s", old.File, err.Error())
+		}
+	}
+	// the files of a rebuilt index which could not be read are kept, as they are not known to be left behind
+	if indexErr == nil {
+		pruneRuns(dir, runs)
+	}
+	return r, nil
+}
+
+// savedRuns reads the runs saved in dir, from the oldest to the latest, to rebuild the index. The runs which cannot be read are skipped.
+func savedRuns(dir string) []*Run {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+runExt))
+	if err != nil {
+		return nil
+	}
+	// the ids, and so the file names, are ordered by the time of the run
+	sort.Strings(files)
+	var runs []*Run
+	for _, f := range files {
+		id := strings.TrimSuffix(filepath.Base(f), runExt)
+		t, err := time.Parse(idLayout, id)
+		if err != nil {
+			logger.Debugf(true, "Skipping %s in the execution history, as it is not a saved run.", f)
+			continue
+		}
+		res, err := Load(dir, &Run{File: filepath.Base(f)})
+		if err != nil {
+			logger.Debugf(true, "Skipping %s in the execution history. %s", f, err.Error())
+			continue
+		}
+		runs = append(runs, newRun(res, id, t))
+	}
+	return runs
+}
+
+// lock acquires the lock on the history in dir by creating the lock file, so that the runs saved by concurrent
+// executions of the project are not lost. The returned func releases the lock.
+func lock(dir string) (func(), error) {
+	file := filepath.Join(dir, lockFile)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, common.NewFilePermissions)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(file) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(file); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			logger.Debugf(true, "Removing the stale execution history lock %s.", file)
+			os.Remove(file)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for another execution to release %s", file)
+		}
+		time.Sleep(lockInterval)
+	}
+}
+
+// pruneRuns removes the saved results which are not in the index, e.g. left behind by an execution killed while saving its run.
+func pruneRuns(dir string, runs []*Run) {
+	kept := make(map[string]bool)
+	for _, r := range runs {
+		kept[r.File] = true
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+runExt))
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if kept[filepath.Base(f)] {
+			continue
+		}
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			logger.Debugf(true, "Unable to remove %s from execution history. %s", f, err.Error())
+		}
+	}
+}
+
+func newRun(res *gauge_messages.ProtoSuiteResult, id string, t time.Time) *Run {
+	r := &Run{ID: id, File: id + runExt, Timestamp: t, Failed: res.GetFailed(), ExecutionTime: res.GetExecutionTime()}
+	for _, specRes := range res.GetSpecResults() {
+		for _, scn := range result.Scenarios(specRes.GetProtoSpec()) {
+			r.Scenarios++
+			switch scn.GetExecutionStatus() {
+			case gauge_messages.ExecutionStatus_FAILED:
+				r.ScenariosFailed++
+			case gauge_messages.ExecutionStatus_SKIPPED:
+				r.ScenariosSkipped++
+			default:
+				r.ScenariosPassed++
+			}
+		}
+	}
+	return r
+}
+
+// Runs returns the runs in the history in dir, from the oldest to the latest
+func Runs(dir string) ([]*Run, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var i index
+	if err := json.Unmarshal(b, &i); err != nil {
+		return nil, fmt.Errorf("invalid history index %s. %s", filepath.Join(dir, indexFile), err.Error())
+	}
+	return i.Runs, nil
+}
+
+// writeIndex replaces the index by renaming a temporary file, so that an interrupted write does not lose the history.
+func writeIndex(dir string, runs []*Run) error {
+	b, err := json.MarshalIndent(index{Runs: runs}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, indexFile+".tmp")
+	if err := ioutil.WriteFile(tmp, b, common.NewFilePermissions); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, indexFile))
+}
+
+// Load reads the suite result of the given run from the history in dir
+func Load(dir string, r *Run) (*gauge_messages.ProtoSuiteResult, error) {
+	f, err := os.Open(filepath.Join(dir, r.File))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	res := &gauge_messages.ProtoSuiteResult{}
+	if err := proto.Unmarshal(b, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,262 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	m "github.com/getgauge/gauge-proto/go/gauge_messages"
+)
+
+var testTime = time.Date(2020, 1, 2, 10, 30, 0, 0, time.UTC)
+
+func stepItem(text string, failed bool, err string) *m.ProtoItem {
+	return &m.ProtoItem{ItemType: m.ProtoItem_Step, Step: &m.ProtoStep{ActualText: text, ParsedText: text, StepExecutionResult: &m.ProtoStepExecutionResult{
+		ExecutionResult: &m.ProtoExecutionResult{Failed: failed, ErrorMessage: err},
+	}}}
+}
+
+func scenario(heading string, status m.ExecutionStatus, time int64, items ...*m.ProtoItem) *m.ProtoItem {
+	return &m.ProtoItem{ItemType: m.ProtoItem_Scenario, Scenario: &m.ProtoScenario{ScenarioHeading: heading, ExecutionStatus: status, ExecutionTime: time, ScenarioItems: items}}
+}
+
+func suiteResult(items ...*m.ProtoItem) *m.ProtoSuiteResult {
+	return &m.ProtoSuiteResult{SpecResults: []*m.ProtoSpecResult{{ProtoSpec: &m.ProtoSpec{FileName: "specs/example.spec", Items: items}}}}
+}
+
+func TestSaveAppendsRunsAndAppliesRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var saved []*Run
+	for i := 0; i < 3; i++ {
+		r, err := Save(dir, suiteResult(scenario("one", m.ExecutionStatus_PASSED, 10), scenario("two", m.ExecutionStatus_FAILED, 20)), 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %s", err.Error())
+		}
+		saved = append(saved, r)
+	}
+
+	runs, err := Runs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != saved[1].ID || runs[1].ID != saved[2].ID {
+		t.Fatalf("Expected the latest 2 runs to be kept, got %v", runs)
+	}
+	if _, err := os.Stat(filepath.Join(dir, saved[0].File)); !os.IsNotExist(err) {
+		t.Errorf("Expected the result of the oldest run to be removed")
+	}
+	if runs[1].Scenarios != 2 || runs[1].ScenariosPassed != 1 || runs[1].ScenariosFailed != 1 || runs[1].PassRate() != 50 {
+		t.Errorf("Unexpected run summary %+v", runs[1])
+	}
+	res, err := Load(dir, runs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.GetSpecResults()[0].GetProtoSpec().GetItems()[1].GetScenario().GetScenarioHeading(); got != "two" {
+		t.Errorf("Expected the saved result to be loaded, got scenario %s", got)
+	}
+}
+
+func TestSaveRebuildsCorruptIndexFromTheSavedRuns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	old, err := Save(dir, suiteResult(scenario("one", m.ExecutionStatus_FAILED, 10)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, indexFile), []byte("{corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "unreadable"+runExt), []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := Save(dir, suiteResult(scenario("one", m.ExecutionStatus_PASSED, 10)), 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	runs, err := Runs(dir)
+	if err != nil || len(runs) != 2 || runs[0].ID != old.ID || runs[1].ID != latest.ID {
+		t.Fatalf("Expected both runs in the rebuilt index, got %v, %v", runs, err)
+	}
+	if runs[0].ScenariosFailed != 1 || !runs[0].Timestamp.Equal(old.Timestamp) {
+		t.Errorf("Expected the summary of the saved run, got %+v", runs[0])
+	}
+	for _, f := range []string{old.File, "unreadable" + runExt} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("Expected %s to be kept, got %s", f, err.Error())
+		}
+	}
+}
+
+func TestSaveRemovesRunsMissingFromTheIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	orphan := filepath.Join(dir, "20200102-103000.000000000"+runExt)
+	if err := ioutil.WriteFile(orphan, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Save(dir, suiteResult(scenario("one", m.ExecutionStatus_PASSED, 10)), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Errorf("Expected the run missing from the index to be removed")
+	}
+}
+
+func TestConcurrentSavesKeepAllRuns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Save(dir, suiteResult(scenario("one", m.ExecutionStatus_PASSED, 10)), 0); err != nil {
+				t.Errorf("Expected no error, got %s", err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+
+	runs, err := Runs(dir)
+	if err != nil || len(runs) != 5 {
+		t.Fatalf("Expected the 5 runs in the index, got %d, %v", len(runs), err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, lockFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the lock to be released")
+	}
+}
+
+func TestStaleLockIsRemoved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, lockFile)
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(file, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Save(dir, suiteResult(scenario("one", m.ExecutionStatus_PASSED, 10)), 0); err != nil {
+		t.Fatalf("Expected the stale lock to be removed, got %s", err.Error())
+	}
+}
+
+func TestRunsWithoutHistory(t *testing.T) {
+	runs, err := Runs(filepath.Join(os.TempDir(), "no-such-history"))
+	if err != nil || runs != nil {
+		t.Errorf("Expected no runs and no error, got %v, %v", runs, err)
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	results := []*m.ProtoSuiteResult{
+		suiteResult(
+			scenario("login", m.ExecutionStatus_PASSED, 100, stepItem("open <page>", false, "")),
+			scenario("search", m.ExecutionStatus_FAILED, 300, stepItem("search for <text>", true, "timeout")),
+			scenario("checkout", m.ExecutionStatus_PASSED, 50),
+		),
+		suiteResult(
+			scenario("login", m.ExecutionStatus_FAILED, 300,
+				&m.ProtoItem{ItemType: m.ProtoItem_Concept, Concept: &m.ProtoConcept{Steps: []*m.ProtoItem{stepItem("search for <text>", true, "not found")}}}),
+			scenario("search", m.ExecutionStatus_FAILED, 100, stepItem("search for <text>", true, "timeout")),
+			scenario("checkout", m.ExecutionStatus_SKIPPED, 0),
+		),
+	}
+	runs := []*Run{newRun(results[0], "1", testTime), newRun(results[1], "2", testTime)}
+
+	r := NewReport(runs, results, 2)
+
+	if len(r.Runs) != 2 || r.Runs[1].PassRate != 0 || r.Runs[0].ScenariosPassed != 2 {
+		t.Errorf("Unexpected run trends %v", r.Runs)
+	}
+	if len(r.SlowestScenarios) != 2 || r.SlowestScenarios[0].Heading != "login" || r.SlowestScenarios[0].AverageTime != 200 || r.SlowestScenarios[1].Heading != "search" {
+		t.Errorf("Unexpected slowest scenarios %v", r.SlowestScenarios)
+	}
+	if len(r.NewFailures) != 1 || r.NewFailures[0].Heading != "login" {
+		t.Errorf("Expected login to be the only new failure, got %v", r.NewFailures)
+	}
+	if len(r.FailingSteps) != 1 || r.FailingSteps[0].Step != "search for <text>" || r.FailingSteps[0].Failures != 3 {
+		t.Errorf("Unexpected failing steps %v", r.FailingSteps)
+	}
+}
+
+func TestNewReportKeepsTableDrivenScenariosApart(t *testing.T) {
+	row := func(i int32, status m.ExecutionStatus) *m.ProtoItem {
+		return &m.ProtoItem{ItemType: m.ProtoItem_TableDrivenScenario, TableDrivenScenario: &m.ProtoTableDrivenScenario{
+			Scenario: &m.ProtoScenario{ScenarioHeading: "row", ExecutionStatus: status}, TableRowIndex: i, IsSpecTableDriven: true}}
+	}
+	results := []*m.ProtoSuiteResult{
+		suiteResult(row(0, m.ExecutionStatus_FAILED), row(1, m.ExecutionStatus_PASSED)),
+		suiteResult(row(0, m.ExecutionStatus_FAILED), row(1, m.ExecutionStatus_FAILED)),
+	}
+
+	r := NewReport(nil, results, 5)
+
+	if len(r.NewFailures) != 1 || r.NewFailures[0].Row != "spec row 2" {
+		t.Errorf("Expected only the second row to be a new failure, got %v", r.NewFailures)
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	results := []*m.ProtoSuiteResult{suiteResult(scenario("login", m.ExecutionStatus_FAILED, 1500, stepItem("open <page>", true, "boom")))}
+	r := NewReport([]*Run{newRun(results[0], "1", testTime)}, results, 5)
+
+	var text bytes.Buffer
+	if err := PrintReport(&text, r, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Pass rate of the last 1 runs", "0 passed, 1 failed, 0 skipped in 0s", "1.5s  login (specs/example.spec)", "New failures since the previous run\n  None", "1  open <page>"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("Expected output to contain %q, got\n%s", want, text.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := PrintReport(&out, r, true); err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("Expected valid JSON, got %s", out.String())
+	}
+	if got.Type != "history" || len(got.Runs) != 1 || got.Runs[0].ID != "1" || len(got.FailingSteps) != 1 {
+		t.Errorf("Unexpected JSON report %s", out.String())
+	}
+}
@@ -0,0 +1,252 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+)
+
+// Scenario identifies a scenario across runs. Row is set for the scenarios executed for a data table row.
+type Scenario struct {
+	Spec    string `json:"spec"`
+	Heading string `json:"heading"`
+	Row     string `json:"row,omitempty"`
+}
+
+func (s Scenario) String() string {
+	return fmt.Sprintf("%s (%s)", result.WithTableRows(s.Heading, s.Row), s.Spec)
+}
+
+// ScenarioTime is the average execution time of a scenario in the runs it was executed
+type ScenarioTime struct {
+	Scenario
+	AverageTime int64 `json:"averageTime"`
+	Runs        int   `json:"runs"`
+}
+
+// StepFailures is the number of times a step failed in the runs
+type StepFailures struct {
+	Step      string `json:"step"`
+	Failures  int    `json:"failures"`
+	LastError string `json:"lastError"`
+}
+
+// RunTrend is the pass rate of a run
+type RunTrend struct {
+	*Run
+	PassRate float32 `json:"passRate"`
+}
+
+// Report summarises the trends in the given runs
+type Report struct {
+	Type             string          `json:"type"`
+	Runs             []*RunTrend     `json:"runs"`
+	SlowestScenarios []*ScenarioTime `json:"slowestScenarios"`
+	NewFailures      []Scenario      `json:"newFailures"`
+	FailingSteps     []*StepFailures `json:"failingSteps"`
+}
+
+type scenarioResult struct {
+	Scenario
+	status gauge_messages.ExecutionStatus
+	time   int64
+}
+
+// NewReport builds the report for the given runs and their suite results, ordered from the oldest to the latest.
+// The slowest scenarios and failing steps are limited to the top entries.
+func NewReport(runs []*Run, results []*gauge_messages.ProtoSuiteResult, top int) *Report {
+	r := &Report{Type: "history", Runs: make([]*RunTrend, 0), SlowestScenarios: make([]*ScenarioTime, 0), NewFailures: make([]Scenario, 0), FailingSteps: make([]*StepFailures, 0)}
+	for _, run := range runs {
+		r.Runs = append(r.Runs, &RunTrend{Run: run, PassRate: run.PassRate()})
+	}
+	times := make(map[Scenario]*ScenarioTime)
+	steps := make(map[string]*StepFailures)
+	for _, res := range results {
+		for _, s := range scenarioResults(res) {
+			if s.status == gauge_messages.ExecutionStatus_SKIPPED {
+				continue
+			}
+			t, ok := times[s.Scenario]
+			if !ok {
+				t = &ScenarioTime{Scenario: s.Scenario}
+				times[s.Scenario] = t
+			}
+			t.AverageTime += s.time
+			t.Runs++
+		}
+		for _, specRes := range res.GetSpecResults() {
+			for _, scn := range result.Scenarios(specRes.GetProtoSpec()) {
+				for _, items := range [][]*gauge_messages.ProtoItem{scn.GetContexts(), scn.GetScenarioItems(), scn.GetTearDownSteps()} {
+					countFailedSteps(items, steps)
+				}
+			}
+		}
+	}
+	for _, t := range times {
+		t.AverageTime /= int64(t.Runs)
+		r.SlowestScenarios = append(r.SlowestScenarios, t)
+	}
+	sort.Slice(r.SlowestScenarios, func(i, j int) bool {
+		a, b := r.SlowestScenarios[i], r.SlowestScenarios[j]
+		if a.AverageTime != b.AverageTime {
+			return a.AverageTime > b.AverageTime
+		}
+		return a.String() < b.String()
+	})
+	for _, s := range steps {
+		r.FailingSteps = append(r.FailingSteps, s)
+	}
+	sort.Slice(r.FailingSteps, func(i, j int) bool {
+		a, b := r.FailingSteps[i], r.FailingSteps[j]
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.Step < b.Step
+	})
+	if top > 0 {
+		if len(r.SlowestScenarios) > top {
+			r.SlowestScenarios = r.SlowestScenarios[:top]
+		}
+		if len(r.FailingSteps) > top {
+			r.FailingSteps = r.FailingSteps[:top]
+		}
+	}
+	if n := len(results); n > 1 {
+		r.NewFailures = newFailures(results[n-2], results[n-1])
+	}
+	return r
+}
+
+// newFailures returns the scenarios that failed in the latest run, but had not failed in the previous one
+func newFailures(previous, latest *gauge_messages.ProtoSuiteResult) []Scenario {
+	failed := make(map[Scenario]bool)
+	for _, s := range scenarioResults(previous) {
+		if s.status == gauge_messages.ExecutionStatus_FAILED {
+			failed[s.Scenario] = true
+		}
+	}
+	scenarios := make([]Scenario, 0)
+	for _, s := range scenarioResults(latest) {
+		if s.status == gauge_messages.ExecutionStatus_FAILED && !failed[s.Scenario] {
+			scenarios = append(scenarios, s.Scenario)
+		}
+	}
+	return scenarios
+}
+
+func scenarioResults(res *gauge_messages.ProtoSuiteResult) []scenarioResult {
+	var results []scenarioResult
+	specPath := result.SpecPaths(res)
+	for _, specRes := range res.GetSpecResults() {
+		spec := specPath(specRes.GetProtoSpec().GetFileName())
+		for _, item := range specRes.GetProtoSpec().GetItems() {
+			var scn *gauge_messages.ProtoScenario
+			var row string
+			switch item.GetItemType() {
+			case gauge_messages.ProtoItem_Scenario:
+				scn = item.GetScenario()
+			case gauge_messages.ProtoItem_TableDrivenScenario:
+				scn = item.GetTableDrivenScenario().GetScenario()
+				row = result.TableRow(item.GetTableDrivenScenario())
+			default:
+				continue
+			}
+			results = append(results, scenarioResult{
+				Scenario: Scenario{Spec: spec, Heading: scn.GetScenarioHeading(), Row: row},
+				status:   scn.GetExecutionStatus(),
+				time:     scn.GetExecutionTime(),
+			})
+		}
+	}
+	return results
+}
+
+// countFailedSteps counts the failed steps by their parsed text, so that a step failing with different arguments is counted as one.
+func countFailedSteps(items []*gauge_messages.ProtoItem, steps map[string]*StepFailures) {
+	for _, item := range items {
+		switch item.GetItemType() {
+		case gauge_messages.ProtoItem_Step:
+			res := item.GetStep().GetStepExecutionResult().GetExecutionResult()
+			if !res.GetFailed() {
+				continue
+			}
+			text := item.GetStep().GetParsedText()
+			if text == "" {
+				text = item.GetStep().GetActualText()
+			}
+			s, ok := steps[text]
+			if !ok {
+				s = &StepFailures{Step: text}
+				steps[text] = s
+			}
+			s.Failures++
+			s.LastError = res.GetErrorMessage()
+		case gauge_messages.ProtoItem_Concept:
+			countFailedSteps(item.GetConcept().GetSteps(), steps)
+		}
+	}
+}
+
+// PrintReport writes the report as text, or as JSON if machineReadable is set
+func PrintReport(w io.Writer, r *Report, machineReadable bool) error {
+	if machineReadable {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		// logger is not used, as it wraps the output in a message, which breaks the json format.
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+	fmt.Fprintf(w, "Pass rate of the last %d runs\n", len(r.Runs))
+	var previous *RunTrend
+	for _, run := range r.Runs {
+		fmt.Fprintf(w, "  %s  %6.2f%%%s  %d passed, %d failed, %d skipped in %s\n", run.Timestamp.Local().Format("2006-01-02 15:04:05"),
+			run.PassRate, change(previous, run), run.ScenariosPassed, run.ScenariosFailed, run.ScenariosSkipped, duration(run.ExecutionTime))
+		previous = run
+	}
+	fmt.Fprintln(w, "\nSlowest scenarios")
+	if len(r.SlowestScenarios) == 0 {
+		fmt.Fprintln(w, "  None")
+	}
+	for _, s := range r.SlowestScenarios {
+		fmt.Fprintf(w, "  %10s  %s\n", duration(s.AverageTime), s.Scenario)
+	}
+	fmt.Fprintln(w, "\nNew failures since the previous run")
+	if len(r.NewFailures) == 0 {
+		fmt.Fprintln(w, "  None")
+	}
+	for _, s := range r.NewFailures {
+		fmt.Fprintf(w, "  %s\n", s)
+	}
+	fmt.Fprintln(w, "\nMost frequently failing steps")
+	if len(r.FailingSteps) == 0 {
+		fmt.Fprintln(w, "  None")
+	}
+	for _, s := range r.FailingSteps {
+		fmt.Fprintf(w, "  %4d  %s\n", s.Failures, s.Step)
+	}
+	return nil
+}
+
+func change(previous, run *RunTrend) string {
+	if previous == nil {
+		return "        "
+	}
+	return fmt.Sprintf(" (%+5.1f)", run.PassRate-previous.PassRate)
+}
+
+func duration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
@@ -0,0 +1,151 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/logger"
+)
+
+const interruptedReason = "skipped Reason: Execution was interrupted"
+
+// InterruptGracePeriod is the time given to the steps in progress to complete after the execution is interrupted,
+// after which the runners are killed.
+var InterruptGracePeriod = 30 * time.Second
+
+var interrupted int32
+
+// interruptListeners are called when the execution is interrupted
+var interruptListeners struct {
+	sync.Mutex
+	listeners []func()
+}
+
+// onInterrupt registers a function to be called when the execution is interrupted.
+func onInterrupt(f func()) {
+	interruptListeners.Lock()
+	defer interruptListeners.Unlock()
+	interruptListeners.listeners = append(interruptListeners.listeners, f)
+}
+
+func notifyInterrupted() {
+	interruptListeners.Lock()
+	defer interruptListeners.Unlock()
+	for _, f := range interruptListeners.listeners {
+		f()
+	}
+}
+
+func isInterrupted() bool {
+	return atomic.LoadInt32(&interrupted) == 1
+}
+
+// runners keeps track of the runners started for the execution, so that they can be killed if the execution is interrupted.
+var runners = &runnerRegistry{}
+
+type runnerRegistry struct {
+	mutex   sync.Mutex
+	runners []*restartableRunner
+}
+
+func (r *runnerRegistry) add(rr *restartableRunner) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.runners = append(r.runners, rr)
+}
+
+// killAll kills the runners forcefully, as a runner busy with a step does not honour a kill request.
+func (r *runnerRegistry) killAll() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, rr := range r.runners {
+		if !rr.Alive() {
+			continue
+		}
+		if p, err := os.FindProcess(rr.Pid()); err == nil {
+			if err := p.Kill(); err != nil {
+				logger.Debugf(true, "Failed to kill runner with PID:%d. %s", rr.Pid(), err.Error())
+			}
+		}
+		rr.Info().Killed = true
+	}
+}
+
+// handleInterrupts stops the execution gracefully on SIGINT or SIGTERM. No new spec or scenario is started and the steps
+// in progress are allowed to complete, so that the after hooks are run and the partial results are reported.
+// The runners are killed if the steps do not complete within InterruptGracePeriod, a second signal stops gauge immediately.
+// Note that a signal sent from the terminal also reaches the runner, as it runs in the same process group.
+// The returned function stops handling the signals.
+func handleInterrupts() func() {
+	atomic.StoreInt32(&interrupted, 0)
+	interruptListeners.Lock()
+	interruptListeners.listeners = nil
+	interruptListeners.Unlock()
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			return
+		case s := <-signals:
+			atomic.StoreInt32(&interrupted, 1)
+			logger.Warningf(true, "Received %s. Waiting for the steps in progress to complete, the remaining scenarios will be skipped. Send the signal again to stop immediately.", s)
+			notifyInterrupted()
+		}
+		select {
+		case <-done:
+		case <-signals:
+			logger.Errorf(true, "Stopping the execution immediately.")
+			runners.killAll()
+			os.Exit(ExecutionFailed)
+		case <-time.After(InterruptGracePeriod):
+			logger.Warningf(true, "The steps in progress did not complete in %s, killing the runners.", InterruptGracePeriod)
+			runners.killAll()
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// executionStopped tells if new specs and scenarios should not be started, either because the execution
+// was interrupted or the failure limit was reached.
+func executionStopped() bool {
+	return isInterrupted() || failures.limitReached()
+}
+
+func stopReason() string {
+	if isInterrupted() {
+		return interruptedReason
+	}
+	return failureLimitReason()
+}
+
+func skipStoppedScenario(scenarioResult *result.ScenarioResult) {
+	scenarioResult.ProtoScenario.ExecutionStatus = gauge_messages.ExecutionStatus_SKIPPED
+	scenarioResult.ProtoScenario.Skipped = true
+	scenarioResult.ProtoScenario.SkipErrors = []string{stopReason()}
+}
+
+// markInterrupted fails the suite if the execution was interrupted, as not all the specs were executed.
+func markInterrupted(suiteResult *result.SuiteResult) {
+	if !isInterrupted() {
+		return
+	}
+	suiteResult.Interrupted = true
+	suiteResult.IsFailed = true
+}
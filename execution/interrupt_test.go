@@ -0,0 +1,101 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+)
+
+func interrupt() func() {
+	atomic.StoreInt32(&interrupted, 1)
+	return func() { atomic.StoreInt32(&interrupted, 0) }
+}
+
+func TestScenariosAreSkippedOnceInterrupted(t *testing.T) {
+	defer interrupt()()
+	messages := make(map[gauge_messages.Message_MessageType]int)
+	h := &mockPluginHandler{NotifyPluginsfunc: func(m *gauge_messages.Message) {}, GracefullyKillPluginsfunc: func() {}}
+	se := newSpecExecutor(exampleSpecWithScenarios, newFailingScenarioRunner(messages), h, gauge.NewBuildErrors(), 0)
+
+	res := se.execute(true, true, true)
+
+	if messages[gauge_messages.Message_ScenarioExecutionStarting] != 0 {
+		t.Errorf("Expected no scenario to be executed, got %d", messages[gauge_messages.Message_ScenarioExecutionStarting])
+	}
+	if res.ScenarioSkippedCount != 2 {
+		t.Errorf("Expected both the scenarios to be skipped, got %d", res.ScenarioSkippedCount)
+	}
+	if got := res.ProtoSpec.Items[0].Scenario.SkipErrors; len(got) != 1 || got[0] != interruptedReason {
+		t.Errorf("Expected the scenario to be skipped as the execution was interrupted, got %v", got)
+	}
+}
+
+func TestRemainingStepsAreSkippedOnceInterrupted(t *testing.T) {
+	defer interrupt()()
+	sce := newScenarioExecutor(&mockRunner{}, nil, &gauge_messages.ExecutionInfo{}, nil, nil, nil, 0)
+	scenarioResult := result.NewScenarioResult(gauge.NewProtoScenario(&gauge.Scenario{Heading: &gauge.Heading{Value: "A scenario"}, Span: &gauge.Span{}}))
+	steps := []*gauge.Step{{Value: "a step"}}
+	items := []*gauge_messages.ProtoItem{{ItemType: gauge_messages.ProtoItem_Step, Step: &gauge_messages.ProtoStep{ActualText: "a step"}}}
+
+	if sce.executeSteps(steps, items, scenarioResult, true) {
+		t.Error("Expected the remaining steps not to be executed")
+	}
+	if items[0].Step.StepExecutionResult != nil {
+		t.Error("Expected the step not to be executed")
+	}
+	if !scenarioResult.ProtoScenario.Skipped || scenarioResult.ProtoScenario.SkipErrors[0] != interruptedReason {
+		t.Errorf("Expected the scenario to be skipped as the execution was interrupted, got %v", scenarioResult.ProtoScenario.SkipErrors)
+	}
+}
+
+func TestFailedScenarioIsNotSkippedOnceInterrupted(t *testing.T) {
+	defer interrupt()()
+	sce := newScenarioExecutor(&mockRunner{}, nil, &gauge_messages.ExecutionInfo{}, nil, nil, nil, 0)
+	scenarioResult := result.NewScenarioResult(gauge.NewProtoScenario(&gauge.Scenario{Heading: &gauge.Heading{Value: "A scenario"}, Span: &gauge.Span{}}))
+	scenarioResult.SetFailure()
+	items := []*gauge_messages.ProtoItem{{ItemType: gauge_messages.ProtoItem_Step, Step: &gauge_messages.ProtoStep{ActualText: "a step"}}}
+
+	sce.executeSteps([]*gauge.Step{{Value: "a step"}}, items, scenarioResult, true)
+
+	if scenarioResult.ProtoScenario.Skipped || !scenarioResult.GetFailed() {
+		t.Error("Expected the scenario to remain failed")
+	}
+}
+
+func TestInterruptedSuiteIsFailed(t *testing.T) {
+	res := result.NewSuiteResult("", time.Now())
+	markInterrupted(res)
+	if res.Interrupted || res.IsFailed {
+		t.Error("Expected the suite not to be marked as interrupted")
+	}
+
+	defer interrupt()()
+	markInterrupted(res)
+	merged := mergeDataTableSpecResults(res)
+
+	if !merged.Interrupted || !merged.IsFailed {
+		t.Errorf("Expected the suite to be interrupted and failed, got %t and %t", merged.Interrupted, merged.IsFailed)
+	}
+}
+
+func TestCrashedRunnerIsNotRestartedOnceInterrupted(t *testing.T) {
+	defer interrupt()()
+	r := &crashingMockRunner{mockRunner: &mockRunner{}}
+
+	if recoverRunner(r) {
+		t.Error("Expected the runner not to be recovered")
+	}
+	if r.recoveries != 0 {
+		t.Errorf("Expected no restart to be attempted, got %d", r.recoveries)
+	}
+}
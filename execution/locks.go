@@ -0,0 +1,112 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"sync"
+
+	"github.com/getgauge/gauge/filter"
+	"github.com/getgauge/gauge/gauge"
+)
+
+type specQueue interface {
+	HasNext() bool
+	Next() []*gauge.Specification
+	Remaining() int
+}
+
+// lockingQueue hands out the specs to the streams of a parallel execution in order, skipping the specs holding
+// a lock which is held by a spec in progress in another stream. The locks are held till the stream releases them.
+type lockingQueue struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	groups [][]*gauge.Specification
+	locks  [][]string
+	held   map[string]bool
+}
+
+func newLockingQueue(sc *gauge.SpecCollection) *lockingQueue {
+	q := &lockingQueue{held: make(map[string]bool)}
+	q.cond = sync.NewCond(&q.mutex)
+	for sc.HasNext() {
+		specs := sc.Next()
+		var locks []string
+		for _, spec := range specs {
+			locks = append(locks, filter.Locks(spec)...)
+		}
+		q.groups = append(q.groups, specs)
+		q.locks = append(q.locks, locks)
+	}
+	return q
+}
+
+func (q *lockingQueue) HasNext() bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.groups) > 0
+}
+
+// Remaining returns the number of spec groups yet to be handed out.
+func (q *lockingQueue) Remaining() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return len(q.groups)
+}
+
+// Next returns the first specs whose locks are free, waiting for the other streams to release them if required.
+// The locks are acquired on behalf of the caller. It returns nil if there are no specs left.
+func (q *lockingQueue) Next() []*gauge.Specification {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for len(q.groups) > 0 {
+		for i, locks := range q.locks {
+			if q.anyHeld(locks) {
+				continue
+			}
+			specs := q.groups[i]
+			for _, l := range locks {
+				q.held[l] = true
+			}
+			q.groups = append(q.groups[:i], q.groups[i+1:]...)
+			q.locks = append(q.locks[:i], q.locks[i+1:]...)
+			return specs
+		}
+		q.cond.Wait()
+	}
+	return nil
+}
+
+// release frees the locks held by the specs returned by Next.
+func (q *lockingQueue) release(specs []*gauge.Specification) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for _, spec := range specs {
+		for _, l := range filter.Locks(spec) {
+			delete(q.held, l)
+		}
+	}
+	q.cond.Broadcast()
+}
+
+// holdsLocks returns true if any of the specs holds a lock.
+func holdsLocks(specs []*gauge.Specification) bool {
+	for _, spec := range specs {
+		if len(filter.Locks(spec)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *lockingQueue) anyHeld(locks []string) bool {
+	for _, l := range locks {
+		if q.held[l] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getgauge/gauge/gauge"
+)
+
+func lockedSpec(file string, locks ...string) *gauge.Specification {
+	spec := &gauge.Specification{FileName: file, Tags: &gauge.Tags{}}
+	for _, l := range locks {
+		spec.Tags.Add([]string{"lock:" + l})
+	}
+	return spec
+}
+
+func TestLockingQueueSkipsSpecsWithHeldLocks(t *testing.T) {
+	a, b, c := lockedSpec("a", "db"), lockedSpec("b", "db"), lockedSpec("c")
+	q := newLockingQueue(gauge.NewSpecCollection([]*gauge.Specification{a, b, c}, false))
+
+	if got := q.Next(); got[0] != a {
+		t.Fatalf("expected spec a, got %s", got[0].FileName)
+	}
+	if got := q.Next(); got[0] != c {
+		t.Fatalf("expected spec c as b waits for the lock held by a, got %s", got[0].FileName)
+	}
+	q.release([]*gauge.Specification{a})
+	if got := q.Next(); got[0] != b {
+		t.Fatalf("expected spec b once a released the lock, got %s", got[0].FileName)
+	}
+	if q.HasNext() {
+		t.Error("expected no specs to be left")
+	}
+}
+
+func TestLockingQueueWaitsForTheLockToBeReleased(t *testing.T) {
+	a, b := lockedSpec("a", "db"), lockedSpec("b", "db")
+	q := newLockingQueue(gauge.NewSpecCollection([]*gauge.Specification{a, b}, false))
+	q.Next()
+
+	next := make(chan []*gauge.Specification)
+	go func() { next <- q.Next() }()
+	select {
+	case <-next:
+		t.Fatal("expected the spec holding a lock to wait till the lock is released")
+	case <-time.After(50 * time.Millisecond):
+	}
+	q.release([]*gauge.Specification{a})
+	select {
+	case got := <-next:
+		if got[0] != b {
+			t.Errorf("expected spec b, got %s", got[0].FileName)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the spec to be returned once the lock is released")
+	}
+}
+
+func TestLockingQueueReturnsNilIfTheSpecsWereTakenWhileWaiting(t *testing.T) {
+	a, b := lockedSpec("a", "db"), lockedSpec("b", "db")
+	q := newLockingQueue(gauge.NewSpecCollection([]*gauge.Specification{a, b}, false))
+	q.Next()
+
+	waiting := make(chan []*gauge.Specification, 2)
+	go func() { waiting <- q.Next() }()
+	go func() { waiting <- q.Next() }()
+	time.Sleep(50 * time.Millisecond)
+	q.release([]*gauge.Specification{a})
+
+	first, second := <-waiting, <-waiting
+	if first == nil {
+		first, second = second, first
+	}
+	if len(first) != 1 || first[0] != b || second != nil {
+		t.Errorf("expected one stream to get spec b and the other nil, got %v and %v", first, second)
+	}
+}
@@ -18,6 +18,7 @@ import (
 func mergeDataTableSpecResults(sResult *result.SuiteResult) *result.SuiteResult {
 	suiteRes := result.NewSuiteResult(sResult.Tags, time.Now())
 	suiteRes.IsFailed = sResult.IsFailed
+	suiteRes.Interrupted = sResult.Interrupted
 	suiteRes.ExecutionTime = sResult.ExecutionTime
 	suiteRes.PostSuite = sResult.PostSuite
 	suiteRes.PreSuite = sResult.PreSuite
@@ -114,6 +115,9 @@ func mergeResults(results []*result.SpecResult) *result.SpecResult {
 		specResult.ExecutionTime = max
 	}
 	aggregateDataTableScnStats(dataTableScnResults, specResult)
+	if specResult.ScenarioCount > 0 && specResult.ScenarioSkippedCount == specResult.ScenarioCount {
+		specResult.Skipped = true
+	}
 	specResult.ProtoSpec.FileName = results[0].ProtoSpec.FileName
 	specResult.ProtoSpec.Tags = results[0].ProtoSpec.Tags
 	specResult.ProtoSpec.SpecHeading = results[0].ProtoSpec.SpecHeading
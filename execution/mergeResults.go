@@ -0,0 +1,149 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/report"
+	"github.com/getgauge/gauge/execution/rerun"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/manifest"
+	"github.com/getgauge/gauge/plugin"
+	"github.com/getgauge/gauge/util"
+	"google.golang.org/protobuf/proto"
+)
+
+// MergeResults merges the suite results saved by the groups of a sharded execution, e.g. the .gauge/last_run_result
+// of each machine running `gauge run -n 8 -g <group>`. The merged result is sent to the reporting plugins and saved as
+// the last run result, along with the failures to execute with --failed. It returns the exit code of the merged execution.
+func MergeResults(files []string) int {
+	var results []*result.SuiteResult
+	for _, f := range files {
+		r, err := readSuiteResult(f)
+		if err != nil {
+			logger.Errorf(true, "Failed to read the suite result in %s. %s", f, err.Error())
+			return ExecutionFailed
+		}
+		results = append(results, r)
+	}
+	res := mergeSuiteResults(results)
+	protoRes := gauge.ConvertToProtoSuiteResult(res)
+	writeResult(res)
+	rerun.SaveFailures(protoRes, util.GetSpecDirs())
+	if formats := report.ConfiguredFormats(); len(formats) > 0 {
+		report.Write(protoRes, formats)
+	}
+	notifyReportingPlugins(protoRes)
+	return printExecutionResult(res, true)
+}
+
+func readSuiteResult(file string) (*result.SuiteResult, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	res := &gauge_messages.ProtoSuiteResult{}
+	if err := proto.Unmarshal(b, res); err != nil {
+		return nil, err
+	}
+	inProject(res)
+	return suiteResultFromProto(res), nil
+}
+
+// inProject rewrites the spec paths of a group's result, which may have been executed in another checkout of the project,
+// to the paths of the specs in this project, so that the results of a spec from different groups are merged and its
+// failures can be executed with --failed.
+func inProject(res *gauge_messages.ProtoSuiteResult) {
+	specPath := result.SpecPaths(res)
+	for _, specRes := range res.GetSpecResults() {
+		spec := specRes.GetProtoSpec()
+		if spec == nil {
+			continue
+		}
+		if p := filepath.FromSlash(specPath(spec.GetFileName())); !filepath.IsAbs(p) {
+			spec.FileName = filepath.Join(config.ProjectRoot, p)
+		}
+	}
+}
+
+func suiteResultFromProto(r *gauge_messages.ProtoSuiteResult) *result.SuiteResult {
+	res := &result.SuiteResult{
+		PreSuite:                r.GetPreHookFailure(),
+		PostSuite:               r.GetPostHookFailure(),
+		IsFailed:                r.GetFailed(),
+		SpecsFailedCount:        int(r.GetSpecsFailedCount()),
+		ExecutionTime:           r.GetExecutionTime(),
+		Environment:             r.GetEnvironment(),
+		Tags:                    r.GetTags(),
+		ProjectName:             r.GetProjectName(),
+		Timestamp:               r.GetTimestamp(),
+		SpecsSkippedCount:       int(r.GetSpecsSkippedCount()),
+		PreHookMessages:         r.GetPreHookMessages(),
+		PostHookMessages:        r.GetPostHookMessages(),
+		PreHookScreenshotFiles:  r.GetPreHookScreenshotFiles(),
+		PostHookScreenshotFiles: r.GetPostHookScreenshotFiles(),
+		PreHookScreenshots:      r.GetPreHookScreenshots(),
+		PostHookScreenshots:     r.GetPostHookScreenshots(),
+	}
+	for _, specRes := range r.GetSpecResults() {
+		res.SpecResults = append(res.SpecResults, specResultFromProto(specRes))
+	}
+	return res
+}
+
+// mergeSuiteResults combines the suite results as the results of the streams of a parallel execution, merging the rows of
+// the data table driven specs executed by different groups. The groups run side by side, so the execution takes as long as the
+// slowest of them and starts with the earliest.
+func mergeSuiteResults(results []*result.SuiteResult) *result.SuiteResult {
+	first := results[0]
+	r := &result.SuiteResult{Environment: first.Environment, Tags: first.Tags, ProjectName: first.ProjectName, Timestamp: first.Timestamp}
+	start, startErr := time.Parse(config.LayoutForTimeStamp, first.Timestamp)
+	for _, res := range results {
+		if res.ExecutionTime > r.ExecutionTime {
+			r.ExecutionTime = res.ExecutionTime
+		}
+		if t, err := time.Parse(config.LayoutForTimeStamp, res.Timestamp); err == nil && (startErr != nil || t.Before(start)) {
+			start, startErr, r.Timestamp = t, nil, res.Timestamp
+		}
+		r.PreHookMessages = append(r.PreHookMessages, res.PreHookMessages...)
+		r.PostHookMessages = append(r.PostHookMessages, res.PostHookMessages...)
+		r.PreHookScreenshotFiles = append(r.PreHookScreenshotFiles, res.PreHookScreenshotFiles...)
+		r.PostHookScreenshotFiles = append(r.PostHookScreenshotFiles, res.PostHookScreenshotFiles...)
+		r.PreHookScreenshots = append(r.PreHookScreenshots, res.PreHookScreenshots...)
+		r.PostHookScreenshots = append(r.PostHookScreenshots, res.PostHookScreenshots...)
+	}
+	r = mergeDataTableSpecResults(aggregateSuiteResults(r, results))
+	sort.SliceStable(r.SpecResults, func(i, j int) bool {
+		return r.SpecResults[i].ProtoSpec.GetFileName() < r.SpecResults[j].ProtoSpec.GetFileName()
+	})
+	if r.SpecsFailedCount > 0 || r.PreSuite != nil || r.PostSuite != nil {
+		r.IsFailed = true
+	}
+	return r
+}
+
+func notifyReportingPlugins(res *gauge_messages.ProtoSuiteResult) {
+	m, err := manifest.ProjectManifest()
+	if err != nil {
+		logger.Errorf(true, "Unable to send the merged result to the plugins. %s", err.Error())
+		return
+	}
+	ph := plugin.StartPlugins(m)
+	ph.NotifyPlugins(&gauge_messages.Message{MessageType: gauge_messages.Message_SuiteExecutionResult,
+		SuiteExecutionResult: &gauge_messages.SuiteExecutionResult{SuiteResult: res}})
+	ph.NotifyPlugins(&gauge_messages.Message{MessageType: gauge_messages.Message_KillProcessRequest,
+		KillProcessRequest: &gauge_messages.KillProcessRequest{}})
+	ph.GracefullyKillPlugins()
+}
@@ -0,0 +1,155 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"google.golang.org/protobuf/proto"
+)
+
+func tableDrivenSpecResult(row int32, status gm.ExecutionStatus) *result.SpecResult {
+	return &result.SpecResult{
+		ProtoSpec: &gm.ProtoSpec{
+			SpecHeading: "Vowels", FileName: "specs/vowels.spec", IsTableDriven: true,
+			Items: []*gm.ProtoItem{
+				{ItemType: gm.ProtoItem_Table, Table: &gm.ProtoTable{Headers: &gm.ProtoTableRow{Cells: []string{"word"}}, Rows: []*gm.ProtoTableRow{{Cells: []string{"gauge"}}, {Cells: []string{"mingle"}}}}},
+				{ItemType: gm.ProtoItem_TableDrivenScenario, TableDrivenScenario: &gm.ProtoTableDrivenScenario{
+					Scenario:      &gm.ProtoScenario{ScenarioHeading: "Vowel counts", ExecutionStatus: status},
+					TableRowIndex: row, IsSpecTableDriven: true,
+				}},
+			},
+		},
+		ScenarioCount:       1,
+		ScenarioFailedCount: map[bool]int{true: 1}[status == gm.ExecutionStatus_FAILED],
+		IsFailed:            status == gm.ExecutionStatus_FAILED,
+		ExecutionTime:       10,
+	}
+}
+
+func TestMergeSuiteResultsOfGroups(t *testing.T) {
+	login := &result.SpecResult{ProtoSpec: &gm.ProtoSpec{SpecHeading: "Login", FileName: "specs/login.spec"}, ScenarioCount: 2, ExecutionTime: 40}
+	group1 := &result.SuiteResult{
+		SpecResults:   []*result.SpecResult{tableDrivenSpecResult(0, gm.ExecutionStatus_PASSED), login},
+		ExecutionTime: 300,
+		Timestamp:     "Oct 17, 2026 at 10:05am",
+		Tags:          "!wip",
+	}
+	group2 := &result.SuiteResult{
+		SpecResults:      []*result.SpecResult{tableDrivenSpecResult(1, gm.ExecutionStatus_FAILED)},
+		IsFailed:         true,
+		SpecsFailedCount: 1,
+		ExecutionTime:    500,
+		Timestamp:        "Oct 17, 2026 at 10:01am",
+		Tags:             "!wip",
+	}
+
+	got := mergeSuiteResults([]*result.SuiteResult{group1, group2})
+
+	if len(got.SpecResults) != 2 {
+		t.Fatalf("expected the rows of the table driven spec to be merged into one result, got %d spec results", len(got.SpecResults))
+	}
+	if got.SpecResults[0].ProtoSpec.GetFileName() != "specs/login.spec" {
+		t.Errorf("expected the spec results to be ordered by file, got %s first", got.SpecResults[0].ProtoSpec.GetFileName())
+	}
+	if vowels := got.SpecResults[1]; vowels.ScenarioCount != 2 || vowels.ScenarioFailedCount != 1 {
+		t.Errorf("expected the merged spec to have 2 scenarios with 1 failure, got %d and %d", vowels.ScenarioCount, vowels.ScenarioFailedCount)
+	}
+	if !got.IsFailed || got.SpecsFailedCount != 1 {
+		t.Errorf("expected the merged result to have failed with 1 failed spec, got %v and %d", got.IsFailed, got.SpecsFailedCount)
+	}
+	if got.ExecutionTime != 500 {
+		t.Errorf("expected the execution time of the slowest group, got %d", got.ExecutionTime)
+	}
+	if got.Timestamp != "Oct 17, 2026 at 10:01am" || got.Tags != "!wip" {
+		t.Errorf("expected the start of the earliest group and its tags, got %q and %q", got.Timestamp, got.Tags)
+	}
+}
+
+func TestMergeSuiteResultsFailsIfASuiteHookFailed(t *testing.T) {
+	hook := &gm.ProtoHookFailure{ErrorMessage: "db is down"}
+	got := mergeSuiteResults([]*result.SuiteResult{{}, {PostSuite: hook}})
+
+	if !got.IsFailed || got.PostSuite != hook {
+		t.Errorf("expected the merged result to fail with the after suite hook failure, got %v and %v", got.IsFailed, got.PostSuite)
+	}
+}
+
+func TestMergeResultsOfGroupsInDifferentWorkspaces(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gauge-merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var results []*result.SuiteResult
+	for i, root := range []string{"/ci/workspace-1/shop", "/ci/workspace-2/shop"} {
+		specRes := tableDrivenSpecResult(int32(i), gm.ExecutionStatus_PASSED)
+		specRes.ProtoSpec.FileName = root + "/specs/vowels.spec"
+		res := gauge.ConvertToProtoSuiteResult(&result.SuiteResult{SpecResults: []*result.SpecResult{specRes}})
+		res.ProjectName = "shop"
+		file := filepath.Join(dir, "last_run_result")
+		b, _ := proto.Marshal(res)
+		if err := ioutil.WriteFile(file, b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		r, err := readSuiteResult(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+
+	got := mergeSuiteResults(results)
+
+	if len(got.SpecResults) != 1 || got.SpecResults[0].ScenarioCount != 2 {
+		t.Fatalf("expected the rows of the spec executed in both workspaces to be merged into one result, got %d spec results", len(got.SpecResults))
+	}
+	if want := filepath.Join(config.ProjectRoot, "specs", "vowels.spec"); got.SpecResults[0].ProtoSpec.GetFileName() != want {
+		t.Errorf("expected the spec to be in this project at %s, got %s", want, got.SpecResults[0].ProtoSpec.GetFileName())
+	}
+}
+
+func TestReadSuiteResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gauge-merge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "last_run_result")
+	saved := &result.SuiteResult{
+		SpecResults:      []*result.SpecResult{tableDrivenSpecResult(1, gm.ExecutionStatus_FAILED)},
+		IsFailed:         true,
+		SpecsFailedCount: 1,
+		ExecutionTime:    500,
+	}
+	b, _ := proto.Marshal(gauge.ConvertToProtoSuiteResult(saved))
+	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readSuiteResult(file)
+
+	if err != nil {
+		t.Fatalf("expected the suite result to be read, got %s", err.Error())
+	}
+	if !got.IsFailed || got.SpecsFailedCount != 1 || got.ExecutionTime != 500 || len(got.SpecResults) != 1 {
+		t.Errorf("expected the saved suite result, got %+v", got)
+	}
+	if specRes := got.SpecResults[0]; specRes.ScenarioFailedCount != 1 || !specRes.GetFailed() {
+		t.Errorf("expected the saved spec result, got %+v", specRes)
+	}
+	if _, err := readSuiteResult(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected an error for a missing result file")
+	}
+}
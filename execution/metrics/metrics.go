@@ -0,0 +1,225 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+// Package metrics serves the progress of an execution in the Prometheus text format while it is running. The scenario,
+// retry and step metrics are fed by the execution events, while the executors report the queue depth of each stream
+// and the runner restarts.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/logger"
+)
+
+const (
+	metricsPath = "/metrics"
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+	passed      = "passed"
+	failed      = "failed"
+	skipped     = "skipped"
+)
+
+// stepDurationBuckets are the upper bounds of the step duration histogram, in seconds
+var stepDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+type histogram struct {
+	counts []int
+	sum    float64
+	count  int
+}
+
+func (h *histogram) observe(v float64) {
+	for i, b := range stepDurationBuckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+type registry struct {
+	mutex        sync.Mutex
+	scenarios    map[string]int
+	planned      int
+	retries      int
+	stepDuration *histogram
+	queueDepth   map[int]int
+	restarts     map[int]int
+	started      time.Time
+	inProgress   bool
+}
+
+func newRegistry() *registry {
+	return &registry{
+		scenarios:    map[string]int{passed: 0, failed: 0, skipped: 0},
+		stepDuration: &histogram{counts: make([]int, len(stepDurationBuckets))},
+		queueDepth:   make(map[int]int),
+		restarts:     make(map[int]int),
+	}
+}
+
+var current = newRegistry()
+
+// Serve serves the metrics on the given address, till gauge exits.
+func Serve(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(metricsPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		current.write(w)
+	})
+	logger.Infof(true, "Serving the execution metrics at http://%s%s", l.Addr().String(), metricsPath)
+	go func() {
+		if err := http.Serve(l, mux); err != nil {
+			logger.Errorf(false, "Stopped serving the execution metrics. %s", err.Error())
+		}
+	}()
+	return nil
+}
+
+// ListenExecutionEvents updates the metrics as the specs, scenarios and steps are executed.
+func ListenExecutionEvents() {
+	ch := make(chan event.ExecutionEvent)
+	event.Register(ch, event.SuiteStart, event.StepEnd, event.ScenarioEnd, event.SuiteEnd)
+	go func() {
+		for {
+			e := <-ch
+			current.handle(e, time.Now())
+			if e.Topic == event.SuiteEnd {
+				return
+			}
+		}
+	}()
+}
+
+// SetPlanned sets the number of scenarios to execute, which along with the scenarios executed gives the progress.
+func SetPlanned(scenarios int) {
+	current.mutex.Lock()
+	defer current.mutex.Unlock()
+	current.planned = scenarios
+}
+
+// SetQueueDepth sets the number of specs waiting to be executed by the stream. The streams share the queue
+// when the specs are distributed lazily.
+func SetQueueDepth(stream, specs int) {
+	current.mutex.Lock()
+	defer current.mutex.Unlock()
+	current.queueDepth[stream] = specs
+}
+
+// RunnerRestarted counts a restart of the runner of the stream, after it crashed or a step timed out.
+func RunnerRestarted(stream int) {
+	current.mutex.Lock()
+	defer current.mutex.Unlock()
+	current.restarts[stream]++
+}
+
+func (r *registry) handle(e event.ExecutionEvent, now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	switch e.Topic {
+	case event.SuiteStart:
+		// the metrics of a previous execution in watch mode are not carried over
+		r.scenarios = map[string]int{passed: 0, failed: 0, skipped: 0}
+		r.retries = 0
+		r.stepDuration = &histogram{counts: make([]int, len(stepDurationBuckets))}
+		r.started, r.inProgress = now, true
+	case event.StepEnd:
+		if res, ok := e.Result.(*result.StepResult); ok {
+			ms := res.ProtoStep.GetStepExecutionResult().GetExecutionResult().GetExecutionTime()
+			r.stepDuration.observe(float64(ms) / 1000)
+		}
+	case event.ScenarioEnd:
+		res, ok := e.Result.(*result.ScenarioResult)
+		if !ok {
+			return
+		}
+		// a scenario is retried only after a failed attempt, which is replaced by the new attempt
+		if res.ProtoScenario.GetRetriesCount() > 1 {
+			r.retries++
+			r.scenarios[failed]--
+		}
+		switch res.ProtoScenario.GetExecutionStatus() {
+		case gauge_messages.ExecutionStatus_PASSED:
+			r.scenarios[passed]++
+		case gauge_messages.ExecutionStatus_FAILED:
+			r.scenarios[failed]++
+		default:
+			r.scenarios[skipped]++
+		}
+	case event.SuiteEnd:
+		r.inProgress = false
+		for stream := range r.queueDepth {
+			r.queueDepth[stream] = 0
+		}
+	}
+}
+
+func (r *registry) write(w io.Writer) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	header(w, "gauge_scenarios", "gauge", "Scenarios executed by status. A retried scenario is counted once, with the status of its last attempt.")
+	for _, s := range []string{passed, failed, skipped} {
+		fmt.Fprintf(w, "gauge_scenarios{status=%q} %d\n", s, r.scenarios[s])
+	}
+	header(w, "gauge_scenarios_planned", "gauge", "Scenarios to be executed.")
+	fmt.Fprintf(w, "gauge_scenarios_planned %d\n", r.planned)
+	header(w, "gauge_scenario_retries_total", "counter", "Attempts to execute a failed scenario again.")
+	fmt.Fprintf(w, "gauge_scenario_retries_total %d\n", r.retries)
+	header(w, "gauge_step_duration_seconds", "histogram", "Duration of the steps executed.")
+	for i, b := range stepDurationBuckets {
+		fmt.Fprintf(w, "gauge_step_duration_seconds_bucket{le=%q} %d\n", formatFloat(b), r.stepDuration.counts[i])
+	}
+	fmt.Fprintf(w, "gauge_step_duration_seconds_bucket{le=\"+Inf\"} %d\n", r.stepDuration.count)
+	fmt.Fprintf(w, "gauge_step_duration_seconds_sum %s\n", formatFloat(r.stepDuration.sum))
+	fmt.Fprintf(w, "gauge_step_duration_seconds_count %d\n", r.stepDuration.count)
+	header(w, "gauge_stream_queue_depth", "gauge", "Specs waiting to be executed by each stream.")
+	for _, s := range sortedStreams(r.queueDepth) {
+		fmt.Fprintf(w, "gauge_stream_queue_depth{stream=\"%d\"} %d\n", s, r.queueDepth[s])
+	}
+	header(w, "gauge_runner_restarts_total", "counter", "Restarts of the runner of each stream.")
+	for _, s := range sortedStreams(r.restarts) {
+		fmt.Fprintf(w, "gauge_runner_restarts_total{stream=\"%d\"} %d\n", s, r.restarts[s])
+	}
+	header(w, "gauge_execution_in_progress", "gauge", "Whether the execution is in progress.")
+	fmt.Fprintf(w, "gauge_execution_in_progress %d\n", map[bool]int{false: 0, true: 1}[r.inProgress])
+	if !r.started.IsZero() {
+		header(w, "gauge_execution_start_time_seconds", "gauge", "Start time of the execution since the unix epoch.")
+		fmt.Fprintf(w, "gauge_execution_start_time_seconds %s\n", formatFloat(float64(r.started.UnixNano())/1e9))
+	}
+}
+
+func header(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedStreams(m map[int]int) []int {
+	var streams []int
+	for s := range m {
+		streams = append(streams, s)
+	}
+	sort.Ints(streams)
+	return streams
+}
@@ -0,0 +1,120 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+)
+
+func scenarioEnd(status gauge_messages.ExecutionStatus, retriesCount int64) event.ExecutionEvent {
+	res := result.NewScenarioResult(&gauge_messages.ProtoScenario{ExecutionStatus: status, RetriesCount: retriesCount})
+	return event.NewExecutionEvent(event.ScenarioEnd, &gauge.Scenario{}, res, 0, &gauge_messages.ExecutionInfo{})
+}
+
+func stepEnd(ms int64) event.ExecutionEvent {
+	res := result.NewStepResult(&gauge_messages.ProtoStep{StepExecutionResult: &gauge_messages.ProtoStepExecutionResult{
+		ExecutionResult: &gauge_messages.ProtoExecutionResult{ExecutionTime: ms},
+	}})
+	return event.NewExecutionEvent(event.StepEnd, gauge.Step{}, res, 0, &gauge_messages.ExecutionInfo{})
+}
+
+func contains(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, l := range lines {
+		if !strings.Contains(out, l+"\n") {
+			t.Errorf("expected the metrics to contain %q, got:\n%s", l, out)
+		}
+	}
+}
+
+func TestScenariosAreCountedByTheStatusOfTheirLastAttempt(t *testing.T) {
+	r := newRegistry()
+	r.handle(event.NewExecutionEvent(event.SuiteStart, nil, nil, 0, &gauge_messages.ExecutionInfo{}), time.Unix(100, 0))
+	r.handle(scenarioEnd(gauge_messages.ExecutionStatus_PASSED, 1), time.Now())
+	r.handle(scenarioEnd(gauge_messages.ExecutionStatus_FAILED, 1), time.Now())
+	r.handle(scenarioEnd(gauge_messages.ExecutionStatus_PASSED, 2), time.Now())
+	r.handle(scenarioEnd(gauge_messages.ExecutionStatus_SKIPPED, 0), time.Now())
+
+	var out bytes.Buffer
+	r.write(&out)
+
+	contains(t, out.String(),
+		`gauge_scenarios{status="passed"} 2`,
+		`gauge_scenarios{status="failed"} 0`,
+		`gauge_scenarios{status="skipped"} 1`,
+		"gauge_scenario_retries_total 1",
+		"gauge_execution_in_progress 1",
+		"gauge_execution_start_time_seconds 100",
+	)
+}
+
+func TestStepDurationsAreObservedInTheHistogram(t *testing.T) {
+	r := newRegistry()
+	r.handle(stepEnd(20), time.Now())
+	r.handle(stepEnd(3000), time.Now())
+
+	var out bytes.Buffer
+	r.write(&out)
+
+	contains(t, out.String(),
+		`gauge_step_duration_seconds_bucket{le="0.01"} 0`,
+		`gauge_step_duration_seconds_bucket{le="0.025"} 1`,
+		`gauge_step_duration_seconds_bucket{le="2.5"} 1`,
+		`gauge_step_duration_seconds_bucket{le="5"} 2`,
+		`gauge_step_duration_seconds_bucket{le="+Inf"} 2`,
+		"gauge_step_duration_seconds_sum 3.02",
+		"gauge_step_duration_seconds_count 2",
+	)
+}
+
+func TestServeExposesTheMetricsOfEachStream(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	current = newRegistry()
+	defer func() { current = newRegistry() }()
+
+	if err := Serve(addr); err != nil {
+		t.Fatalf("expected the metrics to be served, got %s", err.Error())
+	}
+	SetPlanned(5)
+	SetQueueDepth(1, 3)
+	SetQueueDepth(2, 0)
+	RunnerRestarted(2)
+
+	res, err := http.Get("http://" + addr + metricsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+
+	if got := res.Header.Get("Content-Type"); got != contentType {
+		t.Errorf("expected the content type %q, got %q", contentType, got)
+	}
+	contains(t, string(body),
+		"gauge_scenarios_planned 5",
+		`gauge_stream_queue_depth{stream="1"} 3`,
+		`gauge_stream_queue_depth{stream="2"} 0`,
+		`gauge_runner_restarts_total{stream="2"} 1`,
+		"gauge_execution_in_progress 0",
+	)
+}
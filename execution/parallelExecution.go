@@ -48,6 +48,7 @@ type parallelExecution struct {
 	wg                       sync.WaitGroup
 	manifest                 *manifest.Manifest
 	specCollection           *gauge.SpecCollection
+	queue                    *lockingQueue
 	pluginHandler            plugin.Handler
 	runners                  []runner.Runner
 	suiteResult              *result.SuiteResult
@@ -95,26 +96,44 @@ func (e *parallelExecution) numberOfStreams() int {
 
 func (e *parallelExecution) start() {
 	e.startTime = time.Now()
+	failures.reset()
 	event.Notify(event.NewExecutionEvent(event.SuiteStart, nil, nil, 0, &gauge_messages.ExecutionInfo{}))
 	e.pluginHandler = plugin.StartPlugins(e.manifest)
 }
 
+// startRunnersForRemainingStreams starts a runner for each stream, the runner started to validate the specs is used
+// by the first stream. It is replaced if the first stream has properties of its own, as it was started without them.
 func (e *parallelExecution) startRunnersForRemainingStreams() {
 	totalStreams := e.numberOfStreams()
-	rChan := make(chan runner.Runner, totalStreams-1)
-	for i := 2; i <= totalStreams; i++ {
+	first := 2
+	if vars, err := env.StreamEnv(1); err != nil || len(vars) > 0 {
+		killRunner(e.runners[0])
+		first = 1
+	} else {
+		e.runners[0] = newRestartableRunner(e.runners[0], 1)
+	}
+	runners := make([]runner.Runner, totalStreams)
+	copy(runners, e.runners)
+	type started struct {
+		stream int
+		runner runner.Runner
+	}
+	rChan := make(chan started, totalStreams)
+	for i := first; i <= totalStreams; i++ {
 		go func(stream int) {
 			r, err := e.startRunner(e.specCollection, stream)
 			if len(err) > 0 {
 				e.resultChan <- &result.SuiteResult{UnhandledErrors: err}
 				return
 			}
-			rChan <- r
+			rChan <- started{stream, r}
 		}(i)
 	}
-	for i := 1; i < totalStreams; i++ {
-		e.runners = append(e.runners, <-rChan)
+	for i := first; i <= totalStreams; i++ {
+		s := <-rChan
+		runners[s.stream-1] = s.runner
 	}
+	e.runners = runners
 }
 
 func (e *parallelExecution) run() *result.SuiteResult {
@@ -134,17 +153,32 @@ func (e *parallelExecution) run() *result.SuiteResult {
 		}
 	}
 
+	if isScenarioGranularity() {
+		specs := e.specCollection.Specs()
+		if isLazy() {
+			// the specs are ordered before they are split, so that the scenarios of a spec are handed out one after the other
+			// and a stream taking several of them runs the spec hooks once
+			specs = filter.OrderByEstimatedTime(specs)
+		}
+		e.specCollection = gauge.NewSpecCollection(splitByScenario(specs, e.errMaps), false)
+	}
 	if e.specCollection.Size() > 0 {
 		logger.Infof(true, "Executing in %d parallel streams.", e.numberOfStreams())
 		// skipcq CRT-A0013
 		if e.isMultithreaded() {
 			logger.Debugf(true, "Using multithreading for parallel execution.")
+			if vars, err := env.StreamEnv(1); err == nil && len(vars) > 0 {
+				logger.Warningf(true, "The streams share a runner when multithreading is enabled, the properties specific to a stream are not applied.")
+			}
 			if e.runners[0].Info().GRPCSupport {
 				go e.executeGrpcMultithreaded()
 			} else {
 				go e.executeLegacyMultithreaded()
 			}
 		} else if isLazy() {
+			if !isScenarioGranularity() {
+				e.specCollection = gauge.NewSpecCollection(filter.OrderByEstimatedTime(e.specCollection.Specs()), false)
+			}
 			go e.executeLazily()
 		} else {
 			go e.executeEagerly()
@@ -163,6 +197,7 @@ func (e *parallelExecution) run() *result.SuiteResult {
 
 func (e *parallelExecution) executeLazily() {
 	defer close(e.resultChan)
+	e.lockSpecs()
 	e.wg.Add(e.numberOfStreams())
 	e.startRunnersForRemainingStreams()
 
@@ -177,6 +212,7 @@ func (e *parallelExecution) executeLazily() {
 
 func (e *parallelExecution) executeLegacyMultithreaded() {
 	defer close(e.resultChan)
+	e.lockSpecs()
 	totalStreams := e.numberOfStreams()
 	e.wg.Add(totalStreams)
 	handlers := make([]*conn.GaugeConnectionHandler, 0)
@@ -220,15 +256,37 @@ func (e *parallelExecution) startMultithreaded(r runner.Runner, resChan chan *re
 	e.startSpecsExecutionWithRunner(e.specCollection, r, stream)
 }
 
+// lockSpecs makes the streams sharing the spec collection take turns on the specs holding the same lock.
+func (e *parallelExecution) lockSpecs() {
+	if filter.HasLocks(e.specCollection.Specs()) {
+		logger.Debugf(true, "Specs holding the same lock will not be executed at the same time.")
+		e.queue = newLockingQueue(e.specCollection)
+	}
+}
+
 func (e *parallelExecution) executeEagerly() {
 	defer close(e.resultChan)
-	distributions := e.numberOfStreams()
-	specs := filter.DistributeSpecs(e.specCollection.Specs(), distributions)
+	var specs []*gauge.SpecCollection
+	for _, s := range filter.DistributeSpecs(e.specCollection.Specs(), e.numberOfStreams()) {
+		if s != nil {
+			specs = append(specs, s)
+		}
+	}
+	if len(specs) < e.numberOfStreams() {
+		// the specs sharing a lock are assigned to the same stream, which can leave some of the streams without specs
+		logger.Infof(true, "Executing in %d parallel streams, as the specs holding the same lock are executed in the same stream.", len(specs))
+		e.numberOfExecutionStreams = len(specs)
+	}
+	distributions := len(specs)
 	e.wg.Add(distributions)
 	e.startRunnersForRemainingStreams()
 
 	for i, s := range specs {
 		i, s := i, s
+		if isScenarioGranularity() {
+			// the scenarios of a spec assigned to a stream are executed together, so the spec hooks are run once per stream
+			s = gauge.NewSpecCollection(s.Specs(), true)
+		}
 		go func(j int) {
 			defer e.wg.Done()
 			e.startSpecsExecutionWithRunner(s, e.runners[j], j+1)
@@ -250,12 +308,13 @@ func (e *parallelExecution) startRunner(s *gauge.SpecCollection, stream int) (ru
 		}
 		return nil, []error{streamExecError{specsSkipped: s.SpecNames(), message: fmt.Sprintf("Failed to start runner. %s", err.Error())}}
 	}
-	return runner, nil
+	return newRestartableRunner(runner, stream), nil
 }
 
 func (e *parallelExecution) startSpecsExecutionWithRunner(s *gauge.SpecCollection, runner runner.Runner, stream int) {
 	executionInfo := newExecutionInfo(s, runner, e.pluginHandler, e.errMaps, false, stream)
 	se := newSimpleExecution(executionInfo, false, false)
+	se.queue = e.queue
 	se.execute()
 	err := runner.Kill()
 	if err != nil {
@@ -281,6 +340,10 @@ func (e *parallelExecution) executeSpecsInSerial(s *gauge.SpecCollection) *resul
 }
 
 func (e *parallelExecution) finish() {
+	markInterrupted(e.suiteResult)
+	if isScenarioGranularity() {
+		orderScenarioResults(e.suiteResult.SpecResults)
+	}
 	e.suiteResult = mergeDataTableSpecResults(e.suiteResult)
 	event.Notify(event.NewExecutionEvent(event.SuiteEnd, nil, e.suiteResult, 0, &gauge_messages.ExecutionInfo{}))
 	message := &gauge_messages.Message{
@@ -294,7 +357,13 @@ func (e *parallelExecution) finish() {
 }
 
 func (e *parallelExecution) aggregateResults(suiteResults []*result.SuiteResult) {
-	r := result.NewSuiteResult(ExecuteTags, e.startTime)
+	r := aggregateSuiteResults(result.NewSuiteResult(ExecuteTags, e.startTime), suiteResults)
+	r.ExecutionTime = int64(time.Since(e.startTime) / 1e6)
+	e.suiteResult = r
+}
+
+// aggregateSuiteResults adds the spec results, hook failures and errors of the suite results to the given suite result.
+func aggregateSuiteResults(r *result.SuiteResult, suiteResults []*result.SuiteResult) *result.SuiteResult {
 	for _, result := range suiteResults {
 		r.SpecsFailedCount += result.SpecsFailedCount
 		r.SpecResults = append(r.SpecResults, result.SpecResults...)
@@ -311,9 +380,8 @@ func (e *parallelExecution) aggregateResults(suiteResults []*result.SuiteResult)
 			r.UnhandledErrors = append(r.UnhandledErrors, result.UnhandledErrors...)
 		}
 	}
-	r.ExecutionTime = int64(time.Since(e.startTime) / 1e6)
-	e.suiteResult = r
-	e.suiteResult.SetSpecsSkippedCount()
+	r.SetSpecsSkippedCount()
+	return r
 }
 
 func isLazy() bool {
@@ -12,6 +12,7 @@ import (
 
 func (e *parallelExecution) executeGrpcMultithreaded() {
 	defer close(e.resultChan)
+	e.lockSpecs()
 	totalStreams := e.numberOfStreams()
 	e.wg.Add(totalStreams)
 	r, ok := e.runners[0].(*runner.GrpcRunner)
@@ -32,6 +33,7 @@ func (e *parallelExecution) executeGrpcMultithreaded() {
 			defer e.wg.Done()
 			executionInfo := newExecutionInfo(e.specCollection, r, e.pluginHandler, e.errMaps, false, stream)
 			se := newSimpleExecution(executionInfo, false, true)
+			se.queue = e.queue
 			se.execute()
 			e.resultChan <- se.suiteResult
 		}(i)
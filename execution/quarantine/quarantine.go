@@ -0,0 +1,151 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+// Package quarantine identifies the scenarios whose failures are reported, but do not fail the execution.
+// A scenario is quarantined if it or its spec is tagged with `quarantine`, or if it is listed in .gauge/quarantine.json:
+//
+//	{
+//	  "scenarios": [
+//	    {"spec": "specs/login.spec", "scenario": "Login with SSO", "reason": "Flaky identity provider"},
+//	    {"spec": "specs/search.spec"}
+//	  ]
+//	}
+//
+// Spec paths are relative to the project root. An entry without a scenario quarantines all the scenarios of the spec.
+package quarantine
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/getgauge/common"
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/util"
+)
+
+const (
+	// Tag marks a spec or scenario as quarantined
+	Tag      = "quarantine"
+	fileName = "quarantine.json"
+)
+
+// Entry is a spec or a scenario listed in the quarantine file
+type Entry struct {
+	Spec     string `json:"spec"`
+	Scenario string `json:"scenario,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// List holds the quarantined specs and scenarios
+type List struct {
+	Scenarios []Entry `json:"scenarios"`
+}
+
+var (
+	current *List
+	once    sync.Once
+)
+
+// Current returns the quarantine list of the project, read from .gauge/quarantine.json on first use
+func Current() *List {
+	once.Do(func() {
+		current = load(filepath.Join(config.ProjectRoot, common.DotGauge, fileName))
+	})
+	return current
+}
+
+func load(file string) *List {
+	l := &List{}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warningf(true, "Unable to read quarantined scenarios from %s: %s", file, err.Error())
+		}
+		return l
+	}
+	if err := json.Unmarshal(b, l); err != nil {
+		logger.Warningf(true, "Unable to read quarantined scenarios from %s: %s", file, err.Error())
+		return &List{}
+	}
+	return l
+}
+
+// Contains returns true if the given scenario of the given spec is quarantined
+func (l *List) Contains(specFile string, specTags []string, scenario string, scenarioTags []string) bool {
+	if hasTag(specTags) || hasTag(scenarioTags) {
+		return true
+	}
+	if l == nil {
+		return false
+	}
+	spec := filepath.ToSlash(util.RelPathToProjectRoot(specFile))
+	for _, e := range l.Scenarios {
+		if filepath.ToSlash(filepath.Clean(e.Spec)) == spec && (e.Scenario == "" || e.Scenario == scenario) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTag(tags []string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(strings.TrimSpace(t), Tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// FailedScenarios returns the headings of the quarantined scenarios that failed in the given spec
+func (l *List) FailedScenarios(spec *gauge_messages.ProtoSpec) []string {
+	var failed []string
+	for _, scn := range result.Scenarios(spec) {
+		if scn.GetExecutionStatus() == gauge_messages.ExecutionStatus_FAILED && l.Contains(spec.GetFileName(), spec.GetTags(), scn.GetScenarioHeading(), scn.GetTags()) {
+			failed = append(failed, scn.GetScenarioHeading())
+		}
+	}
+	return failed
+}
+
+// OnlyQuarantinedFailures returns true if the suite failed only because of failures in quarantined scenarios.
+// Hook failures and spec errors are never quarantined.
+func (l *List) OnlyQuarantinedFailures(res *result.SuiteResult) bool {
+	if !res.IsFailed || res.PreSuite != nil || res.PostSuite != nil || len(res.UnhandledErrors) > 0 {
+		return false
+	}
+	quarantined := false
+	for _, specRes := range res.SpecResults {
+		if !specRes.IsFailed {
+			continue
+		}
+		spec := specRes.ProtoSpec
+		if len(spec.GetPreHookFailures()) > 0 || len(spec.GetPostHookFailures()) > 0 || len(specRes.Errors) > 0 {
+			return false
+		}
+		specFailed := false
+		for _, scn := range result.Scenarios(spec) {
+			if scn.GetExecutionStatus() != gauge_messages.ExecutionStatus_FAILED {
+				continue
+			}
+			if !l.Contains(spec.GetFileName(), spec.GetTags(), scn.GetScenarioHeading(), scn.GetTags()) {
+				return false
+			}
+			specFailed = true
+		}
+		if !specFailed {
+			return false
+		}
+		quarantined = true
+	}
+	return quarantined
+}
@@ -0,0 +1,118 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package quarantine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/result"
+)
+
+func failedSuite(specFile string, specTags []string, scenarios ...*gauge_messages.ProtoScenario) *result.SuiteResult {
+	spec := &gauge_messages.ProtoSpec{FileName: specFile, Tags: specTags}
+	for _, scn := range scenarios {
+		spec.Items = append(spec.Items, &gauge_messages.ProtoItem{ItemType: gauge_messages.ProtoItem_Scenario, Scenario: scn})
+	}
+	return &result.SuiteResult{IsFailed: true, SpecResults: []*result.SpecResult{{ProtoSpec: spec, IsFailed: true}}}
+}
+
+func scenario(heading string, status gauge_messages.ExecutionStatus, tags ...string) *gauge_messages.ProtoScenario {
+	return &gauge_messages.ProtoScenario{ScenarioHeading: heading, ExecutionStatus: status, Tags: tags}
+}
+
+func TestLoadReadsQuarantineFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quarantine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, fileName)
+	if err := ioutil.WriteFile(file, []byte(`{"scenarios":[{"spec":"specs/a.spec","scenario":"Flaky one","reason":"timing"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := load(file)
+
+	want := Entry{Spec: "specs/a.spec", Scenario: "Flaky one", Reason: "timing"}
+	if len(l.Scenarios) != 1 || l.Scenarios[0] != want {
+		t.Errorf("want %v, got %v", want, l.Scenarios)
+	}
+}
+
+func TestLoadReturnsEmptyListIfFileDoesNotExist(t *testing.T) {
+	l := load(filepath.Join("_testdata", "does-not-exist.json"))
+
+	if len(l.Scenarios) != 0 {
+		t.Errorf("want an empty list, got %v", l.Scenarios)
+	}
+}
+
+func TestContains(t *testing.T) {
+	oldRoot := config.ProjectRoot
+	config.ProjectRoot = filepath.Join("project")
+	defer func() { config.ProjectRoot = oldRoot }()
+	l := &List{Scenarios: []Entry{{Spec: "specs/a.spec", Scenario: "Flaky one"}, {Spec: "specs/b.spec"}}}
+	a := filepath.Join("project", "specs", "a.spec")
+	b := filepath.Join("project", "specs", "b.spec")
+
+	tests := []struct {
+		name         string
+		specFile     string
+		specTags     []string
+		scenario     string
+		scenarioTags []string
+		want         bool
+	}{
+		{"listed scenario", a, nil, "Flaky one", nil, true},
+		{"unlisted scenario of listed spec", a, nil, "Stable one", nil, false},
+		{"any scenario of listed spec", b, nil, "Anything", nil, true},
+		{"scenario tag", filepath.Join("project", "c.spec"), nil, "Tagged", []string{"Quarantine"}, true},
+		{"spec tag", filepath.Join("project", "c.spec"), []string{"quarantine"}, "Untagged", nil, true},
+	}
+	for _, test := range tests {
+		if got := l.Contains(test.specFile, test.specTags, test.scenario, test.scenarioTags); got != test.want {
+			t.Errorf("%s: want %v, got %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestOnlyQuarantinedFailures(t *testing.T) {
+	l := &List{}
+	res := failedSuite("a.spec", nil,
+		scenario("passed", gauge_messages.ExecutionStatus_PASSED),
+		scenario("quarantined", gauge_messages.ExecutionStatus_FAILED, "quarantine"))
+
+	if !l.OnlyQuarantinedFailures(res) {
+		t.Error("want failures of quarantined scenarios to be ignored")
+	}
+}
+
+func TestOnlyQuarantinedFailuresWhenOtherScenariosFail(t *testing.T) {
+	l := &List{}
+	res := failedSuite("a.spec", nil,
+		scenario("failed", gauge_messages.ExecutionStatus_FAILED),
+		scenario("quarantined", gauge_messages.ExecutionStatus_FAILED, "quarantine"))
+
+	if l.OnlyQuarantinedFailures(res) {
+		t.Error("want failures of scenarios which are not quarantined to fail the suite")
+	}
+}
+
+func TestOnlyQuarantinedFailuresWhenHooksFail(t *testing.T) {
+	l := &List{}
+	res := failedSuite("a.spec", []string{"quarantine"}, scenario("quarantined", gauge_messages.ExecutionStatus_FAILED))
+	res.PreSuite = &gauge_messages.ProtoHookFailure{ErrorMessage: "before suite failed"}
+
+	if l.OnlyQuarantinedFailures(res) {
+		t.Error("want hook failures to fail the suite")
+	}
+}
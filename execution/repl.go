@@ -0,0 +1,456 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getgauge/common"
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/parser"
+	"github.com/getgauge/gauge/plugin"
+	"github.com/getgauge/gauge/prompt"
+	"github.com/getgauge/gauge/runner"
+	"github.com/getgauge/gauge/util"
+	"github.com/getgauge/gauge/validation"
+)
+
+const (
+	replPrompt  = "gauge> "
+	replHeading = "REPL"
+	replStream  = 0
+	replHelp    = `Type a step to execute it, or one of the commands:
+  :hooks on|off     run the before hooks and the step hooks, or run the after hooks and stop running them
+  :reset            clear the data stores and reload the concepts, running the hooks again if they are on
+  :load <spec>:<n>  execute the contexts and the steps of the scenario at line n of the spec, up to line n
+  :help             show this help
+  :quit             stop the runner and exit`
+)
+
+var replCommands = []string{":help", ":hooks off", ":hooks on", ":load ", ":quit", ":reset"}
+
+// repl executes the steps typed at the prompt against a runner which is kept alive in between. The data stores are
+// shared by the steps till they are reset, as if they were the steps of a single scenario.
+type repl struct {
+	runner        runner.Runner
+	pluginHandler plugin.Handler
+	concepts      *gauge.ConceptDictionary
+	stepNames     []string
+	hooks         bool
+	executionInfo *gauge_messages.ExecutionInfo
+	out           io.Writer
+}
+
+func newRepl(r runner.Runner, out io.Writer) *repl {
+	return &repl{
+		runner:        r,
+		pluginHandler: &plugin.GaugePlugins{},
+		concepts:      gauge.NewConceptDictionary(),
+		out:           out,
+		executionInfo: &gauge_messages.ExecutionInfo{
+			CurrentSpec:     &gauge_messages.SpecInfo{Name: replHeading},
+			CurrentScenario: &gauge_messages.ScenarioInfo{Name: replHeading},
+			ProjectName:     filepath.Base(config.ProjectRoot),
+		},
+	}
+}
+
+// Repl starts the runner of the project and executes the steps read from the input, till it ends or :quit is typed.
+func Repl(in io.Reader, out io.Writer) {
+	r := newRepl(newRestartableRunner(validation.StartRunner(false), replStream), out)
+	r.load()
+	p := prompt.New(in, out, r.complete)
+	fmt.Fprintln(out, "Type a step to execute it, or :help for the commands.")
+	for {
+		line, err := p.ReadLine(replPrompt)
+		if err == prompt.ErrInterrupted {
+			continue
+		}
+		if err != nil || !r.eval(line) {
+			break
+		}
+	}
+	r.close()
+}
+
+// eval executes the step or the command typed, and returns false if the repl should exit.
+func (r *repl) eval(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+	if !strings.HasPrefix(line, ":") {
+		r.executeText(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+		return true
+	}
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":q", ":quit", ":exit":
+		return false
+	case ":help":
+		fmt.Fprintln(r.out, replHelp)
+	case ":hooks":
+		if len(fields) != 2 || (fields[1] != "on" && fields[1] != "off") {
+			fmt.Fprintln(r.out, "Usage: :hooks on|off")
+			return true
+		}
+		r.setHooks(fields[1] == "on")
+	case ":reset":
+		r.reset()
+	case ":load":
+		if len(fields) != 2 {
+			fmt.Fprintln(r.out, "Usage: :load <spec>:<line>")
+			return true
+		}
+		r.loadScenario(fields[1])
+	default:
+		fmt.Fprintf(r.out, "Unknown command %s. Type :help for the commands.\n", fields[0])
+	}
+	return true
+}
+
+// complete returns the commands, or the steps implemented by the runner and the concepts, starting with the line typed.
+func (r *repl) complete(line string) []string {
+	var candidates []string
+	if strings.HasPrefix(line, ":") {
+		for _, c := range replCommands {
+			if strings.HasPrefix(c, line) {
+				candidates = append(candidates, c)
+			}
+		}
+		return candidates
+	}
+	prefix := ""
+	if strings.HasPrefix(line, "*") {
+		prefix = "* "
+		line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+	}
+	for _, s := range r.stepNames {
+		if strings.HasPrefix(strings.ToLower(s), strings.ToLower(line)) {
+			candidates = append(candidates, prefix+s)
+		}
+	}
+	return candidates
+}
+
+// load reads the concepts and the steps implemented by the runner, and initialises the data stores.
+func (r *repl) load() {
+	if dict, res, err := parser.ParseConcepts(); err != nil {
+		fmt.Fprintf(r.out, "Unable to parse the concepts. %s\n", err.Error())
+	} else {
+		r.concepts = dict
+		r.printErrors(res.Errors())
+	}
+	names := make(map[string]bool)
+	res, err := r.runner.ExecuteMessageWithTimeout(&gauge_messages.Message{MessageType: gauge_messages.Message_StepNamesRequest,
+		StepNamesRequest: &gauge_messages.StepNamesRequest{}})
+	if err != nil {
+		logger.Warningf(true, "Unable to get the steps from the runner, only the concepts are completed. %s", err.Error())
+	}
+	for _, s := range res.GetStepNamesResponse().GetSteps() {
+		names[s] = true
+	}
+	for _, c := range r.concepts.ConceptsMap {
+		names[c.ConceptStep.LineText] = true
+	}
+	r.stepNames = r.stepNames[:0]
+	for s := range names {
+		r.stepNames = append(r.stepNames, s)
+	}
+	sort.Strings(r.stepNames)
+	r.runHooks(
+		replHook{"suite data store initialisation", &gauge_messages.Message{MessageType: gauge_messages.Message_SuiteDataStoreInit,
+			SuiteDataStoreInitRequest: &gauge_messages.SuiteDataStoreInitRequest{Stream: replStream}}},
+		replHook{"spec data store initialisation", &gauge_messages.Message{MessageType: gauge_messages.Message_SpecDataStoreInit,
+			SpecDataStoreInitRequest: &gauge_messages.SpecDataStoreInitRequest{Stream: replStream}}},
+		replHook{"scenario data store initialisation", &gauge_messages.Message{MessageType: gauge_messages.Message_ScenarioDataStoreInit,
+			ScenarioDataStoreInitRequest: &gauge_messages.ScenarioDataStoreInitRequest{Stream: replStream}}},
+	)
+}
+
+func (r *repl) reset() {
+	if r.hooks {
+		r.afterHooks()
+	}
+	r.load()
+	if r.hooks {
+		r.beforeHooks()
+	}
+	fmt.Fprintln(r.out, "The data stores are cleared.")
+}
+
+func (r *repl) close() {
+	if r.hooks {
+		r.afterHooks()
+	}
+	if err := r.runner.Kill(); err != nil {
+		logger.Errorf(false, "unable to kill runner: %s", err.Error())
+	}
+}
+
+// executeText parses the step typed, resolving the concepts, and executes it.
+func (r *repl) executeText(text string) {
+	specText := fmt.Sprintf("# %s\n## %s\n* %s\n", replHeading, replHeading, text)
+	spec, res, err := new(parser.SpecParser).Parse(specText, r.concepts, replHeading)
+	if err != nil {
+		fmt.Fprintln(r.out, err.Error())
+		return
+	}
+	if !res.Ok || len(spec.Scenarios) == 0 {
+		r.printErrors(res.Errors())
+		return
+	}
+	r.execute(spec, spec.Scenarios[0], spec.Scenarios[0].Steps)
+}
+
+// loadScenario executes the contexts and the steps of the scenario at the given line of a spec, up to that line.
+func (r *repl) loadScenario(arg string) {
+	i := strings.LastIndex(arg, ":")
+	line, err := strconv.Atoi(arg[i+1:])
+	if i < 0 || err != nil {
+		fmt.Fprintln(r.out, "Usage: :load <spec>:<line>")
+		return
+	}
+	file := util.GetPathToFile(arg[:i])
+	text, err := common.ReadFileContents(file)
+	if err != nil {
+		fmt.Fprintln(r.out, err.Error())
+		return
+	}
+	spec, res, err := new(parser.SpecParser).Parse(text, r.concepts, file)
+	if err != nil {
+		fmt.Fprintln(r.out, err.Error())
+		return
+	}
+	if !res.Ok {
+		r.printErrors(res.Errors())
+		return
+	}
+	for _, scn := range spec.Scenarios {
+		if !scn.InSpan(line) {
+			continue
+		}
+		steps := append([]*gauge.Step{}, spec.Contexts...)
+		for _, s := range scn.Steps {
+			if s.LineNo <= line {
+				steps = append(steps, s)
+			}
+		}
+		r.execute(spec, scn, steps)
+		return
+	}
+	fmt.Fprintf(r.out, "No scenario at %s:%d\n", arg[:i], line)
+}
+
+func (r *repl) execute(spec *gauge.Specification, scn *gauge.Scenario, steps []*gauge.Step) {
+	if !r.validate(spec, steps) {
+		return
+	}
+	lookup := new(gauge.ArgLookup)
+	for _, t := range []*gauge.Table{spec.DataTable.Table, scn.DataTable.Table} {
+		if t.GetRowCount() == 0 {
+			continue
+		}
+		parser.GetResolvedDataTablerows(t)
+		if err := lookup.ReadDataTableRow(t, 0); err != nil {
+			fmt.Fprintln(r.out, err.Error())
+			return
+		}
+	}
+	items := make([]gauge.Item, len(steps))
+	for i, s := range steps {
+		items[i] = s
+	}
+	protoItems, err := resolveItems(items, lookup, func(protoStep *gauge_messages.ProtoStep, step *gauge.Step) {
+		protoStep.StepExecutionResult = &gauge_messages.ProtoStepExecutionResult{}
+	})
+	if err != nil {
+		fmt.Fprintln(r.out, err.Error())
+		return
+	}
+	r.executionInfo.CurrentSpec = &gauge_messages.SpecInfo{Name: spec.Heading.Value, FileName: spec.FileName, Tags: getTagValue(spec.Tags)}
+	r.executionInfo.CurrentScenario = &gauge_messages.ScenarioInfo{Name: scn.Heading.Value, Tags: getTagValue(scn.Tags)}
+	res := &result.ScenarioResult{ProtoScenario: gauge.NewProtoScenario(scn)}
+	se := newScenarioExecutor(r.runner, r.pluginHandler, r.executionInfo, gauge.NewBuildErrors(), nil, nil, replStream)
+	se.skipStepHooks = !r.hooks
+	se.executeSteps(steps, protoItems, res, false)
+	r.printItems(protoItems, 0)
+}
+
+// validate checks that the steps are implemented, printing the errors along with the suggested implementations.
+func (r *repl) validate(spec *gauge.Specification, steps []*gauge.Step) bool {
+	s := &gauge.Specification{Heading: spec.Heading, FileName: spec.FileName, DataTable: spec.DataTable}
+	for _, step := range steps {
+		s.Items = append(s.Items, step)
+	}
+	errs := validation.NewValidator([]*gauge.Specification{s}, r.runner, r.concepts).Validate()[s]
+	for _, err := range errs {
+		fmt.Fprintln(r.out, err.Error())
+		if e, ok := err.(validation.StepValidationError); ok && e.Suggestion() != "" {
+			fmt.Fprintf(r.out, "Suggested implementation:\n%s\n", e.Suggestion())
+		}
+	}
+	return len(errs) == 0
+}
+
+func (r *repl) setHooks(on bool) {
+	if on == r.hooks {
+		fmt.Fprintf(r.out, "The hooks are already %s.\n", map[bool]string{true: "on", false: "off"}[on])
+		return
+	}
+	if on {
+		r.beforeHooks()
+	} else {
+		r.afterHooks()
+	}
+	r.hooks = on
+}
+
+type replHook struct {
+	name string
+	msg  *gauge_messages.Message
+}
+
+func (r *repl) beforeHooks() {
+	r.runHooks(
+		replHook{"before suite", &gauge_messages.Message{MessageType: gauge_messages.Message_ExecutionStarting,
+			ExecutionStartingRequest: &gauge_messages.ExecutionStartingRequest{CurrentExecutionInfo: r.executionInfo, Stream: replStream}}},
+		replHook{"before spec", &gauge_messages.Message{MessageType: gauge_messages.Message_SpecExecutionStarting,
+			SpecExecutionStartingRequest: &gauge_messages.SpecExecutionStartingRequest{CurrentExecutionInfo: r.executionInfo, Stream: replStream}}},
+		replHook{"before scenario", &gauge_messages.Message{MessageType: gauge_messages.Message_ScenarioExecutionStarting,
+			ScenarioExecutionStartingRequest: &gauge_messages.ScenarioExecutionStartingRequest{CurrentExecutionInfo: r.executionInfo, Stream: replStream}}},
+	)
+}
+
+func (r *repl) afterHooks() {
+	r.runHooks(
+		replHook{"after scenario", &gauge_messages.Message{MessageType: gauge_messages.Message_ScenarioExecutionEnding,
+			ScenarioExecutionEndingRequest: &gauge_messages.ScenarioExecutionEndingRequest{CurrentExecutionInfo: r.executionInfo, Stream: replStream}}},
+		replHook{"after spec", &gauge_messages.Message{MessageType: gauge_messages.Message_SpecExecutionEnding,
+			SpecExecutionEndingRequest: &gauge_messages.SpecExecutionEndingRequest{CurrentExecutionInfo: r.executionInfo, Stream: replStream}}},
+		replHook{"after suite", &gauge_messages.Message{MessageType: gauge_messages.Message_ExecutionEnding,
+			ExecutionEndingRequest: &gauge_messages.ExecutionEndingRequest{CurrentExecutionInfo: r.executionInfo, Stream: replStream}}},
+	)
+}
+
+func (r *repl) runHooks(hooks ...replHook) {
+	for _, h := range hooks {
+		res := r.runner.ExecuteAndGetStatus(h.msg)
+		r.printLines(res.GetMessage(), 2)
+		if res.GetFailed() {
+			fmt.Fprintf(r.out, "%s %s failed\n", replSymbol(true), h.name)
+			r.printFailure(res.GetErrorMessage(), res.GetStackTrace(), res.GetFailureScreenshotFile(), 2)
+		}
+		r.printScreenshots(res.GetScreenshotFiles(), 2)
+	}
+}
+
+func (r *repl) printItems(items []*gauge_messages.ProtoItem, indentation int) {
+	for _, item := range items {
+		switch item.GetItemType() {
+		case gauge_messages.ProtoItem_Step:
+			r.printStep(item.GetStep(), indentation)
+		case gauge_messages.ProtoItem_Concept:
+			c := item.GetConcept()
+			r.printStepStatus(c.GetConceptStep().GetActualText(), c.GetConceptExecutionResult().GetExecutionResult(), indentation)
+			r.printItems(c.GetSteps(), indentation+2)
+		}
+	}
+}
+
+func (r *repl) printStep(step *gauge_messages.ProtoStep, indentation int) {
+	res := step.GetStepExecutionResult()
+	r.printStepStatus(step.GetActualText(), res.GetExecutionResult(), indentation)
+	indentation += 2
+	r.printLines(step.GetPreHookMessages(), indentation)
+	if f := res.GetPreHookFailure(); f != nil {
+		fmt.Fprintf(r.out, "%sBefore step hook failed\n", spaces(indentation))
+		r.printFailure(f.GetErrorMessage(), f.GetStackTrace(), f.GetFailureScreenshotFile(), indentation)
+	}
+	r.printScreenshots(step.GetPreHookScreenshotFiles(), indentation)
+	if e := res.GetExecutionResult(); e != nil {
+		r.printLines(e.GetMessage(), indentation)
+		if e.GetFailed() {
+			r.printFailure(e.GetErrorMessage(), e.GetStackTrace(), e.GetFailureScreenshotFile(), indentation)
+		}
+		r.printScreenshots(e.GetScreenshotFiles(), indentation)
+	}
+	r.printLines(step.GetPostHookMessages(), indentation)
+	if f := res.GetPostHookFailure(); f != nil {
+		fmt.Fprintf(r.out, "%sAfter step hook failed\n", spaces(indentation))
+		r.printFailure(f.GetErrorMessage(), f.GetStackTrace(), f.GetFailureScreenshotFile(), indentation)
+	}
+	r.printScreenshots(step.GetPostHookScreenshotFiles(), indentation)
+}
+
+// printStepStatus prints the step as skipped if it has no result, i.e. it was not executed due to a previous failure.
+func (r *repl) printStepStatus(text string, res *gauge_messages.ProtoExecutionResult, indentation int) {
+	if res == nil {
+		fmt.Fprintf(r.out, "%s- %s (skipped)\n", spaces(indentation), text)
+		return
+	}
+	fmt.Fprintf(r.out, "%s%s %s (%dms)\n", spaces(indentation), replSymbol(res.GetFailed()), text, res.GetExecutionTime())
+}
+
+func (r *repl) printFailure(msg, stacktrace, screenshot string, indentation int) {
+	fmt.Fprintf(r.out, "%sError Message: %s\n", spaces(indentation), msg)
+	if stacktrace != "" {
+		fmt.Fprintf(r.out, "%sStacktrace:\n", spaces(indentation))
+		r.printLines(strings.Split(strings.TrimRight(stacktrace, "\n"), "\n"), indentation)
+	}
+	if screenshot != "" {
+		r.printScreenshots([]string{screenshot}, indentation)
+	}
+}
+
+func (r *repl) printScreenshots(files []string, indentation int) {
+	for _, f := range files {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(os.Getenv(env.GaugeScreenshotsDir), f)
+		}
+		fmt.Fprintf(r.out, "%sScreenshot: %s\n", spaces(indentation), f)
+	}
+}
+
+func (r *repl) printLines(lines []string, indentation int) {
+	for _, l := range lines {
+		fmt.Fprintf(r.out, "%s%s\n", spaces(indentation), l)
+	}
+}
+
+func (r *repl) printErrors(errs []string) {
+	for _, e := range errs {
+		fmt.Fprintln(r.out, e)
+	}
+}
+
+func replSymbol(failed bool) string {
+	switch {
+	case failed && util.IsWindows():
+		return "F"
+	case failed:
+		return "✘"
+	case util.IsWindows():
+		return "P"
+	}
+	return "✔"
+}
+
+func spaces(n int) string {
+	return strings.Repeat(" ", n)
+}
@@ -0,0 +1,170 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+)
+
+// replRunner implements every step except the ones in unimplemented, and records the messages it executes.
+type replRunner struct {
+	mockRunner
+	unimplemented map[string]bool
+	executed      []*gauge_messages.Message
+}
+
+func newReplRunner(unimplemented ...string) *replRunner {
+	r := &replRunner{unimplemented: make(map[string]bool)}
+	for _, s := range unimplemented {
+		r.unimplemented[s] = true
+	}
+	r.ExecuteAndGetStatusFunc = func(m *gauge_messages.Message) *gauge_messages.ProtoExecutionResult {
+		r.executed = append(r.executed, m)
+		if m.GetMessageType() == gauge_messages.Message_ExecuteStep {
+			return &gauge_messages.ProtoExecutionResult{Message: []string{"executed " + m.GetExecuteStepRequest().GetActualStepText()}}
+		}
+		return &gauge_messages.ProtoExecutionResult{}
+	}
+	return r
+}
+
+func (r *replRunner) ExecuteMessageWithTimeout(m *gauge_messages.Message) (*gauge_messages.Message, error) {
+	if m.GetMessageType() == gauge_messages.Message_StepNamesRequest {
+		return &gauge_messages.Message{MessageType: gauge_messages.Message_StepNamesResponse,
+			StepNamesResponse: &gauge_messages.StepNamesResponse{Steps: []string{"Say <word>", "Open the browser", "Open the page"}}}, nil
+	}
+	res := &gauge_messages.StepValidateResponse{IsValid: true}
+	if r.unimplemented[m.GetStepValidateRequest().GetStepText()] {
+		res = &gauge_messages.StepValidateResponse{IsValid: false, ErrorType: gauge_messages.StepValidateResponse_STEP_IMPLEMENTATION_NOT_FOUND,
+			Suggestion: "@Step(\"Not implemented\")"}
+	}
+	return &gauge_messages.Message{MessageType: gauge_messages.Message_StepValidateResponse, StepValidateResponse: res}, nil
+}
+
+func (r *replRunner) executedTypes() []gauge_messages.Message_MessageType {
+	var types []gauge_messages.Message_MessageType
+	for _, m := range r.executed {
+		types = append(types, m.GetMessageType())
+	}
+	r.executed = nil
+	return types
+}
+
+// inTempProject sets the project root to a new directory, returning a function to remove it and restore the root.
+func inTempProject(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "repl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := config.ProjectRoot
+	config.ProjectRoot = dir
+	return dir, func() {
+		config.ProjectRoot = old
+		os.RemoveAll(dir)
+	}
+}
+
+func TestReplCompletesCommandsAndSteps(t *testing.T) {
+	_, cleanup := inTempProject(t)
+	defer cleanup()
+	r := newRepl(newReplRunner(), &bytes.Buffer{})
+	r.load()
+
+	if got, want := r.complete(":h"), []string{":help", ":hooks off", ":hooks on"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got, want := r.complete("open the"), []string{"Open the browser", "Open the page"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if got, want := r.complete("* sa"), []string{"* Say <word>"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestReplExecutesStepsWithoutHooksTillTheyAreOn(t *testing.T) {
+	_, cleanup := inTempProject(t)
+	defer cleanup()
+	out := &bytes.Buffer{}
+	rr := newReplRunner()
+	r := newRepl(rr, out)
+	r.load()
+	rr.executedTypes()
+
+	r.eval(`* Say "hello"`)
+	if got, want := rr.executedTypes(), []gauge_messages.Message_MessageType{gauge_messages.Message_ExecuteStep}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected only the step to be executed, got %v", got)
+	}
+	if !strings.Contains(out.String(), `Say "hello"`) || !strings.Contains(out.String(), `executed Say "hello"`) {
+		t.Errorf("Expected the step and its messages to be printed, got %q", out.String())
+	}
+
+	r.eval(":hooks on")
+	want := []gauge_messages.Message_MessageType{gauge_messages.Message_ExecutionStarting, gauge_messages.Message_SpecExecutionStarting, gauge_messages.Message_ScenarioExecutionStarting}
+	if got := rr.executedTypes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the before hooks to be executed, got %v", got)
+	}
+	r.eval("Open the browser")
+	want = []gauge_messages.Message_MessageType{gauge_messages.Message_StepExecutionStarting, gauge_messages.Message_ExecuteStep, gauge_messages.Message_StepExecutionEnding}
+	if got := rr.executedTypes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the step hooks to be executed, got %v", got)
+	}
+	r.eval(":reset")
+	want = []gauge_messages.Message_MessageType{gauge_messages.Message_ScenarioExecutionEnding, gauge_messages.Message_SpecExecutionEnding, gauge_messages.Message_ExecutionEnding,
+		gauge_messages.Message_SuiteDataStoreInit, gauge_messages.Message_SpecDataStoreInit, gauge_messages.Message_ScenarioDataStoreInit,
+		gauge_messages.Message_ExecutionStarting, gauge_messages.Message_SpecExecutionStarting, gauge_messages.Message_ScenarioExecutionStarting}
+	if got := rr.executedTypes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the data stores to be initialised again within the hooks, got %v", got)
+	}
+	if r.eval(":quit") {
+		t.Error("Expected :quit to exit")
+	}
+}
+
+func TestReplDoesNotExecuteUnimplementedSteps(t *testing.T) {
+	out := &bytes.Buffer{}
+	rr := newReplRunner("Not implemented")
+	r := newRepl(rr, out)
+
+	r.eval("Not implemented")
+
+	if len(rr.executed) != 0 {
+		t.Errorf("Expected the step not to be executed, got %v", rr.executedTypes())
+	}
+	if !strings.Contains(out.String(), `@Step("Not implemented")`) {
+		t.Errorf("Expected the suggested implementation to be printed, got %q", out.String())
+	}
+}
+
+func TestReplLoadsTheScenarioUpToTheLine(t *testing.T) {
+	dir, cleanup := inTempProject(t)
+	defer cleanup()
+	spec := "# Spec\n* Context\n## First\n* One\n* Two\n## Second\n* Three\n* Four\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "example.spec"), []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rr := newReplRunner()
+	r := newRepl(rr, &bytes.Buffer{})
+
+	r.eval(":load example.spec:7")
+
+	var steps []string
+	for _, m := range rr.executed {
+		steps = append(steps, m.GetExecuteStepRequest().GetActualStepText())
+	}
+	if want := []string{"Context", "Three"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("Expected %v to be executed, got %v", want, steps)
+	}
+}
@@ -0,0 +1,446 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/getgauge/common"
+	m "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/util"
+)
+
+const (
+	htmlFormat     = "html"
+	htmlIndexFile  = "index.html"
+	htmlSpecsDir   = "specs"
+	htmlImagesDir  = "images"
+	passedStatus   = "passed"
+	failedStatus   = "failed"
+	skippedStatus  = "skipped"
+	htmlTimeLayout = "2006-01-02 15:04:05"
+)
+
+var nonAlphaNumeric = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+type htmlSuite struct {
+	ProjectName   string
+	Environment   string
+	Tags          string
+	Timestamp     string
+	GeneratedAt   string
+	ExecutionTime string
+	Status        string
+	SuccessRate   string
+	Passed        int
+	Failed        int
+	Skipped       int
+	Hooks         []*htmlHookFailure
+	Specs         []*htmlSpecSummary
+}
+
+type htmlSpecSummary struct {
+	Heading       string
+	File          string
+	Page          string
+	Status        string
+	Scenarios     int
+	Failed        int
+	Skipped       int
+	ExecutionTime string
+}
+
+type htmlSpec struct {
+	Heading       string
+	File          string
+	Status        string
+	Tags          []string
+	ExecutionTime string
+	Errors        []string
+	Hooks         []*htmlHookFailure
+	Scenarios     []*htmlScenario
+}
+
+type htmlScenario struct {
+	Heading       string
+	Status        string
+	Tags          []string
+	ExecutionTime string
+	Attempts      int64
+	SkipReasons   []string
+	Hooks         []*htmlHookFailure
+	Steps         []*htmlStep
+}
+
+type htmlStep struct {
+	Text        string
+	Status      string
+	Depth       int
+	Error       string
+	StackTrace  string
+	Messages    []string
+	Screenshots []string
+	Hooks       []*htmlHookFailure
+}
+
+type htmlHookFailure struct {
+	Name       string
+	Error      string
+	StackTrace string
+	Screenshot string
+}
+
+// htmlReport writes the pages of the report to dir. Screenshots are copied from gauge_screenshots_dir to the
+// images directory of the report, so that the report can be moved around or archived as is.
+type htmlReport struct {
+	dir         string
+	imagesDir   string
+	screenshots map[string]string
+}
+
+func writeHTMLReport(res *m.ProtoSuiteResult, dir string) error {
+	r := &htmlReport{dir: dir, imagesDir: os.Getenv(env.GaugeScreenshotsDir), screenshots: make(map[string]string)}
+	// pages and screenshots of a previous report are removed, as the report dir is reused when overwrite_reports is set.
+	for _, d := range []string{htmlSpecsDir, htmlImagesDir} {
+		if err := os.RemoveAll(filepath.Join(dir, d)); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, htmlSpecsDir), common.NewDirectoryPermissions); err != nil {
+		return err
+	}
+	suite := r.suite(res)
+	for i, specRes := range res.GetSpecResults() {
+		if err := r.writePage(filepath.Join(dir, suite.Specs[i].Page), "spec", r.spec(specRes)); err != nil {
+			return err
+		}
+	}
+	return r.writePage(filepath.Join(dir, htmlIndexFile), "index", suite)
+}
+
+func (r *htmlReport) writePage(file, name string, data interface{}) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := htmlTemplates.ExecuteTemplate(f, name, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (r *htmlReport) suite(res *m.ProtoSuiteResult) *htmlSuite {
+	s := &htmlSuite{
+		ProjectName:   res.GetProjectName(),
+		Environment:   res.GetEnvironment(),
+		Tags:          res.GetTags(),
+		Timestamp:     res.GetTimestamp(),
+		GeneratedAt:   time.Now().Format(htmlTimeLayout),
+		ExecutionTime: duration(res.GetExecutionTime()),
+		Status:        passedStatus,
+		SuccessRate:   fmt.Sprintf("%.2f%%", res.GetSuccessRate()),
+	}
+	if res.GetFailed() {
+		s.Status = failedStatus
+	}
+	if f := res.GetPreHookFailure(); f != nil {
+		s.Hooks = append(s.Hooks, r.hookFailure("Before Suite", f, ""))
+	}
+	if f := res.GetPostHookFailure(); f != nil {
+		s.Hooks = append(s.Hooks, r.hookFailure("After Suite", f, ""))
+	}
+	for i, specRes := range res.GetSpecResults() {
+		spec := specRes.GetProtoSpec()
+		file := filepath.ToSlash(util.RelPathToProjectRoot(spec.GetFileName()))
+		summary := &htmlSpecSummary{
+			Heading:       spec.GetSpecHeading(),
+			File:          file,
+			Page:          fmt.Sprintf("%s/%d-%s.html", htmlSpecsDir, i+1, strings.Trim(nonAlphaNumeric.ReplaceAllString(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), "-"), "-")),
+			Status:        specStatus(specRes),
+			Scenarios:     int(specRes.GetScenarioCount()),
+			Failed:        int(specRes.GetScenarioFailedCount()),
+			Skipped:       int(specRes.GetScenarioSkippedCount()),
+			ExecutionTime: duration(specRes.GetExecutionTime()),
+		}
+		switch summary.Status {
+		case failedStatus:
+			s.Failed++
+		case skippedStatus:
+			s.Skipped++
+		default:
+			s.Passed++
+		}
+		s.Specs = append(s.Specs, summary)
+	}
+	return s
+}
+
+func (r *htmlReport) spec(res *m.ProtoSpecResult) *htmlSpec {
+	spec := res.GetProtoSpec()
+	s := &htmlSpec{
+		Heading:       spec.GetSpecHeading(),
+		File:          filepath.ToSlash(util.RelPathToProjectRoot(spec.GetFileName())),
+		Status:        specStatus(res),
+		Tags:          spec.GetTags(),
+		ExecutionTime: duration(res.GetExecutionTime()),
+	}
+	for _, e := range res.GetErrors() {
+		s.Errors = append(s.Errors, fmt.Sprintf("%s:%d %s", e.GetFilename(), e.GetLineNumber(), e.GetMessage()))
+	}
+	for _, f := range spec.GetPreHookFailures() {
+		s.Hooks = append(s.Hooks, r.hookFailure(withRow("Before Spec", spec, f), f, "../"))
+	}
+	for _, f := range spec.GetPostHookFailures() {
+		s.Hooks = append(s.Hooks, r.hookFailure(withRow("After Spec", spec, f), f, "../"))
+	}
+	for _, item := range spec.GetItems() {
+		switch item.GetItemType() {
+		case m.ProtoItem_Scenario:
+			s.Scenarios = append(s.Scenarios, r.scenario(item.GetScenario(), item.GetScenario().GetScenarioHeading()))
+		case m.ProtoItem_TableDrivenScenario:
+			tds := item.GetTableDrivenScenario()
+			s.Scenarios = append(s.Scenarios, r.scenario(tds.GetScenario(), tableDrivenScenarioName(tds)))
+		}
+	}
+	return s
+}
+
+func (r *htmlReport) scenario(scn *m.ProtoScenario, heading string) *htmlScenario {
+	s := &htmlScenario{
+		Heading:       heading,
+		Status:        scenarioStatus(scn),
+		Tags:          scn.GetTags(),
+		ExecutionTime: duration(scn.GetExecutionTime()),
+		SkipReasons:   scn.GetSkipErrors(),
+	}
+	if scn.GetRetriesCount() > 1 {
+		s.Attempts = scn.GetRetriesCount()
+	}
+	if f := scn.GetPreHookFailure(); f != nil {
+		s.Hooks = append(s.Hooks, r.hookFailure("Before Scenario", f, "../"))
+	}
+	if f := scn.GetPostHookFailure(); f != nil {
+		s.Hooks = append(s.Hooks, r.hookFailure("After Scenario", f, "../"))
+	}
+	for _, items := range [][]*m.ProtoItem{scn.GetContexts(), scn.GetScenarioItems(), scn.GetTearDownSteps()} {
+		s.Steps = append(s.Steps, r.steps(items, 0)...)
+	}
+	return s
+}
+
+// steps flattens the steps, with the steps of a concept following the concept at a greater depth.
+func (r *htmlReport) steps(items []*m.ProtoItem, depth int) []*htmlStep {
+	var steps []*htmlStep
+	for _, item := range items {
+		switch item.GetItemType() {
+		case m.ProtoItem_Step:
+			steps = append(steps, r.step(item.GetStep(), depth))
+		case m.ProtoItem_Concept:
+			c := item.GetConcept()
+			concept := &htmlStep{Text: c.GetConceptStep().GetActualText(), Status: stepStatus(c.GetConceptExecutionResult()), Depth: depth}
+			conceptSteps := r.steps(c.GetSteps(), depth+1)
+			if c.GetConceptExecutionResult() == nil {
+				concept.Status = conceptStatus(conceptSteps)
+			}
+			steps = append(steps, concept)
+			steps = append(steps, conceptSteps...)
+		}
+	}
+	return steps
+}
+
+func (r *htmlReport) step(step *m.ProtoStep, depth int) *htmlStep {
+	res := step.GetStepExecutionResult()
+	s := &htmlStep{
+		Text:       step.GetActualText(),
+		Status:     stepStatus(res),
+		Depth:      depth,
+		Error:      res.GetExecutionResult().GetErrorMessage(),
+		StackTrace: res.GetExecutionResult().GetStackTrace(),
+		Messages:   res.GetExecutionResult().GetMessage(),
+	}
+	if res.GetSkipped() && res.GetSkippedReason() != "" {
+		s.Error = res.GetSkippedReason()
+	}
+	files := append([]string{}, res.GetExecutionResult().GetScreenshotFiles()...)
+	if f := res.GetExecutionResult().GetFailureScreenshotFile(); f != "" {
+		files = append(files, f)
+	}
+	for _, f := range files {
+		if src := r.screenshot(f, "../"); src != "" {
+			s.Screenshots = append(s.Screenshots, src)
+		}
+	}
+	if f := res.GetPreHookFailure(); f != nil {
+		s.Hooks = append(s.Hooks, r.hookFailure("Before Step", f, "../"))
+	}
+	if f := res.GetPostHookFailure(); f != nil {
+		s.Hooks = append(s.Hooks, r.hookFailure("After Step", f, "../"))
+	}
+	return s
+}
+
+func (r *htmlReport) hookFailure(name string, f *m.ProtoHookFailure, pathToRoot string) *htmlHookFailure {
+	return &htmlHookFailure{Name: name, Error: f.GetErrorMessage(), StackTrace: f.GetStackTrace(), Screenshot: r.screenshot(f.GetFailureScreenshotFile(), pathToRoot)}
+}
+
+// screenshot copies the screenshot to the report and returns its path relative to the page.
+// An empty path is returned if the screenshot can not be copied.
+func (r *htmlReport) screenshot(file, pathToRoot string) string {
+	if file == "" {
+		return ""
+	}
+	if copied, ok := r.screenshots[file]; ok {
+		if copied == "" {
+			return ""
+		}
+		return pathToRoot + copied
+	}
+	r.screenshots[file] = ""
+	src := file
+	if !filepath.IsAbs(src) {
+		src = filepath.Join(r.imagesDir, file)
+	}
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		logger.Warningf(true, "Unable to add screenshot %s to the html report. %s", src, err.Error())
+		return ""
+	}
+	if err := os.MkdirAll(filepath.Join(r.dir, htmlImagesDir), common.NewDirectoryPermissions); err != nil {
+		logger.Warningf(true, "Unable to add screenshot %s to the html report. %s", src, err.Error())
+		return ""
+	}
+	dst := filepath.Join(r.dir, htmlImagesDir, filepath.Base(file))
+	if err := ioutil.WriteFile(dst, b, common.NewFilePermissions); err != nil {
+		logger.Warningf(true, "Unable to add screenshot %s to the html report. %s", src, err.Error())
+		return ""
+	}
+	r.screenshots[file] = htmlImagesDir + "/" + filepath.Base(file)
+	return pathToRoot + r.screenshots[file]
+}
+
+func specStatus(res *m.ProtoSpecResult) string {
+	if res.GetFailed() {
+		return failedStatus
+	}
+	if res.GetSkipped() {
+		return skippedStatus
+	}
+	return passedStatus
+}
+
+func scenarioStatus(scn *m.ProtoScenario) string {
+	switch scn.GetExecutionStatus() {
+	case m.ExecutionStatus_FAILED:
+		return failedStatus
+	case m.ExecutionStatus_SKIPPED:
+		return skippedStatus
+	}
+	return passedStatus
+}
+
+func stepStatus(res *m.ProtoStepExecutionResult) string {
+	if res.GetExecutionResult().GetFailed() || res.GetPreHookFailure() != nil || res.GetPostHookFailure() != nil {
+		return failedStatus
+	}
+	if res.GetSkipped() || res.GetExecutionResult() == nil {
+		return skippedStatus
+	}
+	return passedStatus
+}
+
+// conceptStatus derives the status of a concept without an execution result from the status of its steps.
+func conceptStatus(steps []*htmlStep) string {
+	status := skippedStatus
+	for _, s := range steps {
+		if s.Status == failedStatus {
+			return failedStatus
+		}
+		if s.Status == passedStatus {
+			status = passedStatus
+		}
+	}
+	return status
+}
+
+func duration(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+var htmlTemplates = template.Must(template.New("report").Funcs(template.FuncMap{
+	"indent": func(depth int) string { return fmt.Sprintf("%dem", depth*2) },
+}).Parse(`
+{{define "style"}}<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;margin:0;color:#222;background:#f6f7f9}
+header{background:#2f3b4c;color:#fff;padding:1em 2em}header a{color:#fff}
+main{padding:1em 2em}
+h1,h2,h3{margin:.3em 0}
+table{border-collapse:collapse;width:100%;background:#fff}
+th,td{text-align:left;padding:.5em;border-bottom:1px solid #e3e5e8}
+.passed{color:#2e7d32}.failed{color:#c62828}.skipped{color:#8a8a8a}
+.badge{display:inline-block;padding:.1em .6em;border-radius:1em;color:#fff;font-size:.85em}
+.badge.passed{background:#2e7d32}.badge.failed{background:#c62828}.badge.skipped{background:#8a8a8a}
+.summary span{margin-right:2em}
+.card{background:#fff;border:1px solid #e3e5e8;border-left:4px solid #2e7d32;margin:1em 0;padding:.8em 1em}
+.card.failed{border-left-color:#c62828}.card.skipped{border-left-color:#8a8a8a}
+.tags{color:#555;font-size:.85em}
+.step{padding:.3em 0}
+.error{color:#c62828;white-space:pre-wrap}
+pre{background:#f1f1f1;padding:.6em;overflow:auto;font-size:.85em}
+img{max-width:640px;border:1px solid #ccc;display:block;margin:.4em 0}
+</style>{{end}}
+
+{{define "hooks"}}{{range .}}<div class="card failed"><strong>{{.Name}} hook failed</strong>
+<div class="error">{{.Error}}</div>{{if .StackTrace}}<pre>{{.StackTrace}}</pre>{{end}}{{if .Screenshot}}<img src="{{.Screenshot}}" alt="Screenshot of {{.Name}} failure">{{end}}</div>
+{{end}}{{end}}
+
+{{define "index"}}<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8"><title>{{.ProjectName}} - Gauge report</title>{{template "style"}}</head>
+<body><header><h1>{{.ProjectName}}</h1>
+<div class="summary"><span>Environment: {{.Environment}}</span>{{if .Tags}}<span>Tags: {{.Tags}}</span>{{end}}<span>Executed: {{.Timestamp}}</span><span>Time: {{.ExecutionTime}}</span><span>Generated: {{.GeneratedAt}}</span></div></header>
+<main>
+<h2><span class="badge {{.Status}}">{{.Status}}</span> Success rate {{.SuccessRate}}</h2>
+<div class="summary"><span class="passed">{{.Passed}} passed</span><span class="failed">{{.Failed}} failed</span><span class="skipped">{{.Skipped}} skipped</span></div>
+{{template "hooks" .Hooks}}
+<table><thead><tr><th>Specification</th><th>Status</th><th>Scenarios</th><th>Failed</th><th>Skipped</th><th>Time</th></tr></thead><tbody>
+{{range .Specs}}<tr><td><a href="{{.Page}}">{{.Heading}}</a><div class="tags">{{.File}}</div></td><td class="{{.Status}}">{{.Status}}</td><td>{{.Scenarios}}</td><td>{{.Failed}}</td><td>{{.Skipped}}</td><td>{{.ExecutionTime}}</td></tr>
+{{end}}</tbody></table>
+</main></body></html>
+{{end}}
+
+{{define "spec"}}<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8"><title>{{.Heading}} - Gauge report</title>{{template "style"}}</head>
+<body><header><a href="../index.html">&larr; All specifications</a><h1>{{.Heading}}</h1>
+<div class="summary"><span>{{.File}}</span><span>Time: {{.ExecutionTime}}</span>{{if .Tags}}<span>Tags: {{range $i, $t := .Tags}}{{if $i}}, {{end}}{{$t}}{{end}}</span>{{end}}</div></header>
+<main>
+<h2><span class="badge {{.Status}}">{{.Status}}</span></h2>
+{{range .Errors}}<div class="card failed error">{{.}}</div>{{end}}
+{{template "hooks" .Hooks}}
+{{range .Scenarios}}<div class="card {{.Status}}">
+<h3>{{.Heading}} <span class="badge {{.Status}}">{{.Status}}</span></h3>
+<div class="tags">{{.ExecutionTime}}{{if .Attempts}} &middot; {{.Attempts}} attempts{{end}}{{if .Tags}} &middot; Tags: {{range $i, $t := .Tags}}{{if $i}}, {{end}}{{$t}}{{end}}{{end}}</div>
+{{range .SkipReasons}}<div class="skipped">{{.}}</div>{{end}}
+{{template "hooks" .Hooks}}
+{{range .Steps}}<div class="step" style="margin-left:{{indent .Depth}}"><span class="{{.Status}}">&#9679;</span> {{.Text}}
+{{if .Error}}<div class="error">{{.Error}}</div>{{end}}{{if .StackTrace}}<pre>{{.StackTrace}}</pre>{{end}}
+{{range .Messages}}<pre>{{.}}</pre>{{end}}{{range .Screenshots}}<img src="{{.}}" alt="Screenshot">{{end}}
+{{template "hooks" .Hooks}}</div>
+{{end}}</div>
+{{end}}
+</main></body></html>
+{{end}}
+`))
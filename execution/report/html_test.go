@@ -0,0 +1,83 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package report
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	m "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/env"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	screenshotsDir := filepath.Join(dir, "screenshots")
+	if err := os.MkdirAll(screenshotsDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(screenshotsDir, "failure.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := os.Getenv(env.GaugeScreenshotsDir)
+	os.Setenv(env.GaugeScreenshotsDir, screenshotsDir)
+	defer os.Setenv(env.GaugeScreenshotsDir, old)
+
+	res := sampleSuiteResult()
+	failing := res.SpecResults[0].ProtoSpec.Items[1].Scenario
+	step := failing.ScenarioItems[0].Concept.Steps[0].Step
+	step.StepExecutionResult.ExecutionResult.FailureScreenshotFile = "failure.png"
+	failing.ScenarioItems[0].Concept.ConceptStep = &m.ProtoStep{ActualText: "a <concept>"}
+	res.SpecResults[0].Failed = true
+	reportDir := filepath.Join(dir, "report")
+
+	if err := writeHTMLReport(res, reportDir); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	index, err := ioutil.ReadFile(filepath.Join(reportDir, htmlIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<h1>proj</h1>", `<a href="specs/1-example.html">Spec heading</a>`, "After Suite hook failed", "after suite failed"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("Expected index to contain %s, got\n%s", want, index)
+		}
+	}
+	spec, err := ioutil.ReadFile(filepath.Join(reportDir, htmlSpecsDir, "1-example.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Before Spec [spec row 2] hook failed", `<span class="failed">&#9679;</span> a &lt;concept&gt;`, `<div class="error">boom</div>`, "<pre>at foo.go:12</pre>",
+		`<img src="../images/failure.png" alt="Screenshot">`, "table [spec row 2]", "Step implementation not found", "2 attempts"} {
+		if !strings.Contains(string(spec), want) {
+			t.Errorf("Expected spec page to contain %s, got\n%s", want, spec)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(reportDir, htmlImagesDir, "failure.png")); err != nil {
+		t.Errorf("Expected screenshot to be copied to the report. %s", err.Error())
+	}
+}
+
+func TestHTMLStepStatus(t *testing.T) {
+	if got := stepStatus(&m.ProtoStepExecutionResult{ExecutionResult: &m.ProtoExecutionResult{}}); got != passedStatus {
+		t.Errorf("Expected passed, got %s", got)
+	}
+	if got := stepStatus(&m.ProtoStepExecutionResult{ExecutionResult: &m.ProtoExecutionResult{}, PostHookFailure: &m.ProtoHookFailure{}}); got != failedStatus {
+		t.Errorf("Expected a step with a hook failure to fail, got %s", got)
+	}
+	if got := stepStatus(nil); got != skippedStatus {
+		t.Errorf("Expected a step that was not executed to be skipped, got %s", got)
+	}
+}
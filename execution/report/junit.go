@@ -0,0 +1,307 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/getgauge/common"
+	m "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/util"
+)
+
+const (
+	junitFormat     = "junit"
+	junitReportFile = "result.xml"
+	suiteHooksName  = "Suite Hooks"
+	hookFailureType = "HookFailure"
+	stepFailureType = "StepFailure"
+	specErrorType   = "SpecificationError"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	ID         int             `xml:"id,attr"`
+	Name       string          `xml:"name,attr"`
+	Package    string          `xml:"package,attr,omitempty"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name       string          `xml:"name,attr"`
+	Classname  string          `xml:"classname,attr"`
+	File       string          `xml:"file,attr,omitempty"`
+	Line       int             `xml:"line,attr,omitempty"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Failure    *junitFailure   `xml:"failure,omitempty"`
+	Error      *junitFailure   `xml:"error,omitempty"`
+	Skipped    *junitSkipped   `xml:"skipped,omitempty"`
+	SystemOut  string          `xml:"system-out,omitempty"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+func writeJUnitReport(res *m.ProtoSuiteResult, dir string) error {
+	b, err := junitXML(res)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, junitReportFile), b, common.NewFilePermissions)
+}
+
+func junitXML(res *m.ProtoSuiteResult) ([]byte, error) {
+	b, err := xml.MarshalIndent(toJUnitTestSuites(res), "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+func toJUnitTestSuites(res *m.ProtoSuiteResult) *junitTestSuites {
+	suites := &junitTestSuites{Name: res.GetProjectName(), Time: seconds(res.GetExecutionTime())}
+	if hooks := suiteHookTestSuite(res); hooks != nil {
+		suites.Suites = append(suites.Suites, *hooks)
+	}
+	for _, specRes := range res.GetSpecResults() {
+		suites.Suites = append(suites.Suites, toJUnitTestSuite(specRes))
+	}
+	for i := range suites.Suites {
+		s := &suites.Suites[i]
+		s.ID = i
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Errors += s.Errors
+		suites.Skipped += s.Skipped
+	}
+	return suites
+}
+
+func suiteHookTestSuite(res *m.ProtoSuiteResult) *junitTestSuite {
+	if res.GetPreHookFailure() == nil && res.GetPostHookFailure() == nil {
+		return nil
+	}
+	ts := &junitTestSuite{Name: suiteHooksName, Time: seconds(0), Timestamp: res.GetTimestamp()}
+	if f := res.GetPreHookFailure(); f != nil {
+		ts.TestCases = append(ts.TestCases, hookTestCase("Before Suite", suiteHooksName, "", f))
+	}
+	if f := res.GetPostHookFailure(); f != nil {
+		ts.TestCases = append(ts.TestCases, hookTestCase("After Suite", suiteHooksName, "", f))
+	}
+	updateCounts(ts)
+	return ts
+}
+
+func toJUnitTestSuite(res *m.ProtoSpecResult) junitTestSuite {
+	spec := res.GetProtoSpec()
+	file := util.RelPathToProjectRoot(spec.GetFileName())
+	ts := junitTestSuite{
+		Name:      spec.GetSpecHeading(),
+		Package:   file,
+		Time:      seconds(res.GetExecutionTime()),
+		Timestamp: res.GetTimestamp(),
+	}
+	for _, t := range spec.GetTags() {
+		ts.Properties = append(ts.Properties, junitProperty{Name: "tag", Value: t})
+	}
+	if tc := specErrorTestCase(res, file); tc != nil {
+		ts.TestCases = append(ts.TestCases, *tc)
+	}
+	for _, f := range spec.GetPreHookFailures() {
+		ts.TestCases = append(ts.TestCases, hookTestCase(withRow("Before Spec", spec, f), spec.GetSpecHeading(), file, f))
+	}
+	for _, item := range spec.GetItems() {
+		switch item.GetItemType() {
+		case m.ProtoItem_Scenario:
+			ts.TestCases = append(ts.TestCases, scenarioTestCase(item.GetScenario(), item.GetScenario().GetScenarioHeading(), spec.GetSpecHeading(), file))
+		case m.ProtoItem_TableDrivenScenario:
+			tds := item.GetTableDrivenScenario()
+			ts.TestCases = append(ts.TestCases, scenarioTestCase(tds.GetScenario(), tableDrivenScenarioName(tds), spec.GetSpecHeading(), file))
+		}
+	}
+	for _, f := range spec.GetPostHookFailures() {
+		ts.TestCases = append(ts.TestCases, hookTestCase(withRow("After Spec", spec, f), spec.GetSpecHeading(), file, f))
+	}
+	updateCounts(&ts)
+	return ts
+}
+
+func updateCounts(ts *junitTestSuite) {
+	ts.Tests = len(ts.TestCases)
+	for _, tc := range ts.TestCases {
+		if tc.Failure != nil {
+			ts.Failures++
+		}
+		if tc.Error != nil {
+			ts.Errors++
+		}
+		if tc.Skipped != nil {
+			ts.Skipped++
+		}
+	}
+}
+
+func specErrorTestCase(res *m.ProtoSpecResult, file string) *junitTestCase {
+	var msgs []string
+	for _, e := range res.GetErrors() {
+		if e.GetType() == m.Error_PARSE_ERROR {
+			msgs = append(msgs, fmt.Sprintf("%s:%d %s", e.GetFilename(), e.GetLineNumber(), e.GetMessage()))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return &junitTestCase{
+		Name:      "Specification Errors",
+		Classname: res.GetProtoSpec().GetSpecHeading(),
+		File:      file,
+		Time:      seconds(0),
+		Error:     &junitFailure{Message: msgs[0], Type: specErrorType, Contents: strings.Join(msgs, "\n")},
+	}
+}
+
+func hookTestCase(name, classname, file string, f *m.ProtoHookFailure) junitTestCase {
+	return junitTestCase{
+		Name:      name,
+		Classname: classname,
+		File:      file,
+		Time:      seconds(0),
+		Error:     &junitFailure{Message: f.GetErrorMessage(), Type: hookFailureType, Contents: f.GetStackTrace()},
+	}
+}
+
+func withRow(name string, spec *m.ProtoSpec, f *m.ProtoHookFailure) string {
+	if spec.GetIsTableDriven() && f.GetTableRowIndex() >= 0 {
+		return result.WithTableRows(name, result.TableRows(int(f.GetTableRowIndex())+1, 0))
+	}
+	return name
+}
+
+func tableDrivenScenarioName(tds *m.ProtoTableDrivenScenario) string {
+	return result.WithTableRows(tds.GetScenario().GetScenarioHeading(), result.TableRow(tds))
+}
+
+func scenarioTestCase(scn *m.ProtoScenario, name, classname, file string) junitTestCase {
+	tc := junitTestCase{
+		Name:      name,
+		Classname: classname,
+		File:      file,
+		Line:      int(scn.GetSpan().GetStart()),
+		Time:      seconds(scn.GetExecutionTime()),
+		SystemOut: strings.Join(scenarioMessages(scn), "\n"),
+	}
+	for _, t := range scn.GetTags() {
+		tc.Properties = append(tc.Properties, junitProperty{Name: "tag", Value: t})
+	}
+	if scn.GetRetriesCount() > 1 {
+		tc.Properties = append(tc.Properties, junitProperty{Name: "attempts", Value: strconv.FormatInt(scn.GetRetriesCount(), 10)})
+	}
+	switch scn.GetExecutionStatus() {
+	case m.ExecutionStatus_SKIPPED:
+		tc.Skipped = &junitSkipped{Message: strings.Join(scn.GetSkipErrors(), "\n")}
+	case m.ExecutionStatus_FAILED:
+		tc.Failure = scenarioFailure(scn)
+	}
+	return tc
+}
+
+func scenarioFailure(scn *m.ProtoScenario) *junitFailure {
+	if f := scn.GetPreHookFailure(); f != nil {
+		return &junitFailure{Message: "Before Scenario hook failed: " + f.GetErrorMessage(), Type: hookFailureType, Contents: f.GetStackTrace()}
+	}
+	for _, items := range [][]*m.ProtoItem{scn.GetContexts(), scn.GetScenarioItems(), scn.GetTearDownSteps()} {
+		if step := failedStep(items); step != nil {
+			res := step.GetStepExecutionResult().GetExecutionResult()
+			return &junitFailure{
+				Message:  fmt.Sprintf("Step '%s' failed: %s", step.GetActualText(), res.GetErrorMessage()),
+				Type:     stepFailureType,
+				Contents: res.GetStackTrace(),
+			}
+		}
+	}
+	if f := scn.GetPostHookFailure(); f != nil {
+		return &junitFailure{Message: "After Scenario hook failed: " + f.GetErrorMessage(), Type: hookFailureType, Contents: f.GetStackTrace()}
+	}
+	return &junitFailure{Message: "Scenario failed", Type: stepFailureType}
+}
+
+func failedStep(items []*m.ProtoItem) *m.ProtoStep {
+	for _, item := range items {
+		switch item.GetItemType() {
+		case m.ProtoItem_Step:
+			if item.GetStep().GetStepExecutionResult().GetExecutionResult().GetFailed() {
+				return item.GetStep()
+			}
+		case m.ProtoItem_Concept:
+			if s := failedStep(item.GetConcept().GetSteps()); s != nil {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+func scenarioMessages(scn *m.ProtoScenario) []string {
+	msgs := append([]string{}, scn.GetPreHookMessages()...)
+	for _, items := range [][]*m.ProtoItem{scn.GetContexts(), scn.GetScenarioItems(), scn.GetTearDownSteps()} {
+		msgs = append(msgs, stepMessages(items)...)
+	}
+	return append(msgs, scn.GetPostHookMessages()...)
+}
+
+func stepMessages(items []*m.ProtoItem) []string {
+	var msgs []string
+	for _, item := range items {
+		switch item.GetItemType() {
+		case m.ProtoItem_Step:
+			msgs = append(msgs, item.GetStep().GetStepExecutionResult().GetExecutionResult().GetMessage()...)
+		case m.ProtoItem_Concept:
+			msgs = append(msgs, stepMessages(item.GetConcept().GetSteps())...)
+		}
+	}
+	return msgs
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
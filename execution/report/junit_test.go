@@ -0,0 +1,121 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package report
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	m "github.com/getgauge/gauge-proto/go/gauge_messages"
+)
+
+func failedStepItem(text, err, stack string) *m.ProtoItem {
+	return &m.ProtoItem{ItemType: m.ProtoItem_Step, Step: &m.ProtoStep{ActualText: text, StepExecutionResult: &m.ProtoStepExecutionResult{
+		ExecutionResult: &m.ProtoExecutionResult{Failed: true, ErrorMessage: err, StackTrace: stack, Message: []string{"a message"}},
+	}}}
+}
+
+func sampleSuiteResult() *m.ProtoSuiteResult {
+	passed := &m.ProtoScenario{ScenarioHeading: "passing", ExecutionStatus: m.ExecutionStatus_PASSED, ExecutionTime: 1500, Span: &m.Span{Start: 4}}
+	failed := &m.ProtoScenario{ScenarioHeading: "failing", ExecutionStatus: m.ExecutionStatus_FAILED, RetriesCount: 2,
+		ScenarioItems: []*m.ProtoItem{{ItemType: m.ProtoItem_Concept, Concept: &m.ProtoConcept{Steps: []*m.ProtoItem{failedStepItem("step one", "boom", "at foo.go:12")}}}}}
+	skipped := &m.ProtoScenario{ScenarioHeading: "skipped", ExecutionStatus: m.ExecutionStatus_SKIPPED, SkipErrors: []string{"Step implementation not found"}}
+	row := &m.ProtoScenario{ScenarioHeading: "table", ExecutionStatus: m.ExecutionStatus_PASSED}
+	return &m.ProtoSuiteResult{
+		ProjectName:     "proj",
+		ExecutionTime:   2500,
+		PostHookFailure: &m.ProtoHookFailure{ErrorMessage: "after suite failed", TableRowIndex: -1},
+		SpecResults: []*m.ProtoSpecResult{{
+			ExecutionTime: 2500,
+			ProtoSpec: &m.ProtoSpec{
+				SpecHeading:     "Spec heading",
+				FileName:        "specs/example.spec",
+				Tags:            []string{"smoke"},
+				IsTableDriven:   true,
+				PreHookFailures: []*m.ProtoHookFailure{{ErrorMessage: "before spec failed", TableRowIndex: 1}},
+				Items: []*m.ProtoItem{
+					{ItemType: m.ProtoItem_Scenario, Scenario: passed},
+					{ItemType: m.ProtoItem_Scenario, Scenario: failed},
+					{ItemType: m.ProtoItem_Scenario, Scenario: skipped},
+					{ItemType: m.ProtoItem_TableDrivenScenario, TableDrivenScenario: &m.ProtoTableDrivenScenario{Scenario: row, TableRowIndex: 1, IsSpecTableDriven: true}},
+				},
+			},
+		}},
+	}
+}
+
+func TestJUnitTestSuitesMapsSpecsAndScenarios(t *testing.T) {
+	suites := toJUnitTestSuites(sampleSuiteResult())
+
+	if len(suites.Suites) != 2 {
+		t.Fatalf("Expected 2 testsuites, got %d", len(suites.Suites))
+	}
+	if suites.Suites[0].Name != suiteHooksName || suites.Suites[0].Errors != 1 {
+		t.Errorf("Expected suite hook failure to be reported as an error, got %+v", suites.Suites[0])
+	}
+	spec := suites.Suites[1]
+	if spec.Name != "Spec heading" || spec.Tests != 5 || spec.Failures != 1 || spec.Errors != 1 || spec.Skipped != 1 {
+		t.Errorf("Unexpected spec testsuite %+v", spec)
+	}
+	if suites.Tests != 6 || suites.Failures != 1 || suites.Errors != 2 || suites.Skipped != 1 {
+		t.Errorf("Unexpected totals %+v", suites)
+	}
+	if spec.TestCases[0].Name != "Before Spec [spec row 2]" {
+		t.Errorf("Expected spec hook failure testcase, got %s", spec.TestCases[0].Name)
+	}
+	if spec.TestCases[1].Time != "1.500" || spec.TestCases[1].Line != 4 {
+		t.Errorf("Unexpected passing testcase %+v", spec.TestCases[1])
+	}
+	f := spec.TestCases[2].Failure
+	if f == nil || f.Message != "Step 'step one' failed: boom" || f.Contents != "at foo.go:12" {
+		t.Errorf("Unexpected failure %+v", f)
+	}
+	if spec.TestCases[2].Properties[0].Name != "attempts" || spec.TestCases[2].Properties[0].Value != "2" {
+		t.Errorf("Expected attempts to be recorded, got %+v", spec.TestCases[2].Properties)
+	}
+	if spec.TestCases[3].Skipped.Message != "Step implementation not found" {
+		t.Errorf("Unexpected skipped reason %+v", spec.TestCases[3].Skipped)
+	}
+	if spec.TestCases[4].Name != "table [spec row 2]" {
+		t.Errorf("Unexpected table driven scenario name %s", spec.TestCases[4].Name)
+	}
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "junit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeJUnitReport(sampleSuiteResult(), dir); err != nil {
+		t.Fatalf("Expected no error, got %s", err.Error())
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, junitReportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{`<?xml version="1.0" encoding="UTF-8"?>`, `<testsuites name="proj" tests="6" failures="1" errors="2" skipped="1" time="2.500">`, `<system-out>a message</system-out>`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected report to contain %s, got\n%s", want, got)
+		}
+	}
+}
+
+func TestValidateReportFormats(t *testing.T) {
+	if err := Validate([]string{"JUnit", "html"}); err != nil {
+		t.Errorf("Expected junit and html to be valid formats, got %s", err.Error())
+	}
+	if err := Validate([]string{"pdf"}); err == nil {
+		t.Error("Expected pdf to be an invalid format")
+	}
+}
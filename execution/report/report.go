@@ -0,0 +1,125 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+// Package report generates execution reports in-process, without starting a reporting plugin.
+// Formats are selected using the --report flag or the native_reports property and the reports
+// are written to gauge_reports_dir once the suite execution ends.
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getgauge/common"
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+)
+
+const timestampedDirLayout = "2006-01-02 15.04.05"
+
+// Formats holds the report formats passed via the --report flag
+var Formats []string
+
+type writer func(res *gauge_messages.ProtoSuiteResult, dir string) error
+
+var writers = map[string]writer{
+	junitFormat: writeJUnitReport,
+	htmlFormat:  writeHTMLReport,
+}
+
+// ConfiguredFormats returns the report formats to be generated, the --report flag takes precedence over the native_reports property.
+func ConfiguredFormats() []string {
+	if len(Formats) > 0 {
+		return Formats
+	}
+	return env.NativeReportFormats()
+}
+
+// Validate checks that a report writer exists for each of the given formats
+func Validate(formats []string) error {
+	for _, f := range formats {
+		if _, ok := writers[strings.ToLower(strings.TrimSpace(f))]; !ok {
+			return fmt.Errorf("invalid report format '%s'. Supported formats are: %s", f, strings.Join(SupportedFormats(), ", "))
+		}
+	}
+	return nil
+}
+
+// SupportedFormats lists the report formats that gauge can generate natively
+func SupportedFormats() []string {
+	var formats []string
+	for f := range writers {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ListenSuiteEndAndWriteReports listens to the suite end event and writes the reports in the given formats
+func ListenSuiteEndAndWriteReports(wg *sync.WaitGroup, formats []string) {
+	ch := make(chan event.ExecutionEvent)
+	event.Register(ch, event.SuiteEnd)
+	wg.Add(1)
+
+	go func() {
+		for {
+			e := <-ch
+			if e.Topic == event.SuiteEnd {
+				Write(gauge.ConvertToProtoSuiteResult(e.Result.(*result.SuiteResult)), formats)
+				wg.Done()
+				return
+			}
+		}
+	}()
+}
+
+// Write generates the reports for the given suite result in each of the given formats
+func Write(res *gauge_messages.ProtoSuiteResult, formats []string) {
+	now := time.Now()
+	for _, f := range formats {
+		f = strings.ToLower(strings.TrimSpace(f))
+		w, ok := writers[f]
+		if !ok {
+			logger.Errorf(true, "Unable to generate report. Unsupported report format '%s'.", f)
+			continue
+		}
+		dir := reportDir(f, now)
+		if err := os.MkdirAll(dir, common.NewDirectoryPermissions); err != nil {
+			logger.Errorf(true, "Failed to create directory in %s. Reason: %s", dir, err.Error())
+			continue
+		}
+		if err := w(res, dir); err != nil {
+			logger.Errorf(true, "Failed to generate %s report. Reason: %s", f, err.Error())
+			continue
+		}
+		logger.Infof(true, "Successfully generated %s report to => %s", f, dir)
+	}
+}
+
+func reportDir(format string, t time.Time) string {
+	dir := os.Getenv(env.GaugeReportsDir)
+	if dir == "" {
+		dir = "reports"
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(config.ProjectRoot, dir)
+	}
+	dir = filepath.Join(dir, format)
+	if !env.ShouldOverwriteReports() {
+		dir = filepath.Join(dir, t.Format(timestampedDirLayout))
+	}
+	return dir
+}
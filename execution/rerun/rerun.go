@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 
 	"github.com/getgauge/common"
@@ -92,6 +93,7 @@ func ListenFailedScenarios(wg *sync.WaitGroup, specDirs []string) {
 				failedMeta.aggregateFailedItems()
 				writeFailedMeta(getJSON(failedMeta))
 				wg.Done()
+				return
 			}
 		}
 	}()
@@ -113,13 +115,45 @@ func addSpecFailedMetadata(res result.Result, args []string) {
 
 func addSuiteFailedMetadata(res result.Result, args []string) {
 	failedMeta.failedItemsMap = make(map[string]map[string]bool)
+	failedMeta.addSpecDirs(args)
+}
+
+func (m *failedMetadata) addSpecDirs(args []string) {
 	for _, arg := range args {
 		path, err := filepath.Abs(arg)
 		path = util.RelPathToProjectRoot(path)
 		if err == nil {
-			failedMeta.addFailedItem(path, path)
+			m.addFailedItem(path, path)
+		}
+	}
+}
+
+// SaveFailures writes the specs and scenarios which failed in the given suite result, for them to be executed with --failed.
+// The specs are written relative to the project directory of the result, which may come from another checkout of the project.
+// The whole of the spec dirs is to be executed again if a suite hook failed, and the whole spec if a spec hook failed.
+func SaveFailures(res *gauge_messages.ProtoSuiteResult, specDirs []string) {
+	meta := newFailedMetaData()
+	if res.GetPreHookFailure() != nil || res.GetPostHookFailure() != nil {
+		meta.addSpecDirs(specDirs)
+	} else {
+		specPath := result.SpecPaths(res)
+		for _, specRes := range res.GetSpecResults() {
+			spec := specRes.GetProtoSpec()
+			fileName := filepath.FromSlash(specPath(spec.GetFileName()))
+			if len(spec.GetPreHookFailures()) > 0 || len(spec.GetPostHookFailures()) > 0 {
+				meta.addFailedItem(fileName, fileName)
+				continue
+			}
+			for _, scn := range result.Scenarios(spec) {
+				if scn.GetExecutionStatus() == gauge_messages.ExecutionStatus_FAILED {
+					meta.addFailedItem(fileName, fmt.Sprintf("%s:%v", fileName, scn.GetSpan().GetStart()))
+				}
+			}
 		}
 	}
+	meta.aggregateFailedItems()
+	sort.Strings(meta.FailedItems)
+	writeFailedMeta(getJSON(meta))
 }
 
 func addFailedMetadata(res result.Result, args []string, add func(res result.Result, args []string)) {
@@ -214,3 +248,18 @@ var WritePrevArgs = func(cmdArgs []string) {
 		logger.Fatalf(true, "Failed to write to %s. Reason: %s", prevCmdFile, err.Error())
 	}
 }
+
+// LastFailedItems returns the specs and scenarios which failed in the previous run, as `<spec file>` or `<spec file>:<scenario line>`
+// relative to the project root. It returns nil if there is no information about the previous run.
+func LastFailedItems() []string {
+	contents, err := common.ReadFileContents(filepath.Join(config.ProjectRoot, common.DotGauge, failedFile))
+	if err != nil {
+		return nil
+	}
+	meta := newFailedMetaData()
+	if err = json.Unmarshal([]byte(contents), meta); err != nil {
+		logger.Warningf(true, "Invalid last run information. Reason: %s", err.Error())
+		return nil
+	}
+	return meta.FailedItems
+}
@@ -123,3 +123,37 @@ func (s *MySuite) TestGetAllFailedItems(c *C) {
 
 	c.Assert(failedItems, DeepEquals, []string{"scn1", "scn2", "scn3"})
 }
+
+func (s *MySuite) TestSaveFailuresOfASuiteResult(c *C) {
+	spec1Rel := filepath.Join("specs", "example1.spec")
+	spec2Rel := filepath.Join("specs", "example2.spec")
+	scenario := func(status gauge_messages.ExecutionStatus, line int64) *gauge_messages.ProtoItem {
+		return &gauge_messages.ProtoItem{ItemType: gauge_messages.ProtoItem_Scenario, Scenario: &gauge_messages.ProtoScenario{ExecutionStatus: status, Span: &gauge_messages.Span{Start: line}}}
+	}
+	res := &gauge_messages.ProtoSuiteResult{SpecResults: []*gauge_messages.ProtoSpecResult{
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: filepath.Join(config.ProjectRoot, spec1Rel), Items: []*gauge_messages.ProtoItem{
+			scenario(gauge_messages.ExecutionStatus_PASSED, 3),
+			scenario(gauge_messages.ExecutionStatus_FAILED, 8),
+		}}},
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: filepath.Join(config.ProjectRoot, spec2Rel), PreHookFailures: []*gauge_messages.ProtoHookFailure{{}}}},
+	}}
+	defer os.RemoveAll(filepath.Join(config.ProjectRoot, common.DotGauge))
+
+	SaveFailures(res, []string{"specs"})
+
+	c.Assert(LastFailedItems(), DeepEquals, []string{spec1Rel + ":8", spec2Rel})
+}
+
+func (s *MySuite) TestSaveFailuresOfASuiteResultFromAnotherWorkspace(c *C) {
+	res := &gauge_messages.ProtoSuiteResult{ProjectName: "shop", SpecResults: []*gauge_messages.ProtoSpecResult{
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "/ci/workspace-1/shop/specs/login/login.spec", Items: []*gauge_messages.ProtoItem{
+			{ItemType: gauge_messages.ProtoItem_Scenario, Scenario: &gauge_messages.ProtoScenario{ExecutionStatus: gauge_messages.ExecutionStatus_FAILED, Span: &gauge_messages.Span{Start: 5}}},
+		}}},
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "/ci/workspace-1/shop/specs/cart.spec"}},
+	}}
+	defer os.RemoveAll(filepath.Join(config.ProjectRoot, common.DotGauge))
+
+	SaveFailures(res, []string{"specs"})
+
+	c.Assert(LastFailedItems(), DeepEquals, []string{filepath.Join("specs", "login", "login.spec") + ":5"})
+}
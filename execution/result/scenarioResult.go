@@ -7,6 +7,9 @@
 package result
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/getgauge/gauge-proto/go/gauge_messages"
 )
 
@@ -27,6 +30,64 @@ func (s ScenarioResult) SetFailure() {
 	s.ProtoScenario.Failed = true
 }
 
+// IsFlaky returns true if the scenario passed only after being retried
+func (s ScenarioResult) IsFlaky() bool {
+	return IsFlaky(s.ProtoScenario)
+}
+
+// IsFlaky returns true if the given scenario passed only after being retried
+func IsFlaky(scn *gauge_messages.ProtoScenario) bool {
+	return scn.GetExecutionStatus() == gauge_messages.ExecutionStatus_PASSED && scn.GetRetriesCount() > 1
+}
+
+// Scenarios returns all the scenarios in the spec result, including the runs of table driven scenarios
+func Scenarios(spec *gauge_messages.ProtoSpec) []*gauge_messages.ProtoScenario {
+	var scenarios []*gauge_messages.ProtoScenario
+	for _, item := range spec.GetItems() {
+		switch item.GetItemType() {
+		case gauge_messages.ProtoItem_Scenario:
+			scenarios = append(scenarios, item.GetScenario())
+		case gauge_messages.ProtoItem_TableDrivenScenario:
+			scenarios = append(scenarios, item.GetTableDrivenScenario().GetScenario())
+		}
+	}
+	return scenarios
+}
+
+// TableRow describes the data table rows a run of a table driven scenario was executed for, e.g. "spec row 2, scenario row 1"
+func TableRow(tds *gauge_messages.ProtoTableDrivenScenario) string {
+	var specRow, scenarioRow int
+	if tds.GetIsSpecTableDriven() || !tds.GetIsScenarioTableDriven() {
+		specRow = int(tds.GetTableRowIndex()) + 1
+	}
+	if tds.GetIsScenarioTableDriven() {
+		scenarioRow = int(tds.GetScenarioTableRowIndex()) + 1
+	}
+	return TableRows(specRow, scenarioRow)
+}
+
+// TableRows describes the given rows of the spec and the scenario data tables, e.g. "spec row 2, scenario row 1".
+// The rows are numbered from 1, 0 being no row of the table.
+func TableRows(specRow, scenarioRow int) string {
+	var rows []string
+	if specRow > 0 {
+		rows = append(rows, fmt.Sprintf("spec row %d", specRow))
+	}
+	if scenarioRow > 0 {
+		rows = append(rows, fmt.Sprintf("scenario row %d", scenarioRow))
+	}
+	return strings.Join(rows, ", ")
+}
+
+// WithTableRows adds the data table rows to the name of a scenario or a hook, e.g. "Login [spec row 2]", which tells apart
+// its runs for the rows in the reports.
+func WithTableRows(name, rows string) string {
+	if rows == "" {
+		return name
+	}
+	return fmt.Sprintf("%s [%s]", name, rows)
+}
+
 // GetFailed returns the state of the scenario result
 func (s ScenarioResult) GetFailed() bool {
 	return s.ProtoScenario.GetExecutionStatus() == gauge_messages.ExecutionStatus_FAILED
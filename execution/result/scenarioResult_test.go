@@ -0,0 +1,58 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package result
+
+import (
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	gc "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestScenarioPassingAfterRetryIsFlaky(c *gc.C) {
+	res := ScenarioResult{ProtoScenario: &gauge_messages.ProtoScenario{ExecutionStatus: gauge_messages.ExecutionStatus_PASSED, RetriesCount: 3}}
+
+	c.Assert(res.IsFlaky(), gc.Equals, true)
+}
+
+func (s *MySuite) TestScenarioPassingInFirstAttemptIsNotFlaky(c *gc.C) {
+	res := ScenarioResult{ProtoScenario: &gauge_messages.ProtoScenario{ExecutionStatus: gauge_messages.ExecutionStatus_PASSED, RetriesCount: 1}}
+
+	c.Assert(res.IsFlaky(), gc.Equals, false)
+}
+
+func (s *MySuite) TestScenarioFailingAfterRetryIsNotFlaky(c *gc.C) {
+	res := ScenarioResult{ProtoScenario: &gauge_messages.ProtoScenario{ExecutionStatus: gauge_messages.ExecutionStatus_FAILED, RetriesCount: 3}}
+
+	c.Assert(res.IsFlaky(), gc.Equals, false)
+}
+
+func (s *MySuite) TestScenariosIncludesTableDrivenScenarios(c *gc.C) {
+	scn1 := &gauge_messages.ProtoScenario{ScenarioHeading: "first"}
+	scn2 := &gauge_messages.ProtoScenario{ScenarioHeading: "second"}
+	spec := &gauge_messages.ProtoSpec{Items: []*gauge_messages.ProtoItem{
+		{ItemType: gauge_messages.ProtoItem_Comment, Comment: &gauge_messages.ProtoComment{Text: "comment"}},
+		{ItemType: gauge_messages.ProtoItem_Scenario, Scenario: scn1},
+		{ItemType: gauge_messages.ProtoItem_TableDrivenScenario, TableDrivenScenario: &gauge_messages.ProtoTableDrivenScenario{Scenario: scn2}},
+	}}
+
+	c.Assert(Scenarios(spec), gc.DeepEquals, []*gauge_messages.ProtoScenario{scn1, scn2})
+}
+
+func (s *MySuite) TestTableRowOfTableDrivenScenario(c *gc.C) {
+	specRow := &gauge_messages.ProtoTableDrivenScenario{TableRowIndex: 1}
+	scenarioRow := &gauge_messages.ProtoTableDrivenScenario{IsScenarioTableDriven: true, ScenarioTableRowIndex: 2}
+	bothRows := &gauge_messages.ProtoTableDrivenScenario{IsSpecTableDriven: true, IsScenarioTableDriven: true, ScenarioTableRowIndex: 2}
+
+	c.Assert(TableRow(specRow), gc.Equals, "spec row 2")
+	c.Assert(TableRow(scenarioRow), gc.Equals, "scenario row 3")
+	c.Assert(TableRow(bothRows), gc.Equals, "spec row 1, scenario row 3")
+}
+
+func (s *MySuite) TestNameWithTableRows(c *gc.C) {
+	c.Assert(WithTableRows("Vowel counts", TableRows(2, 1)), gc.Equals, "Vowel counts [spec row 2, scenario row 1]")
+	c.Assert(WithTableRows("Vowel counts", TableRows(0, 3)), gc.Equals, "Vowel counts [scenario row 3]")
+	c.Assert(WithTableRows("Vowel counts", TableRows(0, 0)), gc.Equals, "Vowel counts")
+}
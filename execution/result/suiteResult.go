@@ -7,12 +7,15 @@
 package result
 
 import (
+	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/getgauge/gauge-proto/go/gauge_messages"
 	"github.com/getgauge/gauge/config"
 	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/util"
 )
 
 // SuitResult represents the result of suit execution
@@ -35,6 +38,8 @@ type SuiteResult struct {
 	PostHookScreenshotFiles []string
 	PreHookScreenshots      [][]byte
 	PostHookScreenshots     [][]byte
+	// Interrupted is set if the execution was stopped by a signal before all the specs were executed
+	Interrupted bool
 }
 
 // NewSuiteResult is a constructor for SuitResult
@@ -124,3 +129,44 @@ func (sr *SuiteResult) GetFailed() bool {
 func (sr *SuiteResult) Item() interface{} {
 	return nil
 }
+
+// SpecPaths returns a function giving the slash separated path of a spec file of the suite result relative to the root of the
+// project it was executed in, so that the results of different checkouts of the project, e.g. in different CI workspaces or on
+// different platforms, can be matched. The root is the innermost directory named after the project that holds all the specs.
+// The paths of a result without such a directory are relative to the current project root.
+func SpecPaths(res *gauge_messages.ProtoSuiteResult) func(file string) string {
+	root := projectDir(res)
+	return func(file string) string {
+		p := slashPath(file)
+		if root != "" && strings.HasPrefix(p, root+"/") {
+			return strings.TrimPrefix(p, root+"/")
+		}
+		return slashPath(util.RelPathToProjectRoot(file))
+	}
+}
+
+// projectDir returns the innermost directory named after the project of the suite result holding all its specs, if any.
+func projectDir(res *gauge_messages.ProtoSuiteResult) string {
+	if res.GetProjectName() == "" || len(res.GetSpecResults()) == 0 {
+		return ""
+	}
+	dir := path.Dir(slashPath(res.GetSpecResults()[0].GetProtoSpec().GetFileName()))
+	for _, specRes := range res.GetSpecResults()[1:] {
+		p := slashPath(specRes.GetProtoSpec().GetFileName())
+		for dir != "." && dir != "/" && !strings.HasPrefix(p, dir+"/") {
+			dir = path.Dir(dir)
+		}
+	}
+	parts := strings.Split(dir, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == res.GetProjectName() {
+			return strings.Join(parts[:i+1], "/")
+		}
+	}
+	return ""
+}
+
+// slashPath replaces the separators of a path with slashes, also for the paths recorded on another platform.
+func slashPath(p string) string {
+	return strings.Replace(filepath.ToSlash(p), "\\", "/", -1)
+}
@@ -0,0 +1,39 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package result
+
+import (
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	gc "gopkg.in/check.v1"
+)
+
+func suiteResultOf(project string, files ...string) *gauge_messages.ProtoSuiteResult {
+	res := &gauge_messages.ProtoSuiteResult{ProjectName: project}
+	for _, f := range files {
+		res.SpecResults = append(res.SpecResults, &gauge_messages.ProtoSpecResult{ProtoSpec: &gauge_messages.ProtoSpec{FileName: f}})
+	}
+	return res
+}
+
+func (s *MySuite) TestSpecPathsAreRelativeToTheProjectDirectory(c *gc.C) {
+	path := SpecPaths(suiteResultOf("shop", "/ci/shop/build-1/shop/specs/login.spec", "/ci/shop/build-1/shop/specs/cart/cart.spec"))
+
+	c.Assert(path("/ci/shop/build-1/shop/specs/login.spec"), gc.Equals, "specs/login.spec")
+	c.Assert(path("/ci/shop/build-1/shop/specs/cart/cart.spec"), gc.Equals, "specs/cart/cart.spec")
+}
+
+func (s *MySuite) TestSpecPathsOfAResultRecordedOnWindows(c *gc.C) {
+	path := SpecPaths(suiteResultOf("shop", `C:\ci\shop\specs\login.spec`, `C:\ci\shop\specs\cart.spec`))
+
+	c.Assert(path(`C:\ci\shop\specs\login.spec`), gc.Equals, "specs/login.spec")
+}
+
+func (s *MySuite) TestSpecPathsWithoutTheProjectDirectoryAreKept(c *gc.C) {
+	path := SpecPaths(suiteResultOf("shop", "specs/login.spec"))
+
+	c.Assert(path("specs/login.spec"), gc.Equals, "specs/login.spec")
+}
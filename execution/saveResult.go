@@ -40,6 +40,7 @@ func ListenSuiteEndAndSaveResult(wg *sync.WaitGroup) {
 			if e.Topic == event.SuiteEnd {
 				writeResult(e.Result.(*result.SuiteResult))
 				wg.Done()
+				return
 			}
 		}
 	}()
@@ -8,6 +8,7 @@ package execution
 
 import (
 	"fmt"
+	"time"
 
 	"errors"
 
@@ -29,6 +30,9 @@ type scenarioExecutor struct {
 	stream               int
 	contexts             []*gauge.Step
 	teardowns            []*gauge.Step
+	stepTimeout          time.Duration
+	deadline             time.Time
+	skipStepHooks        bool
 }
 
 func newScenarioExecutor(r runner.Runner, ph plugin.Handler, ei *gauge_messages.ExecutionInfo, errMap *gauge.BuildErrors, contexts []*gauge.Step, teardowns []*gauge.Step, stream int) *scenarioExecutor {
@@ -48,11 +52,18 @@ func (e *scenarioExecutor) execute(i gauge.Item, r result.Result) {
 	scenarioResult := r.(*result.ScenarioResult)
 	scenarioResult.ProtoScenario.ExecutionStatus = gauge_messages.ExecutionStatus_PASSED
 	scenarioResult.ProtoScenario.Skipped = false
-	if(e.runner.Info().Killed){
+	if runnerCrashed(e.runner) {
 		e.errMap.ScenarioErrs[scenario] = append([]error{errors.New("skipped Reason: Runner is not alive")}, e.errMap.ScenarioErrs[scenario]...)
 		setSkipInfoInResult(scenarioResult, scenario, e.errMap)
 		return
 	}
+	// a scenario being retried is allowed to finish
+	if scenarioResult.ProtoScenario.GetRetriesCount() <= 1 && executionStopped() {
+		skipStoppedScenario(scenarioResult)
+		event.Notify(event.NewExecutionEvent(event.ScenarioStart, scenario, scenarioResult, e.stream, e.currentExecutionInfo))
+		event.Notify(event.NewExecutionEvent(event.ScenarioEnd, scenario, scenarioResult, e.stream, e.currentExecutionInfo))
+		return
+	}
 	if scenario.SpecDataTableRow.IsInitialized() && !shouldExecuteForRow(scenario.SpecDataTableRowIndex) {
 		e.errMap.ScenarioErrs[scenario] = append([]error{errors.New("skipped Reason: Doesn't satisfy --table-rows flag condition")}, e.errMap.ScenarioErrs[scenario]...)
 		setSkipInfoInResult(scenarioResult, scenario, e.errMap)
@@ -67,6 +78,12 @@ func (e *scenarioExecutor) execute(i gauge.Item, r result.Result) {
 	event.Notify(event.NewExecutionEvent(event.ScenarioStart, scenario, scenarioResult, e.stream, e.currentExecutionInfo))
 	defer event.Notify(event.NewExecutionEvent(event.ScenarioEnd, scenario, scenarioResult, e.stream, e.currentExecutionInfo))
 
+	scenarioTimeout, stepTimeout := timeouts(e.currentExecutionInfo.GetCurrentSpec().GetTags(), getTagValue(scenario.Tags))
+	e.stepTimeout, e.deadline = stepTimeout, time.Time{}
+	if scenarioTimeout > 0 {
+		e.deadline = time.Now().Add(scenarioTimeout)
+	}
+
 	res := e.initScenarioDataStore()
 	if res.GetFailed() {
 		e.handleScenarioDataStoreFailure(scenarioResult, scenario, fmt.Errorf("Failed to initialize scenario datastore. Error: %s", res.GetErrorMessage()))
@@ -79,11 +96,13 @@ func (e *scenarioExecutor) execute(i gauge.Item, r result.Result) {
 		protoScenItems := scenarioResult.ProtoScenario.GetScenarioItems()
 		// context and steps are not appended together since sometime it cause the issue and the steps in step list and proto step list differs.
 		// This is done to fix https://github.com/getgauge/gauge/issues/1629
-		if e.executeSteps(e.contexts, protoContexts, scenarioResult) {
-			e.executeSteps(scenario.Steps, protoScenItems, scenarioResult)
+		if e.executeSteps(e.contexts, protoContexts, scenarioResult, true) {
+			e.executeSteps(scenario.Steps, protoScenItems, scenarioResult, true)
 		}
 		// teardowns are not appended to previous call to executeSteps to ensure they are run irrespective of context/step failure
-		e.executeSteps(e.teardowns, scenarioResult.ProtoScenario.GetTearDownSteps(), scenarioResult)
+		// teardowns are not bound by the scenario timeout, so that they can clean up after a timed out scenario
+		e.deadline = time.Time{}
+		e.executeSteps(e.teardowns, scenarioResult.ProtoScenario.GetTearDownSteps(), scenarioResult, false)
 	}
 
 	e.notifyAfterScenarioHook(scenarioResult)
@@ -119,6 +138,7 @@ func (e *scenarioExecutor) notifyBeforeScenarioHook(scenarioResult *result.Scena
 		ScenarioExecutionStartingRequest: &gauge_messages.ScenarioExecutionStartingRequest{CurrentExecutionInfo: e.currentExecutionInfo, Stream: int32(e.stream)}}
 	e.pluginHandler.NotifyPlugins(message)
 	res := executeHook(message, scenarioResult, e.runner)
+	recordRunnerCrash(e.runner, res)
 	scenarioResult.ProtoScenario.PreHookMessages = res.Message
 	scenarioResult.ProtoScenario.PreHookScreenshotFiles = res.ScreenshotFiles
 	scenarioResult.ProtoScenario.PreHookScreenshots = res.Screenshots
@@ -133,22 +153,37 @@ func (e *scenarioExecutor) notifyBeforeScenarioHook(scenarioResult *result.Scena
 func (e *scenarioExecutor) notifyAfterScenarioHook(scenarioResult *result.ScenarioResult) {
 	message := &gauge_messages.Message{MessageType: gauge_messages.Message_ScenarioExecutionEnding,
 		ScenarioExecutionEndingRequest: &gauge_messages.ScenarioExecutionEndingRequest{CurrentExecutionInfo: e.currentExecutionInfo, Stream: int32(e.stream)}}
-	res := executeHook(message, scenarioResult, e.runner)
-	scenarioResult.ProtoScenario.PostHookMessages = res.Message
-	scenarioResult.ProtoScenario.PostHookScreenshotFiles = res.ScreenshotFiles
-	scenarioResult.ProtoScenario.PostHookScreenshots = res.Screenshots
-	if res.GetFailed() {
-		setScenarioFailure(e.currentExecutionInfo)
-		handleHookFailure(scenarioResult, res, result.AddPostHook)
+	// the hook is not executed by a runner which crashed while executing the scenario
+	if !runnerCrashed(e.runner) {
+		res := executeHook(message, scenarioResult, e.runner)
+		recordRunnerCrash(e.runner, res)
+		scenarioResult.ProtoScenario.PostHookMessages = res.Message
+		scenarioResult.ProtoScenario.PostHookScreenshotFiles = res.ScreenshotFiles
+		scenarioResult.ProtoScenario.PostHookScreenshots = res.Screenshots
+		if res.GetFailed() {
+			setScenarioFailure(e.currentExecutionInfo)
+			handleHookFailure(scenarioResult, res, result.AddPostHook)
+		}
 	}
 	message.ScenarioExecutionEndingRequest.ScenarioResult = gauge.ConvertToProtoScenarioResult(scenarioResult)
 	e.pluginHandler.NotifyPlugins(message)
 }
 
-func (e *scenarioExecutor) executeSteps(steps []*gauge.Step, protoItems []*gauge_messages.ProtoItem, scenarioResult *result.ScenarioResult) bool {
+// executeSteps returns false if the remaining steps of the scenario should not be executed. If interruptible, the steps
+// not yet started are skipped once the execution is interrupted. No step is executed once the runner crashed.
+func (e *scenarioExecutor) executeSteps(steps []*gauge.Step, protoItems []*gauge_messages.ProtoItem, scenarioResult *result.ScenarioResult, interruptible bool) bool {
 	var stepsIndex int
 	for _, protoItem := range protoItems {
 		if protoItem.GetItemType() == gauge_messages.ProtoItem_Concept || protoItem.GetItemType() == gauge_messages.ProtoItem_Step {
+			if runnerCrashed(e.runner) {
+				return false
+			}
+			if interruptible && isInterrupted() {
+				if !scenarioResult.GetFailed() {
+					skipStoppedScenario(scenarioResult)
+				}
+				return false
+			}
 			failed, recoverable := e.executeStep(steps[stepsIndex], protoItem, scenarioResult)
 			stepsIndex++
 			if failed {
@@ -171,7 +206,7 @@ func (e *scenarioExecutor) executeStep(step *gauge.Step, protoItem *gauge_messag
 		recoverable = res.GetRecoverable()
 
 	} else if protoItem.GetItemType() == gauge_messages.ProtoItem_Step {
-		se := &stepExecutor{runner: e.runner, pluginHandler: e.pluginHandler, currentExecutionInfo: e.currentExecutionInfo, stream: e.stream}
+		se := &stepExecutor{runner: e.runner, pluginHandler: e.pluginHandler, currentExecutionInfo: e.currentExecutionInfo, stream: e.stream, timeout: stepTimeout(e.stepTimeout, e.deadline), skipHooks: e.skipStepHooks}
 		res := se.executeStep(step, protoItem.GetStep())
 		protoItem.GetStep().StepExecutionResult = res.ProtoStepExecResult()
 		failed = res.GetFailed()
@@ -14,7 +14,9 @@ import (
 	"github.com/getgauge/gauge-proto/go/gauge_messages"
 	"github.com/getgauge/gauge/config"
 	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/metrics"
 	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/filter"
 	"github.com/getgauge/gauge/gauge"
 	"github.com/getgauge/gauge/logger"
 	"github.com/getgauge/gauge/manifest"
@@ -24,17 +26,21 @@ import (
 
 // ExecuteTags holds the tags to filter the execution by
 var ExecuteTags = ""
+var dataTableRows string
 var tableRowsIndexes []int
 
 // SetTableRows is used to limit data driven execution to specific rows
-func SetTableRows(tableRows string) {
-	tableRowsIndexes = getDataTableRows(tableRows)
+func SetTableRows(rows string) {
+	dataTableRows = rows
+	tableRowsIndexes = getDataTableRows(rows)
+	filter.TableRowIndexes = tableRowsIndexes
 }
 
 type simpleExecution struct {
 	manifest             *manifest.Manifest
 	runner               runner.Runner
 	specCollection       *gauge.SpecCollection
+	queue                *lockingQueue
 	pluginHandler        plugin.Handler
 	currentExecutionInfo *gauge_messages.ExecutionInfo
 	suiteResult          *result.SuiteResult
@@ -91,7 +97,11 @@ func (e *simpleExecution) execute() {
 	}
 
 	if !e.suiteResult.GetFailed() {
-		results := e.executeSpecs(e.specCollection)
+		var specs specQueue = e.specCollection
+		if e.queue != nil {
+			specs = e.queue
+		}
+		results := e.executeSpecs(specs)
 		e.suiteResult.AddSpecResults(results)
 	}
 
@@ -102,11 +112,13 @@ func (e *simpleExecution) execute() {
 
 func (e *simpleExecution) start() {
 	e.startTime = time.Now()
+	failures.reset()
 	event.Notify(event.NewExecutionEvent(event.SuiteStart, nil, nil, 0, &gauge_messages.ExecutionInfo{}))
 	e.pluginHandler = plugin.StartPlugins(e.manifest)
 }
 
 func (e *simpleExecution) finish() {
+	markInterrupted(e.suiteResult)
 	e.suiteResult = mergeDataTableSpecResults(e.suiteResult)
 	event.Notify(event.NewExecutionEvent(event.SuiteEnd, nil, e.suiteResult, 0, &gauge_messages.ExecutionInfo{}))
 	e.notifyExecutionResult()
@@ -120,48 +132,101 @@ func (e *simpleExecution) stopAllPlugins() {
 	}
 }
 
-func (e *simpleExecution) executeSpecs(sc *gauge.SpecCollection) (results []*result.SpecResult) {
+func (e *simpleExecution) executeSpecs(sc specQueue) (results []*result.SpecResult) {
+	// the specs created for the scenarios of a file are handed out one by one in scenario granularity, so the after spec hook
+	// is held back while the stream takes the scenarios of the same file, and the spec hooks are run once for all of them
+	holdAfter := isScenarioGranularity()
+	q, locking := sc.(*lockingQueue)
+	var run *specRun
+	metrics.SetQueueDepth(e.stream, sc.Remaining())
 	for sc.HasNext() {
 		specs := sc.Next()
-		var preHookFailures, postHookFailures []*gauge_messages.ProtoHookFailure
-		var specResults []*result.SpecResult
-		var before, after = true, false
+		metrics.SetQueueDepth(e.stream, sc.Remaining())
+		if specs == nil {
+			// the remaining specs were taken by the other streams while waiting for a lock
+			break
+		}
+		stopped := executionStopped()
+		if run != nil && (run.file != specs[0].FileName || run.stopped != stopped) {
+			results = append(results, run.finish(true)...)
+			run = nil
+		}
+		before := run == nil
+		if run == nil {
+			run = &specRun{file: specs[0].FileName, stopped: stopped}
+		}
+		// the locks are held till the after spec hook is run, so it is not held back for the specs holding a lock
+		hold := holdAfter && !(locking && holdsLocks(specs))
 		for i, spec := range specs {
-			if i == len(specs)-1 {
-				after = true
-			}
-			res := newSpecExecutor(spec, e.runner, e.pluginHandler, e.errMaps, e.stream).execute(before, preHookFailures == nil, after)
+			run.execute(newSpecExecutor(spec, e.runner, e.pluginHandler, e.errMaps, e.stream), before, i == len(specs)-1 && !hold)
 			before = false
-			specResults = append(specResults, res)
-			preHookFailures = append(preHookFailures, res.GetPreHook()...)
-			postHookFailures = append(postHookFailures, res.GetPostHook()...)
-			res.ProtoSpec.PreHookFailures, res.ProtoSpec.PostHookFailures = []*gauge_messages.ProtoHookFailure{}, []*gauge_messages.ProtoHookFailure{}
 		}
-		for _, res := range specResults {
-			for _, preHook := range preHookFailures {
-				res.AddPreHook(&gauge_messages.ProtoHookFailure{
-					StackTrace:            preHook.StackTrace,
-					ErrorMessage:          preHook.ErrorMessage,
-					FailureScreenshot:     preHook.FailureScreenshot,
-					FailureScreenshotFile: preHook.FailureScreenshotFile,
-					TableRowIndex:         preHook.TableRowIndex,
-				})
-			}
-			for _, postHook := range postHookFailures {
-				res.AddPostHook(&gauge_messages.ProtoHookFailure{
-					StackTrace:            postHook.StackTrace,
-					ErrorMessage:          postHook.ErrorMessage,
-					FailureScreenshot:     postHook.FailureScreenshot,
-					FailureScreenshotFile: postHook.FailureScreenshotFile,
-					TableRowIndex:         postHook.TableRowIndex,
-				})
-			}
-			results = append(results, res)
+		if !hold {
+			results = append(results, run.finish(false)...)
+			run = nil
+		}
+		if locking {
+			q.release(specs)
 		}
 	}
+	if run != nil {
+		results = append(results, run.finish(true)...)
+	}
 	return results
 }
 
+// specRun is the specs of a file executed one after the other by a stream, which share the before and after spec hooks.
+type specRun struct {
+	file                              string
+	stopped                           bool
+	last                              *specExecutor
+	results                           []*result.SpecResult
+	preHookFailures, postHookFailures []*gauge_messages.ProtoHookFailure
+}
+
+func (r *specRun) execute(e *specExecutor, before, after bool) {
+	e.stopped = r.stopped
+	res := e.execute(before, r.preHookFailures == nil, after)
+	r.last = e
+	r.results = append(r.results, res)
+	r.collectHookFailures(res)
+}
+
+func (r *specRun) collectHookFailures(res *result.SpecResult) {
+	r.preHookFailures = append(r.preHookFailures, res.GetPreHook()...)
+	r.postHookFailures = append(r.postHookFailures, res.GetPostHook()...)
+	res.ProtoSpec.PreHookFailures, res.ProtoSpec.PostHookFailures = []*gauge_messages.ProtoHookFailure{}, []*gauge_messages.ProtoHookFailure{}
+}
+
+// finish runs the after spec hook, if it was held back, and adds the hook failures to the results of all the specs in the run.
+func (r *specRun) finish(afterHeldBack bool) []*result.SpecResult {
+	if afterHeldBack && r.last.executed {
+		r.last.finish()
+		r.collectHookFailures(r.last.specResult)
+	}
+	for _, res := range r.results {
+		for _, preHook := range r.preHookFailures {
+			res.AddPreHook(&gauge_messages.ProtoHookFailure{
+				StackTrace:            preHook.StackTrace,
+				ErrorMessage:          preHook.ErrorMessage,
+				FailureScreenshot:     preHook.FailureScreenshot,
+				FailureScreenshotFile: preHook.FailureScreenshotFile,
+				TableRowIndex:         preHook.TableRowIndex,
+			})
+		}
+		for _, postHook := range r.postHookFailures {
+			res.AddPostHook(&gauge_messages.ProtoHookFailure{
+				StackTrace:            postHook.StackTrace,
+				ErrorMessage:          postHook.ErrorMessage,
+				FailureScreenshot:     postHook.FailureScreenshot,
+				FailureScreenshotFile: postHook.FailureScreenshotFile,
+				TableRowIndex:         postHook.TableRowIndex,
+			})
+		}
+	}
+	return r.results
+}
+
 func (e *simpleExecution) notifyBeforeSuite() {
 	m := &gauge_messages.Message{MessageType: gauge_messages.Message_ExecutionStarting,
 		ExecutionStartingRequest: &gauge_messages.ExecutionStartingRequest{CurrentExecutionInfo: e.currentExecutionInfo, Stream: int32(e.stream)}}
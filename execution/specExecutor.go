@@ -34,6 +34,12 @@ type specExecutor struct {
 	errMap               *gauge.BuildErrors
 	stream               int
 	scenarioExecutor     executor
+	// stopped is set if the execution was interrupted or the failure limit was reached before the spec was started, so that its hooks are not run
+	stopped bool
+	// executed is set once the spec is executed, the after spec hook held back by the caller is run only for such a spec
+	executed bool
+	// retryFilter selects the scenarios of the spec to retry, when retries are limited to the scenarios with some tags
+	retryFilter *filter.ScenarioFilterBasedOnTags
 }
 
 func newSpecExecutor(s *gauge.Specification, r runner.Runner, ph plugin.Handler, e *gauge.BuildErrors, stream int) *specExecutor {
@@ -62,7 +68,7 @@ func newSpecExecutor(s *gauge.Specification, r runner.Runner, ph plugin.Handler,
 
 func (e *specExecutor) execute(executeBefore, execute, executeAfter bool) *result.SpecResult {
 	e.specResult = gauge.NewSpecResult(e.specification)
-	if e.runner.Info().Killed {
+	if !recoverRunner(e.runner) {
 		e.specResult.SetSkipped(true)
 		return e.specResult
 	}
@@ -84,7 +90,9 @@ func (e *specExecutor) execute(executeBefore, execute, executeAfter bool) *resul
 	if executeBefore {
 		event.Notify(event.NewExecutionEvent(event.SpecStart, e.specification, e.specResult, e.stream, e.currentExecutionInfo))
 		if _, ok := e.errMap.SpecErrs[e.specification]; !ok {
-			if res := e.initSpecDataStore(); res.GetFailed() {
+			if e.stopped {
+				e.specResult.SetSkipped(true)
+			} else if res := e.initSpecDataStore(); res.GetFailed() {
 				e.skipSpecForError(fmt.Errorf("Failed to initialize spec datastore. Error: %s", res.GetErrorMessage()))
 			} else {
 				e.notifyBeforeSpecHook()
@@ -125,15 +133,21 @@ func (e *specExecutor) execute(executeBefore, execute, executeAfter bool) *resul
 		}
 	}
 	e.specResult.SetSkipped(e.specResult.Skipped || e.specResult.ScenarioSkippedCount == len(e.specification.Scenarios))
+	e.executed = true
 	if executeAfter {
-		if _, ok := e.errMap.SpecErrs[e.specification]; !ok {
-			e.notifyAfterSpecHook()
-		}
-		event.Notify(event.NewExecutionEvent(event.SpecEnd, e.specification, e.specResult, e.stream, e.currentExecutionInfo))
+		e.finish()
 	}
 	return e.specResult
 }
 
+// finish runs the after spec hook and notifies the end of the spec.
+func (e *specExecutor) finish() {
+	if _, ok := e.errMap.SpecErrs[e.specification]; !ok && !e.stopped {
+		e.notifyAfterSpecHook()
+	}
+	event.Notify(event.NewExecutionEvent(event.SpecEnd, e.specification, e.specResult, e.stream, e.currentExecutionInfo))
+}
+
 func (e *specExecutor) executeTableRelatedScenarios(scenarios []*gauge.Scenario) error {
 	if len(scenarios) > 0 {
 		index := e.specification.Scenarios[0].SpecDataTableRowIndex
@@ -285,15 +299,14 @@ func (e *specExecutor) executeScenario(scenario *gauge.Scenario) (*result.Scenar
 	shouldRetry := RetryOnlyTags == ""
 
 	if !shouldRetry {
-		spec := e.specification
-		tagValues := make([]string, 0)
-		if spec.Tags != nil {
-			tagValues = spec.Tags.Values()
+		if e.retryFilter == nil {
+			tagValues := make([]string, 0)
+			if e.specification.Tags != nil {
+				tagValues = e.specification.Tags.Values()
+			}
+			e.retryFilter = filter.NewScenarioFilterBasedOnTags(tagValues, RetryOnlyTags)
 		}
-
-		specFilter := filter.NewScenarioFilterBasedOnTags(tagValues, RetryOnlyTags)
-
-		shouldRetry = !(specFilter.Filter(scenario))
+		shouldRetry = !(e.retryFilter.Filter(scenario))
 	}
 	retriesCount := 0
 	for i := 0; i < MaxRetriesCount; i++ {
@@ -312,17 +325,29 @@ func (e *specExecutor) executeScenario(scenario *gauge.Scenario) (*result.Scenar
 		if err := e.addAllItemsForScenarioExecution(scenario, scenarioResult); err != nil {
 			return nil, err
 		}
-		e.scenarioExecutor.execute(scenario, scenarioResult)
 		retriesCount++
+		// Set before execution so that the scenario end listeners can tell a flaky scenario.
+		scenarioResult.ProtoScenario.RetriesCount = int64(retriesCount)
+		e.scenarioExecutor.execute(scenario, scenarioResult)
+		if runnerCrashed(e.runner) && !scenarioResult.ProtoScenario.GetSkipped() {
+			logger.Errorf(true, "%s Scenario: %s, Specification: %s", runnerCrashedMessage, scenario.Heading.Value, e.specification.FileName)
+			recoverRunner(e.runner)
+		}
 		if scenarioResult.ProtoScenario.GetExecutionStatus() == gauge_messages.ExecutionStatus_SKIPPED {
 			e.specResult.ScenarioSkippedCount++
 		}
+		if scenarioResult.IsFlaky() {
+			logger.Debugf(true, "Scenario '%s' passed after %d attempts and is marked as flaky.", scenario.Heading.Value, retriesCount)
+		}
 
 		if !(shouldRetry && scenarioResult.GetFailed()) {
 			break
 		}
 	}
 	scenarioResult.ProtoScenario.RetriesCount = int64(retriesCount)
+	if scenarioResult.GetFailed() {
+		failures.add()
+	}
 	return scenarioResult, nil
 }
 
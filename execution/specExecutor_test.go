@@ -242,7 +242,7 @@ func (r *mockRunner) ExecuteAndGetStatus(m *gauge_messages.Message) *gauge_messa
 }
 
 func (r *mockRunner) Alive() bool {
-	return false
+	return true
 }
 
 func (r *mockRunner) Kill() error {
@@ -7,6 +7,8 @@
 package execution
 
 import (
+	"time"
+
 	"github.com/getgauge/gauge-proto/go/gauge_messages"
 	"github.com/getgauge/gauge/execution/event"
 	"github.com/getgauge/gauge/execution/result"
@@ -20,6 +22,8 @@ type stepExecutor struct {
 	pluginHandler        plugin.Handler
 	currentExecutionInfo *gauge_messages.ExecutionInfo
 	stream               int
+	timeout              time.Duration
+	skipHooks            bool
 }
 
 // TODO: stepExecutor should not consume both gauge.Step and gauge_messages.ProtoStep. The usage of ProtoStep should be eliminated.
@@ -36,12 +40,14 @@ func (e *stepExecutor) executeStep(step *gauge.Step, protoStep *gauge_messages.P
 	}
 	event.Notify(event.NewExecutionEvent(event.StepStart, step, nil, e.stream, e.currentExecutionInfo))
 
-	e.notifyBeforeStepHook(stepResult)
+	if !e.skipHooks {
+		e.notifyBeforeStepHook(stepResult)
+	}
 	if !stepResult.GetFailed() {
 		executeStepMessage := &gauge_messages.Message{MessageType: gauge_messages.Message_ExecuteStep, ExecuteStepRequest: stepRequest}
-		stepExecutionStatus := e.runner.ExecuteAndGetStatus(executeStepMessage)
-		stepExecutionStatus.Message = append(stepResult.ProtoStepExecResult().GetExecutionResult().Message, stepExecutionStatus.Message...)
-		stepExecutionStatus.Screenshots = append(stepResult.ProtoStepExecResult().GetExecutionResult().Screenshots, stepExecutionStatus.Screenshots...)
+		stepExecutionStatus := executeWithTimeout(e.runner, executeStepMessage, e.timeout)
+		stepExecutionStatus.Message = append(stepResult.ProtoStepExecResult().GetExecutionResult().GetMessage(), stepExecutionStatus.Message...)
+		stepExecutionStatus.Screenshots = append(stepResult.ProtoStepExecResult().GetExecutionResult().GetScreenshots(), stepExecutionStatus.Screenshots...)
 		if stepExecutionStatus.GetFailed() {
 			e.currentExecutionInfo.CurrentStep.ErrorMessage = stepExecutionStatus.GetErrorMessage()
 			e.currentExecutionInfo.CurrentStep.StackTrace = stepExecutionStatus.GetStackTrace()
@@ -50,7 +56,9 @@ func (e *stepExecutor) executeStep(step *gauge.Step, protoStep *gauge_messages.P
 		}
 		stepResult.SetProtoExecResult(stepExecutionStatus)
 	}
-	e.notifyAfterStepHook(stepResult)
+	if !e.skipHooks && !runnerCrashed(e.runner) {
+		e.notifyAfterStepHook(stepResult)
+	}
 
 	event.Notify(event.NewExecutionEvent(event.StepEnd, *step, stepResult, e.stream, e.currentExecutionInfo))
 	defer e.currentExecutionInfo.CurrentStep.Reset()
@@ -70,6 +78,7 @@ func (e *stepExecutor) notifyBeforeStepHook(stepResult *result.StepResult) {
 	}
 	e.pluginHandler.NotifyPlugins(m)
 	res := executeHook(m, stepResult, e.runner)
+	recordRunnerCrash(e.runner, res)
 	stepResult.ProtoStep.PreHookMessages = res.Message
 	stepResult.ProtoStep.PreHookScreenshotFiles = res.ScreenshotFiles
 	stepResult.ProtoStep.PreHookScreenshots = res.Screenshots
@@ -88,6 +97,7 @@ func (e *stepExecutor) notifyAfterStepHook(stepResult *result.StepResult) {
 	}
 
 	res := executeHook(m, stepResult, e.runner)
+	recordRunnerCrash(e.runner, res)
 	stepResult.ProtoStep.PostHookMessages = res.Message
 	stepResult.ProtoStep.PostHookScreenshotFiles = res.ScreenshotFiles
 	stepResult.ProtoStep.PostHookScreenshots = res.Screenshots
@@ -0,0 +1,228 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/metrics"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/manifest"
+	"github.com/getgauge/gauge/runner"
+)
+
+const (
+	scenarioTimeoutTag = "timeout"
+	stepTimeoutTag     = "step-timeout"
+)
+
+// ScenarioTimeout is the time a scenario is allowed to run, 0 being no limit. It can be overridden by a `timeout:<duration>` spec or scenario tag.
+var ScenarioTimeout time.Duration
+
+// StepTimeout is the time a step is allowed to run, 0 being no limit. It can be overridden by a `step-timeout:<duration>` spec or scenario tag.
+var StepTimeout time.Duration
+
+// timeouts returns the scenario and step timeouts for a scenario. Scenario tags take precedence over spec tags, which take precedence over the flags.
+func timeouts(specTags, scenarioTags []string) (scenario time.Duration, step time.Duration) {
+	scenario, step = ScenarioTimeout, StepTimeout
+	for _, tags := range [][]string{specTags, scenarioTags} {
+		if d, ok := timeoutFromTags(tags, scenarioTimeoutTag); ok {
+			scenario = d
+		}
+		if d, ok := timeoutFromTags(tags, stepTimeoutTag); ok {
+			step = d
+		}
+	}
+	return scenario, step
+}
+
+func timeoutFromTags(tags []string, name string) (time.Duration, bool) {
+	for _, tag := range tags {
+		i := strings.Index(tag, ":")
+		if i < 0 || !strings.EqualFold(strings.TrimSpace(tag[:i]), name) {
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(tag[i+1:]))
+		if err != nil || d < 0 {
+			logger.Warningf(true, "Ignoring tag '%s'. Expected a duration like %s:30s", tag, name)
+			continue
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// stepTimeout returns the time the next step is allowed to run, given the step timeout and the scenario deadline.
+func stepTimeout(step time.Duration, deadline time.Time) time.Duration {
+	if deadline.IsZero() {
+		return step
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		remaining = time.Nanosecond
+	}
+	if step == 0 || remaining < step {
+		return remaining
+	}
+	return step
+}
+
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("Step timed out after %s. The runner was stopped as the step did not complete in time.", e.timeout)
+}
+
+// executeWithTimeout executes the message and waits at most for the given timeout, 0 being no limit.
+// If the runner does not respond in time, it is killed and restarted when possible, and a failed result is returned.
+// The result is failed with the crash if the runner crashed while executing the message.
+func executeWithTimeout(r runner.Runner, m *gauge_messages.Message, timeout time.Duration) *gauge_messages.ProtoExecutionResult {
+	if timeout <= 0 {
+		res := r.ExecuteAndGetStatus(m)
+		recordRunnerCrash(r, res)
+		return res
+	}
+	resChan := make(chan *gauge_messages.ProtoExecutionResult, 1)
+	go func() {
+		resChan <- r.ExecuteAndGetStatus(m)
+	}()
+	select {
+	case res := <-resChan:
+		recordRunnerCrash(r, res)
+		return res
+	case <-time.After(timeout):
+		err := timeoutError{timeout}
+		logger.Errorf(true, err.Error())
+		stopTimedOutRunner(r)
+		return &gauge_messages.ProtoExecutionResult{Failed: true, ErrorMessage: err.Error(), ExecutionTime: timeout.Milliseconds()}
+	}
+}
+
+// stopTimedOutRunner kills the runner busy with a timed out step, and restarts it for the remaining scenarios.
+// A runner shared by parallel streams is not stopped as the other streams are still using it.
+func stopTimedOutRunner(r runner.Runner) {
+	if InParallel && r.IsMultithreaded() {
+		return
+	}
+	rr, ok := r.(restarter)
+	if !ok {
+		killRunner(r)
+		return
+	}
+	if err := rr.restart(); err != nil {
+		logger.Errorf(true, "Failed to restart the runner. %s", err.Error())
+	}
+}
+
+func killRunner(r runner.Runner) {
+	if err := r.Kill(); err != nil {
+		logger.Debugf(true, "Failed to kill the runner gracefully, killing it forcefully. %s", err.Error())
+		if p, err := os.FindProcess(r.Pid()); err == nil {
+			if err := p.Kill(); err != nil {
+				logger.Debugf(true, "Failed to kill runner with PID:%d. %s", r.Pid(), err.Error())
+			}
+		}
+	}
+	r.Info().Killed = true
+}
+
+type restarter interface {
+	restart() error
+}
+
+// restartableRunner lets a runner stopped in the middle of an execution be replaced by a new one. The runner is
+// guarded as it is replaced while the step which timed out, or the interrupt handler, may still be using it.
+type restartableRunner struct {
+	mutex   sync.RWMutex
+	runner  runner.Runner
+	stream  int
+	crashes int
+}
+
+func newRestartableRunner(r runner.Runner, stream int) runner.Runner {
+	if r == nil {
+		return nil
+	}
+	rr := &restartableRunner{runner: r, stream: stream}
+	runners.add(rr)
+	return rr
+}
+
+func (r *restartableRunner) current() runner.Runner {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.runner
+}
+
+func (r *restartableRunner) ExecuteAndGetStatus(m *gauge_messages.Message) *gauge_messages.ProtoExecutionResult {
+	return r.current().ExecuteAndGetStatus(m)
+}
+
+func (r *restartableRunner) ExecuteMessageWithTimeout(m *gauge_messages.Message) (*gauge_messages.Message, error) {
+	return r.current().ExecuteMessageWithTimeout(m)
+}
+
+func (r *restartableRunner) Alive() bool {
+	return r.current().Alive()
+}
+
+func (r *restartableRunner) Kill() error {
+	return r.current().Kill()
+}
+
+func (r *restartableRunner) Connection() net.Conn {
+	return r.current().Connection()
+}
+
+func (r *restartableRunner) IsMultithreaded() bool {
+	return r.current().IsMultithreaded()
+}
+
+func (r *restartableRunner) Info() *runner.RunnerInfo {
+	return r.current().Info()
+}
+
+func (r *restartableRunner) Pid() int {
+	return r.current().Pid()
+}
+
+// restart kills the current runner and starts a new one. The suite, spec and scenario data stores are initialised again,
+// so that the after hooks and tear down steps of the scenario in progress can run, but the before hooks are not re-run.
+func (r *restartableRunner) restart() error {
+	killRunner(r.current())
+	m, err := manifest.ProjectManifest()
+	if err != nil {
+		return err
+	}
+	logger.Infof(true, "Restarting the runner.")
+	n, err := runner.Start(m, r.stream, make(chan bool), false)
+	if err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	r.runner = n
+	r.mutex.Unlock()
+	metrics.RunnerRestarted(r.stream)
+	for _, msg := range []*gauge_messages.Message{
+		{MessageType: gauge_messages.Message_SuiteDataStoreInit, SuiteDataStoreInitRequest: &gauge_messages.SuiteDataStoreInitRequest{Stream: int32(r.stream)}},
+		{MessageType: gauge_messages.Message_SpecDataStoreInit, SpecDataStoreInitRequest: &gauge_messages.SpecDataStoreInitRequest{Stream: int32(r.stream)}},
+		{MessageType: gauge_messages.Message_ScenarioDataStoreInit, ScenarioDataStoreInitRequest: &gauge_messages.ScenarioDataStoreInitRequest{Stream: int32(r.stream)}},
+	} {
+		if res := n.ExecuteAndGetStatus(msg); res.GetFailed() {
+			return fmt.Errorf("failed to initialise data store of the restarted runner. %s", res.GetErrorMessage())
+		}
+	}
+	return nil
+}
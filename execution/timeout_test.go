@@ -0,0 +1,85 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	. "gopkg.in/check.v1"
+)
+
+type restartingMockRunner struct {
+	*mockRunner
+	restarts int
+}
+
+func (r *restartingMockRunner) restart() error {
+	r.restarts++
+	return nil
+}
+
+func (s *MySuite) TestTimeoutsFromFlags(c *C) {
+	ScenarioTimeout, StepTimeout = time.Minute, time.Second
+	defer func() { ScenarioTimeout, StepTimeout = 0, 0 }()
+
+	scenario, step := timeouts([]string{"smoke"}, nil)
+
+	c.Assert(scenario, Equals, time.Minute)
+	c.Assert(step, Equals, time.Second)
+}
+
+func (s *MySuite) TestTimeoutsFromScenarioTagsOverrideSpecTags(c *C) {
+	ScenarioTimeout = time.Minute
+	defer func() { ScenarioTimeout = 0 }()
+
+	scenario, step := timeouts([]string{"timeout:30s", "step-timeout:5s"}, []string{"Timeout: 10s"})
+
+	c.Assert(scenario, Equals, 10*time.Second)
+	c.Assert(step, Equals, 5*time.Second)
+}
+
+func (s *MySuite) TestTimeoutsIgnoreInvalidTags(c *C) {
+	scenario, _ := timeouts(nil, []string{"timeout:soon"})
+
+	c.Assert(scenario, Equals, time.Duration(0))
+}
+
+func (s *MySuite) TestStepTimeoutIsLimitedByScenarioDeadline(c *C) {
+	c.Assert(stepTimeout(time.Second, time.Time{}), Equals, time.Second)
+	c.Assert(stepTimeout(time.Hour, time.Now().Add(time.Minute)) <= time.Minute, Equals, true)
+	c.Assert(stepTimeout(time.Second, time.Now().Add(time.Minute)), Equals, time.Second)
+	c.Assert(stepTimeout(0, time.Now().Add(-time.Minute)), Equals, time.Nanosecond)
+}
+
+func (s *MySuite) TestExecuteWithTimeoutFailsAndRestartsRunnerIfStepDoesNotComplete(c *C) {
+	block := make(chan bool)
+	defer close(block)
+	r := &restartingMockRunner{mockRunner: &mockRunner{ExecuteAndGetStatusFunc: func(m *gauge_messages.Message) *gauge_messages.ProtoExecutionResult {
+		<-block
+		return &gauge_messages.ProtoExecutionResult{}
+	}}}
+
+	res := executeWithTimeout(r, &gauge_messages.Message{MessageType: gauge_messages.Message_ExecuteStep}, 10*time.Millisecond)
+
+	c.Assert(res.GetFailed(), Equals, true)
+	c.Assert(res.GetRecoverableError(), Equals, false)
+	c.Assert(res.GetErrorMessage(), Equals, "Step timed out after 10ms. The runner was stopped as the step did not complete in time.")
+	c.Assert(r.restarts, Equals, 1)
+}
+
+func (s *MySuite) TestExecuteWithTimeoutReturnsResultOfStepCompletingInTime(c *C) {
+	r := &restartingMockRunner{mockRunner: &mockRunner{ExecuteAndGetStatusFunc: func(m *gauge_messages.Message) *gauge_messages.ProtoExecutionResult {
+		return &gauge_messages.ProtoExecutionResult{ExecutionTime: 1}
+	}}}
+
+	res := executeWithTimeout(r, &gauge_messages.Message{MessageType: gauge_messages.Message_ExecuteStep}, time.Minute)
+
+	c.Assert(res.GetFailed(), Equals, false)
+	c.Assert(res.GetExecutionTime(), Equals, int64(1))
+	c.Assert(r.restarts, Equals, 0)
+}
@@ -0,0 +1,330 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+// Package trace exports the execution as an OpenTelemetry trace, with a span for the suite and for each spec, scenario,
+// concept and step executed. Once the suite ends, the trace is sent in the OTLP JSON format to the OTLP/HTTP endpoint
+// given by the otel_traces_endpoint property and written to the file given by the otel_traces_file property.
+//
+// The context of the suite span is passed to the runners in the TRACEPARENT env var, in the W3C trace context format,
+// so that the step implementations can add their own spans to the trace.
+package trace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getgauge/common"
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/version"
+)
+
+// TraceParentEnv is the env var holding the context of the suite span, as read by the OpenTelemetry SDKs
+const TraceParentEnv = "TRACEPARENT"
+
+const (
+	scopeName       = "github.com/getgauge/gauge"
+	defaultService  = "gauge"
+	serviceNameEnv  = "OTEL_SERVICE_NAME"
+	exceptionEvent  = "exception"
+	notCompletedMsg = "The execution ended before this completed."
+)
+
+// propagated holds the ids of the trace and suite span passed to the runners, for the next execution to use
+var propagated *span
+
+// Enabled tells if the trace of the execution should be exported
+func Enabled() bool {
+	return env.OtelTracesEndpoint() != "" || env.OtelTracesFile() != ""
+}
+
+// Propagate creates the ids of the trace of the next execution, and sets the context of its suite span in the
+// TRACEPARENT env var, so that the runners started afterwards inherit it.
+func Propagate() error {
+	propagated = &span{TraceID: newID(16), SpanID: newID(8)}
+	return os.Setenv(TraceParentEnv, fmt.Sprintf("00-%s-%s-01", propagated.TraceID, propagated.SpanID))
+}
+
+// ListenAndExport listens to the execution events and exports the trace once the suite ends
+func ListenAndExport(wg *sync.WaitGroup) {
+	ch := make(chan event.ExecutionEvent)
+	event.Register(ch, event.SuiteStart, event.SpecStart, event.ScenarioStart, event.ConceptStart, event.StepStart,
+		event.StepEnd, event.ConceptEnd, event.ScenarioEnd, event.SpecEnd, event.SuiteEnd)
+	wg.Add(1)
+	t := newTracer()
+
+	go func() {
+		for {
+			e := <-ch
+			t.handle(e, time.Now())
+			if e.Topic == event.SuiteEnd {
+				if err := export(t.finished); err != nil {
+					logger.Errorf(true, "Failed to export the trace of the execution. %s", err.Error())
+				}
+				wg.Done()
+				return
+			}
+		}
+	}()
+}
+
+// tracer builds the spans from the execution events. The spans in progress are kept per stream, as the streams of
+// a parallel execution raise their events at the same time.
+type tracer struct {
+	traceID  string
+	suite    *span
+	stacks   map[int][]*span
+	started  map[*span]time.Time
+	finished []*span
+}
+
+// newTracer uses the ids passed to the runners if any, else the trace is not connected to the runners
+// e.g. for the executions after the first one in watch mode.
+func newTracer() *tracer {
+	t := &tracer{traceID: newID(16), stacks: make(map[int][]*span), started: make(map[*span]time.Time)}
+	t.suite = &span{TraceID: t.traceID, SpanID: newID(8)}
+	if propagated != nil {
+		t.traceID, t.suite = propagated.TraceID, propagated
+		propagated = nil
+	}
+	return t
+}
+
+func (t *tracer) handle(e event.ExecutionEvent, now time.Time) {
+	switch e.Topic {
+	case event.SuiteStart:
+		t.suite.Name = filepath.Base(config.ProjectRoot)
+		t.suite.Attributes = []keyValue{stringAttr("gauge.type", "suite")}
+		t.begin(t.suite, now)
+	case event.SpecStart:
+		spec := e.Item.(*gauge.Specification)
+		s := t.start(e.Stream, "spec", spec.Heading.Value, now)
+		s.Attributes = append(s.Attributes, fileAttrs(spec.FileName, spec.Heading.LineNo)...)
+		s.Attributes = append(s.Attributes, tagsAttr(spec.Tags)...)
+	case event.ScenarioStart:
+		scn := e.Item.(*gauge.Scenario)
+		s := t.start(e.Stream, "scenario", scn.Heading.Value, now)
+		s.Attributes = append(s.Attributes, fileAttrs(e.ExecutionInfo.GetCurrentSpec().GetFileName(), scn.Heading.LineNo)...)
+		s.Attributes = append(s.Attributes, tagsAttr(scn.Tags)...)
+	case event.ConceptStart, event.StepStart:
+		step := e.Item.(*gauge.Step)
+		kind := "step"
+		if e.Topic == event.ConceptStart {
+			kind = "concept"
+		}
+		s := t.start(e.Stream, kind, step.LineText, now)
+		// the steps of a concept are in the concept file, which is not known unless set in the step
+		if file := step.FileName; file != "" || step.Parent == nil {
+			if file == "" {
+				file = e.ExecutionInfo.GetCurrentSpec().GetFileName()
+			}
+			s.Attributes = append(s.Attributes, fileAttrs(file, step.LineNo)...)
+		}
+	case event.StepEnd, event.ConceptEnd, event.ScenarioEnd, event.SpecEnd:
+		stack := t.stacks[e.Stream]
+		if len(stack) == 0 {
+			return
+		}
+		s := stack[len(stack)-1]
+		t.stacks[e.Stream] = stack[:len(stack)-1]
+		t.finish(s, e.Result, now)
+	case event.SuiteEnd:
+		for stream, stack := range t.stacks {
+			for i := len(stack) - 1; i >= 0; i-- {
+				stack[i].Status = status{Code: statusCodeError, Message: notCompletedMsg}
+				t.end(stack[i], now)
+			}
+			delete(t.stacks, stream)
+		}
+		t.finish(t.suite, e.Result, now)
+	}
+}
+
+// start begins a span, the child of the span in progress in the stream or of the suite span.
+func (t *tracer) start(stream int, kind, name string, now time.Time) *span {
+	parent := t.suite
+	if stack := t.stacks[stream]; len(stack) > 0 {
+		parent = stack[len(stack)-1]
+	}
+	s := &span{TraceID: t.traceID, SpanID: newID(8), ParentSpanID: parent.SpanID, Name: name,
+		Attributes: []keyValue{stringAttr("gauge.type", kind), intAttr("gauge.stream.id", stream)}}
+	t.begin(s, now)
+	t.stacks[stream] = append(t.stacks[stream], s)
+	return s
+}
+
+func (t *tracer) begin(s *span, now time.Time) {
+	s.Kind = spanKindInternal
+	s.StartTimeUnixNano = unixNano(now)
+	t.started[s] = now
+}
+
+func (t *tracer) end(s *span, now time.Time) {
+	if _, ok := t.started[s]; !ok {
+		t.begin(s, now)
+	}
+	s.EndTimeUnixNano = unixNano(now)
+	delete(t.started, s)
+	t.finished = append(t.finished, s)
+}
+
+// finish ends the span, with the failures of the hooks and of the step as exception events. The status of
+// a skipped span is left unset.
+func (t *tracer) finish(s *span, res result.Result, now time.Time) {
+	defer t.end(s, now)
+	if res == nil {
+		return
+	}
+	for _, f := range res.GetPreHook() {
+		s.addException(now, "before", f.GetErrorMessage(), f.GetStackTrace())
+	}
+	skipped, reason := false, ""
+	switch r := res.(type) {
+	case *result.StepResult:
+		if actual := r.ProtoStep.GetActualText(); actual != "" {
+			s.Name = actual
+		}
+		if er := r.ProtoStep.GetStepExecutionResult().GetExecutionResult(); er.GetFailed() && r.GetStepFailed() {
+			s.addException(now, "", er.GetErrorMessage(), er.GetStackTrace())
+		}
+		skipped, reason = r.ProtoStep.GetStepExecutionResult().GetSkipped(), r.ProtoStep.GetStepExecutionResult().GetSkippedReason()
+	case *result.ScenarioResult:
+		skipped = r.ProtoScenario.GetExecutionStatus() == gauge_messages.ExecutionStatus_SKIPPED
+		reason = strings.Join(r.ProtoScenario.GetSkipErrors(), "\n")
+	case *result.SpecResult:
+		skipped = r.Skipped
+		var errs []string
+		for _, e := range r.Errors {
+			errs = append(errs, e.GetMessage())
+		}
+		reason = strings.Join(errs, "\n")
+	case *result.SuiteResult:
+		s.Attributes = append(s.Attributes, boolAttr("gauge.interrupted", r.Interrupted))
+		if r.Environment != "" {
+			s.Attributes = append(s.Attributes, stringAttr("gauge.environment", r.Environment))
+		}
+	}
+	for _, f := range res.GetPostHook() {
+		s.addException(now, "after", f.GetErrorMessage(), f.GetStackTrace())
+	}
+	switch {
+	case res.GetFailed():
+		s.Status = status{Code: statusCodeError, Message: s.errorMessage()}
+	case skipped:
+		s.Attributes = append(s.Attributes, boolAttr("gauge.skipped", true))
+		if reason != "" {
+			s.Attributes = append(s.Attributes, stringAttr("gauge.skip.reason", reason))
+		}
+	default:
+		s.Status = status{Code: statusCodeOk}
+	}
+}
+
+// addException records a failure, following the OpenTelemetry conventions for exceptions. The hook is empty if
+// the failure is not of a hook.
+func (s *span) addException(now time.Time, hook, msg, stacktrace string) {
+	attrs := []keyValue{stringAttr("exception.message", msg)}
+	if stacktrace != "" {
+		attrs = append(attrs, stringAttr("exception.stacktrace", stacktrace))
+	}
+	if hook != "" {
+		attrs = append(attrs, stringAttr("gauge.hook", hook))
+	}
+	s.Events = append(s.Events, spanEvent{TimeUnixNano: unixNano(now), Name: exceptionEvent, Attributes: attrs})
+}
+
+// errorMessage returns the message of the first failure of the span
+func (s *span) errorMessage() string {
+	for _, e := range s.Events {
+		for _, a := range e.Attributes {
+			if a.Key == "exception.message" && a.Value.StringValue != nil {
+				return *a.Value.StringValue
+			}
+		}
+	}
+	return ""
+}
+
+func fileAttrs(file string, line int) []keyValue {
+	if file == "" {
+		return nil
+	}
+	if rel, err := filepath.Rel(config.ProjectRoot, file); err == nil && !strings.HasPrefix(rel, "..") {
+		file = rel
+	}
+	return []keyValue{stringAttr("code.filepath", filepath.ToSlash(file)), intAttr("code.lineno", line)}
+}
+
+func tagsAttr(tags *gauge.Tags) []keyValue {
+	if tags == nil || len(tags.Values()) == 0 {
+		return nil
+	}
+	return []keyValue{stringsAttr("gauge.tags", tags.Values())}
+}
+
+func newID(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		logger.Debugf(true, "Unable to generate a random trace id. %s", err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+func newTracesData(spans []*span) *tracesData {
+	service := os.Getenv(serviceNameEnv)
+	if service == "" {
+		service = defaultService
+	}
+	attrs := []keyValue{
+		stringAttr("service.name", service),
+		stringAttr("service.version", version.FullVersion()),
+		stringAttr("gauge.project.name", filepath.Base(config.ProjectRoot)),
+	}
+	return &tracesData{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: attrs},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: scopeName, Version: version.FullVersion()}, Spans: spans}},
+	}}}
+}
+
+// export writes the spans to the file and sends them to the endpoint configured.
+func export(spans []*span) error {
+	body, err := json.Marshal(newTracesData(spans))
+	if err != nil {
+		return err
+	}
+	if file := env.OtelTracesFile(); file != "" {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(config.ProjectRoot, file)
+		}
+		if err := os.MkdirAll(filepath.Dir(file), common.NewDirectoryPermissions); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(file, body, common.NewFilePermissions); err != nil {
+			return err
+		}
+		logger.Debugf(true, "Trace of the execution written to %s", file)
+	}
+	if endpoint := env.OtelTracesEndpoint(); endpoint != "" {
+		if err := post(endpoint, body); err != nil {
+			return err
+		}
+		logger.Debugf(true, "Trace of the execution exported to %s", endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package trace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/execution/event"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+)
+
+func attr(s *span, key string) *anyValue {
+	for _, a := range s.Attributes {
+		if a.Key == key {
+			return &a.Value
+		}
+	}
+	return nil
+}
+
+func executeFailingStep(t *tracer) {
+	specFile := filepath.Join(config.ProjectRoot, "specs", "example.spec")
+	ei := &gauge_messages.ExecutionInfo{CurrentSpec: &gauge_messages.SpecInfo{FileName: specFile}}
+	spec := &gauge.Specification{FileName: specFile, Heading: &gauge.Heading{Value: "Spec", LineNo: 1}, Tags: &gauge.Tags{RawValues: [][]string{{"smoke"}}}}
+	scn := &gauge.Scenario{Heading: &gauge.Heading{Value: "Scenario", LineNo: 3}}
+	step := &gauge.Step{LineText: "Say <word>", LineNo: 4}
+	stepRes := result.NewStepResult(&gauge_messages.ProtoStep{ActualText: `Say "hi"`, StepExecutionResult: &gauge_messages.ProtoStepExecutionResult{
+		ExecutionResult: &gauge_messages.ProtoExecutionResult{Failed: true, ErrorMessage: "boom", StackTrace: "at step"}}})
+	stepRes.SetStepFailure()
+	scnRes := &result.ScenarioResult{ProtoScenario: &gauge_messages.ProtoScenario{ExecutionStatus: gauge_messages.ExecutionStatus_FAILED}}
+	specRes := &result.SpecResult{ProtoSpec: &gauge_messages.ProtoSpec{}, IsFailed: true}
+	suiteRes := &result.SuiteResult{IsFailed: true}
+
+	now := time.Now()
+	for _, e := range []event.ExecutionEvent{
+		event.NewExecutionEvent(event.SuiteStart, nil, nil, 0, ei),
+		event.NewExecutionEvent(event.SpecStart, spec, specRes, 2, ei),
+		event.NewExecutionEvent(event.ScenarioStart, scn, scnRes, 2, ei),
+		event.NewExecutionEvent(event.StepStart, step, nil, 2, ei),
+		event.NewExecutionEvent(event.StepEnd, *step, stepRes, 2, ei),
+		event.NewExecutionEvent(event.ScenarioEnd, scn, scnRes, 2, ei),
+		event.NewExecutionEvent(event.SpecEnd, spec, specRes, 2, ei),
+		event.NewExecutionEvent(event.SuiteEnd, nil, suiteRes, 0, ei),
+	} {
+		now = now.Add(time.Millisecond)
+		t.handle(e, now)
+	}
+}
+
+func TestTracerNestsTheSpansAndRecordsFailures(t *testing.T) {
+	tr := newTracer()
+	executeFailingStep(tr)
+
+	if len(tr.finished) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(tr.finished))
+	}
+	step, scn, spec, suite := tr.finished[0], tr.finished[1], tr.finished[2], tr.finished[3]
+	if suite.ParentSpanID != "" || spec.ParentSpanID != suite.SpanID || scn.ParentSpanID != spec.SpanID || step.ParentSpanID != scn.SpanID {
+		t.Error("Expected the step, scenario and spec spans to be nested in the suite span")
+	}
+	for _, s := range tr.finished {
+		if s.TraceID != tr.traceID || len(s.SpanID) != 16 {
+			t.Errorf("Expected span %s to be in trace %s, got %s/%s", s.Name, tr.traceID, s.TraceID, s.SpanID)
+		}
+	}
+	if step.Name != `Say "hi"` || step.Status.Code != statusCodeError || step.Status.Message != "boom" {
+		t.Errorf("Expected the step span to be failed with the error message, got %+v", step)
+	}
+	if len(step.Events) != 1 || step.Events[0].Name != exceptionEvent {
+		t.Errorf("Expected an exception event for the step failure, got %+v", step.Events)
+	}
+	if v := attr(step, "code.filepath"); v == nil || *v.StringValue != "specs/example.spec" {
+		t.Errorf("Expected the spec file relative to the project as the file of the step, got %v", v)
+	}
+	if v := attr(step, "gauge.stream.id"); v == nil || *v.IntValue != "2" {
+		t.Errorf("Expected the stream id of the step, got %v", v)
+	}
+	if v := attr(spec, "gauge.tags"); v == nil || len(v.ArrayValue.Values) != 1 || *v.ArrayValue.Values[0].StringValue != "smoke" {
+		t.Errorf("Expected the tags of the spec, got %v", v)
+	}
+}
+
+func TestPropagatedContextIsUsedForTheNextExecutionOnly(t *testing.T) {
+	old, had := os.LookupEnv(TraceParentEnv)
+	defer func() {
+		if had {
+			os.Setenv(TraceParentEnv, old)
+		} else {
+			os.Unsetenv(TraceParentEnv)
+		}
+	}()
+	if err := Propagate(); err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(os.Getenv(TraceParentEnv), "-")
+
+	first, second := newTracer(), newTracer()
+
+	if len(parts) != 4 || first.traceID != parts[1] || first.suite.SpanID != parts[2] {
+		t.Errorf("Expected the first tracer to use the context %s, got %s/%s", os.Getenv(TraceParentEnv), first.traceID, first.suite.SpanID)
+	}
+	if second.traceID == first.traceID {
+		t.Error("Expected the next tracer to start a new trace")
+	}
+}
+
+func TestExportWritesFileAndSendsToEndpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var received tracesData
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+	os.Setenv("otel_traces_file", "reports/trace.json")
+	os.Setenv("otel_traces_endpoint", server.URL)
+	defer os.Unsetenv("otel_traces_file")
+	defer os.Unsetenv("otel_traces_endpoint")
+	oldRoot := config.ProjectRoot
+	config.ProjectRoot = dir
+	defer func() { config.ProjectRoot = oldRoot }()
+	tr := newTracer()
+	executeFailingStep(tr)
+
+	if err := export(tr.finished); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "reports", "trace.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written tracesData
+	if err := json.Unmarshal(b, &written); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(written.ResourceSpans[0].ScopeSpans[0].Spans); got != 4 {
+		t.Errorf("Expected 4 spans in the file, got %d", got)
+	}
+	if path != tracesPath || len(received.ResourceSpans) != 1 || len(received.ResourceSpans[0].ScopeSpans[0].Spans) != 4 {
+		t.Errorf("Expected the spans to be sent to %s, got %d resource spans at %s", tracesPath, len(received.ResourceSpans), path)
+	}
+}
@@ -0,0 +1,444 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/getgauge/common"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/parser"
+	"github.com/getgauge/gauge/runner"
+	"github.com/getgauge/gauge/util"
+	"github.com/getgauge/gauge/validation"
+)
+
+// Watch if true keeps the runner alive after the execution and re-runs the scenarios affected by
+// changes to the spec, concept and env properties files.
+var Watch bool
+
+// changes to a file are usually notified as multiple events, these are batched till the files are quiet for this interval.
+const watchDebounceInterval = 300 * time.Millisecond
+
+// watchRunner keeps the runner alive across executions in watch mode. Executors kill the runner once the suite ends.
+type watchRunner struct {
+	runner.Runner
+}
+
+func (r *watchRunner) Kill() error {
+	return nil
+}
+
+// restart replaces the runner kept alive across executions, when a step times out.
+func (r *watchRunner) restart() error {
+	rr, ok := r.Runner.(restarter)
+	if !ok {
+		killRunner(r.Runner)
+		return nil
+	}
+	return rr.restart()
+}
+
+// recoverFromCrash restarts the runner kept alive across executions, when it crashes.
+func (r *watchRunner) recoverFromCrash() error {
+	rr, ok := r.Runner.(crashRecoverer)
+	if !ok {
+		return fmt.Errorf("the runner cannot be restarted")
+	}
+	return rr.recoverFromCrash()
+}
+
+type watchedSpec struct {
+	spec  *gauge.Specification
+	lines []string
+}
+
+// specWatcher holds the specs and concepts of the last parse, which are compared against
+// the changed files to find the scenarios to execute again.
+type specWatcher struct {
+	specDirs []string
+	runner   runner.Runner
+	concepts *gauge.ConceptDictionary
+	specs    map[string]*watchedSpec
+	exitCode int
+}
+
+// scenarioSelection selects the given scenarios of a spec
+type scenarioSelection map[*gauge.Scenario]bool
+
+func (s scenarioSelection) Filter(item gauge.Item) bool {
+	return !s[item.(*gauge.Scenario)]
+}
+
+// executeAndWatch executes the specs and then again on every change till gauge is interrupted. The interrupt handler is
+// installed for each execution, a signal received while waiting for changes kills the runner and stops watching.
+func executeAndWatch(specDirs []string, res *validation.ValidationResult) int {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	w := &specWatcher{specDirs: specDirs, runner: res.Runner, specs: make(map[string]*watchedSpec)}
+	defer w.killRunner()
+	w.parseAll()
+	w.execute(res.SpecCollection, res.ErrMap, res.ParseOk)
+	if isInterrupted() {
+		return w.exitCode
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Errorf(true, "Unable to watch for file changes: %s", err.Error())
+		return w.exitCode
+	}
+	defer fw.Close()
+	for _, dir := range w.dirsToWatch() {
+		addDirToWatcher(fw, dir)
+		for _, nested := range util.FindAllNestedDirs(dir) {
+			addDirToWatcher(fw, nested)
+		}
+	}
+	for {
+		logger.Infof(true, "\nWatching for changes in specs, concepts and env. Press Ctrl+C to stop.")
+		files, ok := collectChanges(fw, signals)
+		if !ok {
+			logger.Infof(true, "Stopped watching for changes.")
+			return w.exitCode
+		}
+		w.onChange(files)
+		if isInterrupted() {
+			return w.exitCode
+		}
+	}
+}
+
+func (w *specWatcher) dirsToWatch() []string {
+	dirs := []string{filepath.Join(config.ProjectRoot, common.EnvDirectoryName)}
+	for _, d := range append(util.GetSpecDirs(), w.specDirs...) {
+		if !filepath.IsAbs(d) {
+			d = filepath.Join(config.ProjectRoot, d)
+		}
+		if !util.IsDir(d) {
+			d = filepath.Dir(d)
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs
+}
+
+func addDirToWatcher(fw *fsnotify.Watcher, dir string) {
+	if err := fw.Add(dir); err != nil {
+		logger.Errorf(false, "Unable to add directory %v to file watcher: %s", dir, err.Error())
+	}
+}
+
+// collectChanges blocks till a spec, concept or env properties file changes and returns all the files changed till the files are quiet.
+// It returns false if a signal to stop is received.
+func collectChanges(fw *fsnotify.Watcher, stop <-chan os.Signal) ([]string, bool) {
+	changed := make(map[string]bool)
+	var quiet <-chan time.Time
+	for {
+		select {
+		case e := <-fw.Events:
+			file, err := filepath.Abs(e.Name)
+			if err != nil {
+				logger.Errorf(false, "Failed to get abs file path for %s: %s", e.Name, err)
+				continue
+			}
+			if e.Op&fsnotify.Create != 0 && util.IsDir(file) {
+				addDirToWatcher(fw, file)
+				continue
+			}
+			if e.Op == fsnotify.Chmod || !(util.IsGaugeFile(file) || isEnvPropertiesFile(file)) {
+				continue
+			}
+			changed[file] = true
+			quiet = time.After(watchDebounceInterval)
+		case err := <-fw.Errors:
+			logger.Errorf(false, "Error event while watching specs %s", err)
+		case <-stop:
+			return nil, false
+		case <-quiet:
+			var files []string
+			for f := range changed {
+				files = append(files, f)
+			}
+			sort.Strings(files)
+			return files, true
+		}
+	}
+}
+
+func isEnvPropertiesFile(file string) bool {
+	envDir := filepath.Join(config.ProjectRoot, common.EnvDirectoryName) + string(filepath.Separator)
+	return filepath.Ext(file) == ".properties" && strings.HasPrefix(file, envDir)
+}
+
+func (w *specWatcher) onChange(files []string) {
+	var specFiles, conceptFiles []string
+	for _, f := range files {
+		logger.Debugf(true, "File changed: %s", f)
+		switch {
+		case isEnvPropertiesFile(f):
+			w.reloadEnv()
+			return
+		case util.IsConcept(f):
+			conceptFiles = append(conceptFiles, f)
+		case util.IsSpec(f):
+			specFiles = append(specFiles, f)
+		}
+	}
+	changedSpecs := make(map[string]bool)
+	for _, f := range specFiles {
+		changedSpecs[f] = true
+	}
+	var affectedConcepts map[string]bool
+	if len(conceptFiles) > 0 {
+		var ok bool
+		if affectedConcepts, ok = w.updateConcepts(conceptFiles); !ok {
+			return
+		}
+		for file, ws := range w.specs {
+			if specUsesConcepts(ws.spec, affectedConcepts) {
+				specFiles = append(specFiles, file)
+			}
+		}
+	}
+	specs, errMap, parseOk := w.parse(specFiles, changedSpecs, affectedConcepts)
+	if len(specs) == 0 {
+		logger.Infof(true, "No scenarios affected by the changes.")
+		return
+	}
+	specs, errMap = validation.ValidateParsedSpecs(specs, w.runner, w.concepts, errMap)
+	w.execute(gauge.NewSpecCollection(specs, false), errMap, parseOk)
+}
+
+// updateConcepts parses the concepts again and returns the concepts defined in the changed files, before and after the change.
+func (w *specWatcher) updateConcepts(files []string) (map[string]bool, bool) {
+	changed := make(map[string]bool)
+	for _, f := range files {
+		changed[f] = true
+	}
+	affected := conceptsDefinedIn(w.concepts, changed)
+	dict, res, err := parser.ParseConcepts()
+	if err != nil {
+		logger.Errorf(true, "Unable to parse concepts: %s", err.Error())
+		return nil, false
+	}
+	if !res.Ok {
+		logger.Errorf(true, "Fix the concept parse errors to continue execution.")
+		return nil, false
+	}
+	for c := range conceptsDefinedIn(dict, changed) {
+		affected[c] = true
+	}
+	w.concepts = dict
+	return affected, true
+}
+
+func conceptsDefinedIn(dict *gauge.ConceptDictionary, files map[string]bool) map[string]bool {
+	concepts := make(map[string]bool)
+	if dict == nil {
+		return concepts
+	}
+	for value, c := range dict.ConceptsMap {
+		if f, err := filepath.Abs(c.FileName); err == nil && files[f] {
+			concepts[value] = true
+		}
+	}
+	return concepts
+}
+
+func (w *specWatcher) parseAll() {
+	dict, _, err := parser.ParseConcepts()
+	if err != nil {
+		logger.Fatalf(true, "Unable to parse : %s", err.Error())
+	}
+	w.concepts = dict
+	w.specs = make(map[string]*watchedSpec)
+	w.parse(w.specDirs, nil, nil)
+}
+
+// parse parses the given spec files or dirs and returns the specs with only the scenarios affected by the changes.
+// All the scenarios of a spec are affected if it is new, or if its heading, contexts, tear down steps or data table changed.
+func (w *specWatcher) parse(sources []string, changedSpecs, affectedConcepts map[string]bool) ([]*gauge.Specification, *gauge.BuildErrors, bool) {
+	errMap := gauge.NewBuildErrors()
+	var existing []string
+	for _, s := range sources {
+		if !util.IsSpec(s) || common.FileExists(s) {
+			existing = append(existing, s)
+			continue
+		}
+		logger.Debugf(true, "Spec file removed: %s", s)
+		delete(w.specs, s)
+	}
+	if len(existing) == 0 {
+		return nil, errMap, true
+	}
+	parsed, failed := parser.ParseSpecs(existing, w.concepts, errMap)
+	filteredOut := make(map[string]bool)
+	for _, s := range existing {
+		if util.IsSpec(s) {
+			filteredOut[s] = true
+		}
+	}
+	var affected []*gauge.Specification
+	for _, spec := range parsed {
+		file, err := filepath.Abs(spec.FileName)
+		if err != nil {
+			file = spec.FileName
+		}
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			logger.Errorf(true, "Unable to read %s: %s", file, err.Error())
+			continue
+		}
+		delete(filteredOut, file)
+		current := &watchedSpec{spec: spec, lines: strings.Split(string(b), "\n")}
+		previous := w.specs[file]
+		w.specs[file] = current
+		selection := affectedScenarios(previous, current, changedSpecs[file], affectedConcepts)
+		if len(selection) == 0 {
+			continue
+		}
+		s, _ := spec.Filter(selection)
+		affected = append(affected, s)
+	}
+	for file := range filteredOut {
+		delete(w.specs, file)
+	}
+	return affected, errMap, !failed
+}
+
+func affectedScenarios(previous, current *watchedSpec, changed bool, concepts map[string]bool) scenarioSelection {
+	spec := current.spec
+	all := previous == nil || (changed && previous.preamble() != current.preamble()) ||
+		stepsUseConcepts(spec.Contexts, concepts) || stepsUseConcepts(spec.TearDownSteps, concepts)
+	var unchanged map[string]bool
+	if previous != nil {
+		unchanged = make(map[string]bool)
+		for _, scn := range previous.spec.Scenarios {
+			unchanged[previous.text(scn)] = true
+		}
+	}
+	selection := make(scenarioSelection)
+	for _, scn := range spec.Scenarios {
+		if all || (changed && !unchanged[current.text(scn)]) || stepsUseConcepts(scn.Steps, concepts) {
+			selection[scn] = true
+		}
+	}
+	return selection
+}
+
+// text returns the lines of the spec file in the span of the given scenario
+func (ws *watchedSpec) text(scn *gauge.Scenario) string {
+	start, end := scn.Span.Start-1, scn.Span.End
+	if start < 0 {
+		start = 0
+	}
+	if end > len(ws.lines) {
+		end = len(ws.lines)
+	}
+	if start > end {
+		return ""
+	}
+	return strings.Join(ws.lines[start:end], "\n")
+}
+
+// preamble returns the spec heading, tags, data table and contexts i.e. the lines before the first scenario, along with the tear down steps
+func (ws *watchedSpec) preamble() string {
+	end := len(ws.lines)
+	if len(ws.spec.Scenarios) > 0 && ws.spec.Scenarios[0].Span.Start-1 < end {
+		end = ws.spec.Scenarios[0].Span.Start - 1
+	}
+	p := strings.Join(ws.lines[:end], "\n")
+	for _, s := range ws.spec.TearDownSteps {
+		p += "\n" + s.LineText
+	}
+	return p
+}
+
+func specUsesConcepts(spec *gauge.Specification, concepts map[string]bool) bool {
+	if len(concepts) == 0 {
+		return false
+	}
+	if stepsUseConcepts(spec.Contexts, concepts) || stepsUseConcepts(spec.TearDownSteps, concepts) {
+		return true
+	}
+	for _, scn := range spec.Scenarios {
+		if stepsUseConcepts(scn.Steps, concepts) {
+			return true
+		}
+	}
+	return false
+}
+
+// stepsUseConcepts checks if any of the steps, or the steps of the concepts used, is one of the given concepts.
+// Steps which refer to a concept that was removed are considered as well, as they are no longer concepts.
+func stepsUseConcepts(steps []*gauge.Step, concepts map[string]bool) bool {
+	for _, s := range steps {
+		if concepts[s.Value] || (s.IsConcept && stepsUseConcepts(s.ConceptSteps, concepts)) {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadEnv restarts the runner with the changed env properties, as runners read them only on start, and executes all the specs.
+func (w *specWatcher) reloadEnv() {
+	logger.Infof(true, "Env properties changed, restarting the runner.")
+	err := env.ReloadEnv(env.CurrentEnvironments(), func(err error) {
+		logger.Fatalf(true, "Failed to load env. %s", err.Error())
+	})
+	if err != nil {
+		logger.Errorf(true, err.Error())
+		return
+	}
+	w.killRunner()
+	w.runner = newRestartableRunner(validation.StartRunner(false), 0)
+	w.parseAll()
+	specs := make([]*gauge.Specification, 0, len(w.specs))
+	for _, ws := range w.specs {
+		specs = append(specs, ws.spec)
+	}
+	errMap := gauge.NewBuildErrors()
+	specs, errMap = validation.ValidateParsedSpecs(sortedByFileName(specs), w.runner, w.concepts, errMap)
+	w.execute(gauge.NewSpecCollection(specs, false), errMap, true)
+}
+
+func sortedByFileName(specs []*gauge.Specification) []*gauge.Specification {
+	sort.Slice(specs, func(i, j int) bool { return specs[i].FileName < specs[j].FileName })
+	return specs
+}
+
+// execute runs the specs with the runner kept alive, the listeners of the execution events return once the suite ends.
+func (w *specWatcher) execute(specs *gauge.SpecCollection, errMap *gauge.BuildErrors, parseOk bool) {
+	stopHandlingInterrupts := handleInterrupts()
+	defer stopHandlingInterrupts()
+	wg := listenExecutionEvents(w.specDirs)
+	setPlannedScenarios(specs)
+	ei := newExecutionInfo(specs, &watchRunner{w.runner}, nil, errMap, false, 0)
+	logger.Debug(true, "Run started")
+	w.exitCode = printExecutionResult(ei.getExecutor().run(), parseOk)
+	wg.Wait()
+}
+
+func (w *specWatcher) killRunner() {
+	if err := w.runner.Kill(); err != nil {
+		logger.Errorf(false, "unable to kill runner: %s", err.Error())
+	}
+}
@@ -0,0 +1,96 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package execution
+
+import (
+	"strings"
+
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/parser"
+
+	. "gopkg.in/check.v1"
+)
+
+const watchedSpecText = `# Spec heading
+* context step
+
+## First scenario
+* first step
+
+## Second scenario
+* second step
+`
+
+func parseWatchedSpec(c *C, text string) *watchedSpec {
+	spec, res, err := new(parser.SpecParser).Parse(text, gauge.NewConceptDictionary(), "example.spec")
+	c.Assert(err, IsNil)
+	c.Assert(res.Ok, Equals, true)
+	return &watchedSpec{spec: spec, lines: strings.Split(text, "\n")}
+}
+
+func selectedHeadings(s scenarioSelection) []string {
+	var headings []string
+	for scn := range s {
+		headings = append(headings, scn.Heading.Value)
+	}
+	return headings
+}
+
+func (s *MySuite) TestAllScenariosOfNewSpecAreAffected(c *C) {
+	current := parseWatchedSpec(c, watchedSpecText)
+
+	c.Assert(len(affectedScenarios(nil, current, true, nil)), Equals, 2)
+}
+
+func (s *MySuite) TestOnlyChangedScenariosAreAffected(c *C) {
+	previous := parseWatchedSpec(c, watchedSpecText)
+	current := parseWatchedSpec(c, strings.Replace(watchedSpecText, "* second step", "* second step changed", 1))
+
+	c.Assert(selectedHeadings(affectedScenarios(previous, current, true, nil)), DeepEquals, []string{"Second scenario"})
+}
+
+func (s *MySuite) TestAllScenariosAreAffectedWhenContextChanges(c *C) {
+	previous := parseWatchedSpec(c, watchedSpecText)
+	current := parseWatchedSpec(c, strings.Replace(watchedSpecText, "* context step", "* another context step", 1))
+
+	c.Assert(len(affectedScenarios(previous, current, true, nil)), Equals, 2)
+}
+
+func (s *MySuite) TestScenariosUsingChangedConceptsAreAffected(c *C) {
+	previous := parseWatchedSpec(c, watchedSpecText)
+	current := parseWatchedSpec(c, watchedSpecText)
+
+	selection := affectedScenarios(previous, current, false, map[string]bool{"first step": true})
+
+	c.Assert(selectedHeadings(selection), DeepEquals, []string{"First scenario"})
+}
+
+func (s *MySuite) TestStepsUseNestedConcepts(c *C) {
+	nested := &gauge.Step{Value: "nested concept", IsConcept: true, ConceptSteps: []*gauge.Step{{Value: "a step"}}}
+	steps := []*gauge.Step{{Value: "outer concept", IsConcept: true, ConceptSteps: []*gauge.Step{nested}}}
+
+	c.Assert(stepsUseConcepts(steps, map[string]bool{"nested concept": true}), Equals, true)
+	c.Assert(stepsUseConcepts(steps, map[string]bool{"other concept": true}), Equals, false)
+}
+
+func (s *MySuite) TestValidateFlagsWithWatchInParallel(c *C) {
+	InParallel = true
+	Watch = true
+	NumberOfExecutionStreams = 2
+	defer func() { InParallel, Watch = false, false }()
+
+	err := validateFlags()
+
+	c.Assert(err.Error(), Equals, "--watch cannot be used along with --parallel")
+}
+
+func (s *MySuite) TestCrashedRunnerIsRecoveredInWatchMode(c *C) {
+	r := &crashingMockRunner{mockRunner: &mockRunner{}}
+
+	c.Assert(recoverRunner(&watchRunner{r}), Equals, true)
+	c.Assert(r.recoveries, Equals, 1)
+}
@@ -0,0 +1,89 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package filter
+
+import (
+	"strings"
+
+	"github.com/getgauge/gauge/gauge"
+)
+
+const lockTagPrefix = "lock:"
+
+// Locks returns the resources locked by the spec, given by the tags of the spec or any of its scenarios in the form
+// lock:<resource>. Specs holding the same lock are never executed at the same time in parallel execution.
+func Locks(spec *gauge.Specification) []string {
+	var locks []string
+	seen := make(map[string]bool)
+	add := func(tags *gauge.Tags) {
+		if tags == nil {
+			return
+		}
+		for _, tag := range tags.Values() {
+			tag = strings.TrimSpace(tag)
+			if len(tag) <= len(lockTagPrefix) || !strings.EqualFold(tag[:len(lockTagPrefix)], lockTagPrefix) {
+				continue
+			}
+			if l := strings.TrimSpace(tag[len(lockTagPrefix):]); l != "" && !seen[l] {
+				seen[l] = true
+				locks = append(locks, l)
+			}
+		}
+	}
+	add(spec.Tags)
+	for _, scn := range spec.Scenarios {
+		add(scn.Tags)
+	}
+	return locks
+}
+
+// HasLocks tells if any of the specs holds a lock.
+func HasLocks(specs []*gauge.Specification) bool {
+	for _, spec := range specs {
+		if len(Locks(spec)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// lockGroups groups the specs sharing a lock, either directly or through other specs, as they have to be executed
+// in the same stream. Every spec without a lock is a group of its own. The groups are ordered by their first spec.
+func lockGroups(specs []*gauge.Specification) [][]*gauge.Specification {
+	parent := make([]int, len(specs))
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	holder := make(map[string]int)
+	for i, spec := range specs {
+		parent[i] = i
+		for _, l := range Locks(spec) {
+			if j, ok := holder[l]; ok {
+				parent[find(i)] = find(j)
+			} else {
+				holder[l] = i
+			}
+		}
+	}
+	var groups [][]*gauge.Specification
+	index := make(map[int]int)
+	for i, spec := range specs {
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], spec)
+	}
+	return groups
+}
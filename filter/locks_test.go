@@ -0,0 +1,81 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package filter
+
+import (
+	"github.com/getgauge/gauge/gauge"
+	. "gopkg.in/check.v1"
+)
+
+func lockedSpec(file string, locks ...string) *gauge.Specification {
+	spec := &gauge.Specification{FileName: file, Tags: &gauge.Tags{}}
+	for _, l := range locks {
+		spec.Tags.Add([]string{"lock:" + l})
+	}
+	return spec
+}
+
+func (s *MySuite) TestLocksFromSpecAndScenarioTags(c *C) {
+	spec := &gauge.Specification{
+		Tags: &gauge.Tags{RawValues: [][]string{{"smoke", "Lock: payments-db "}}},
+		Scenarios: []*gauge.Scenario{
+			{Tags: &gauge.Tags{RawValues: [][]string{{"lock:port-8080", "lock:payments-db"}}}},
+			{},
+			{Tags: &gauge.Tags{RawValues: [][]string{{"lock:", "locked"}}}},
+		},
+	}
+
+	c.Assert(Locks(spec), DeepEquals, []string{"payments-db", "port-8080"})
+	c.Assert(Locks(&gauge.Specification{}), IsNil)
+}
+
+func (s *MySuite) TestLockGroupsJoinSpecsSharingLocksTransitively(c *C) {
+	a := lockedSpec("a", "db")
+	b := lockedSpec("b")
+	d := lockedSpec("d", "port")
+	e := lockedSpec("e", "db", "port")
+	f := lockedSpec("f", "queue")
+
+	groups := lockGroups([]*gauge.Specification{a, b, d, e, f})
+
+	c.Assert(groups, DeepEquals, [][]*gauge.Specification{{a, d, e}, {b}, {f}})
+}
+
+func (s *MySuite) TestDistributeSpecsKeepsLockedSpecsInOneCollection(c *C) {
+	specs := []*gauge.Specification{lockedSpec("a", "db"), lockedSpec("b"), lockedSpec("c", "db"), lockedSpec("d"), lockedSpec("e", "db")}
+
+	collections := DistributeSpecs(specs, 3)
+
+	c.Assert(collections[0].Specs(), DeepEquals, []*gauge.Specification{specs[0], specs[2], specs[4]})
+	c.Assert(collections[1].Specs(), DeepEquals, []*gauge.Specification{specs[1]})
+	c.Assert(collections[2].Specs(), DeepEquals, []*gauge.Specification{specs[3]})
+}
+
+func (s *MySuite) TestDistributeSpecsLeavesCollectionsEmptyIfAllSpecsShareALock(c *C) {
+	specs := []*gauge.Specification{lockedSpec("a", "db"), lockedSpec("b", "db")}
+
+	collections := DistributeSpecs(specs, 2)
+
+	c.Assert(collections[0].Specs(), DeepEquals, specs)
+	c.Assert(collections[1], IsNil)
+}
+
+func (s *MySuite) TestDistributeSpecsByTimeKeepsLockedSpecsTogether(c *C) {
+	t := newSpecTimings(previousRun())
+	slow := specWithScenarios("slow.spec", "s1", "s2")
+	medium := specWithScenarios("medium.spec", "m1")
+	fast := specWithScenarios("fast.spec", "f1", "f2")
+	medium.Tags = &gauge.Tags{RawValues: [][]string{{"lock:db"}}}
+	fast.Tags = &gauge.Tags{RawValues: [][]string{{"lock:db"}}}
+	other := specWithScenarios("other.spec", "o1")
+
+	collections := distributeByTime([]*gauge.Specification{fast, other, medium, slow}, 3, t)
+
+	c.Assert(collections[0].Specs(), DeepEquals, []*gauge.Specification{slow})
+	c.Assert(collections[1].Specs(), DeepEquals, []*gauge.Specification{medium, fast})
+	c.Assert(collections[2].Specs(), DeepEquals, []*gauge.Specification{other})
+}
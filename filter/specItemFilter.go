@@ -7,17 +7,9 @@
 package filter
 
 import (
-	"errors"
-	"github.com/getgauge/gauge/env"
-	"go/constant"
-	"go/token"
-	"go/types"
-	"regexp"
-	"strconv"
 	"strings"
 
-	"fmt"
-
+	"github.com/getgauge/gauge/env"
 	"github.com/getgauge/gauge/gauge"
 	"github.com/getgauge/gauge/logger"
 )
@@ -28,6 +20,8 @@ type scenarioFilterBasedOnSpan struct {
 type ScenarioFilterBasedOnTags struct {
 	specTags      []string
 	tagExpression string
+	// exp is the syntax tree of the tag expression, which is nil if the expression is invalid
+	exp tagExpression
 }
 
 type scenarioFilterBasedOnName struct {
@@ -47,8 +41,16 @@ func (filter *scenarioFilterBasedOnSpan) Filter(item gauge.Item) bool {
 	return true
 }
 
+// NewScenarioFilterBasedOnTags parses the tag expression once, to filter the scenarios of a spec with the given tags.
+// An invalid expression matches no scenario.
 func NewScenarioFilterBasedOnTags(specTags []string, tagExp string) *ScenarioFilterBasedOnTags {
-	return &ScenarioFilterBasedOnTags{specTags, tagExp}
+	exp, _ := parseTagExpression(tagExp)
+	return &ScenarioFilterBasedOnTags{specTags: specTags, tagExpression: tagExp, exp: exp}
+}
+
+// ForSpec returns a filter for the scenarios of a spec with the given tags, which reuses the parsed tag expression.
+func (filter *ScenarioFilterBasedOnTags) ForSpec(specTags []string) *ScenarioFilterBasedOnTags {
+	return &ScenarioFilterBasedOnTags{specTags: specTags, tagExpression: filter.tagExpression, exp: filter.exp}
 }
 
 func (filter *ScenarioFilterBasedOnTags) Filter(item gauge.Item) bool {
@@ -68,9 +70,6 @@ func (filter *scenarioFilterBasedOnName) Filter(item gauge.Item) bool {
 }
 
 func sanitize(tag string) string {
-	if _, err := strconv.ParseBool(tag); err == nil {
-		return fmt.Sprintf("{%s}", tag)
-	}
 	if env.AllowCaseSensitiveTags() {
 		return tag
 	}
@@ -78,128 +77,26 @@ func sanitize(tag string) string {
 }
 
 func (filter *ScenarioFilterBasedOnTags) filterTags(stags []string) bool {
-	tagsMap := make(map[string]bool)
-	for _, tag := range stags {
-		tag = sanitize(tag)
-		tagsMap[strings.Replace(tag, " ", "", -1)] = true
-	}
-	filter.replaceSpecialChar()
-	value, _ := filter.formatAndEvaluateExpression(tagsMap, filter.isTagPresent)
-	return value
-}
-
-func (filter *ScenarioFilterBasedOnTags) replaceSpecialChar() {
-	filter.tagExpression = strings.Replace(strings.Replace(strings.Replace(strings.Replace(filter.tagExpression, " ", "", -1), ",", "&", -1), "&&", "&", -1), "||", "|", -1)
-}
-
-func (filter *ScenarioFilterBasedOnTags) formatAndEvaluateExpression(tagsMap map[string]bool, isTagQualified func(tagsMap map[string]bool, tagName string) bool) (bool, error) {
-	tagExpressionParts, tags := filter.parseTagExpression()
-	for _, tag := range tags {
-		for i, txp := range tagExpressionParts {
-			if strings.TrimSpace(txp) == strings.TrimSpace(tag) {
-				tagExpressionParts[i] = strconv.FormatBool(isTagQualified(tagsMap, strings.TrimSpace(tag)))
-			}
-		}
-	}
-	return filter.evaluateExp(filter.handleNegation(strings.Join(tagExpressionParts, "")))
-}
-
-func (filter *ScenarioFilterBasedOnTags) handleNegation(tagExpression string) string {
-	tagExpression = strings.Replace(strings.Replace(tagExpression, "!true", "false", -1), "!false", "true", -1)
-	for strings.Contains(tagExpression, "!(") {
-		tagExpression = filter.evaluateBrackets(tagExpression)
-	}
-	return tagExpression
-}
-
-func (filter *ScenarioFilterBasedOnTags) evaluateBrackets(tagExpression string) string {
-	if strings.Contains(tagExpression, "!(") {
-		innerText := filter.resolveBracketExpression(tagExpression)
-		return strings.Replace(tagExpression, "!("+innerText+")", filter.evaluateBrackets(innerText), -1)
-	}
-	value, _ := filter.evaluateExp(tagExpression)
-	return strconv.FormatBool(!value)
-}
-
-func (filter *ScenarioFilterBasedOnTags) resolveBracketExpression(tagExpression string) string {
-	indexOfOpenBracket := strings.Index(tagExpression, "!(") + 1
-	bracketStack := make([]string, 0)
-	i := indexOfOpenBracket
-	for ; i < len(tagExpression); i++ {
-		if tagExpression[i] == '(' {
-			bracketStack = append(bracketStack, "(")
-		} else if tagExpression[i] == ')' {
-			bracketStack = bracketStack[:len(bracketStack)-1]
-		}
-		if len(bracketStack) == 0 {
-			break
-		}
-	}
-	return tagExpression[indexOfOpenBracket+1 : i]
-}
-
-func (filter *ScenarioFilterBasedOnTags) evaluateExp(tagExpression string) (bool, error) {
-	tre := regexp.MustCompile("true")
-	fre := regexp.MustCompile("false")
-
-	s := fre.ReplaceAllString(tre.ReplaceAllString(tagExpression, "1"), "0")
-
-	val, err := types.Eval(token.NewFileSet(), nil, 0, s)
-	if err != nil {
-		return false, errors.New("Invalid Expression.\n" + err.Error())
-	}
-	res, _ := constant.Uint64Val(val.Value)
-
-	var final bool
-	if res == 1 {
-		final = true
-	} else {
-		final = false
-	}
-
-	return final, nil
-}
-
-func (filter *ScenarioFilterBasedOnTags) isTagPresent(tagsMap map[string]bool, tagName string) bool {
-	_, ok := tagsMap[tagName]
-	return ok
-}
-
-func (filter *ScenarioFilterBasedOnTags) parseTagExpression() (tagExpressionParts []string, tags []string) {
-	isValidOperator := func(r rune) bool { return r == '&' || r == '|' || r == '(' || r == ')' || r == '!' }
-	var word string
-	var wordValue = func() string {
-		return sanitize(strings.TrimSpace(word))
-	}
-	for _, c := range filter.tagExpression {
-		c1, _ := strconv.Unquote(strconv.QuoteRuneToASCII(c))
-		if isValidOperator(c) {
-			if word != "" {
-				tagExpressionParts = append(tagExpressionParts, wordValue())
-				tags = append(tags, wordValue())
-			}
-			tagExpressionParts = append(tagExpressionParts, c1)
-			word = ""
-		} else {
-			word += c1
-		}
+	if filter.exp == nil {
+		return false
 	}
-	if word != "" {
-		tagExpressionParts = append(tagExpressionParts, wordValue())
-		tags = append(tags, wordValue())
+	tags := make([]string, 0, len(stags))
+	for _, tag := range stags {
+		tags = append(tags, removeSpaces(sanitize(tag)))
 	}
-	return
+	return filter.exp.eval(tags)
 }
 
 func filterSpecsByTags(specs []*gauge.Specification, tagExpression string) ([]*gauge.Specification, []*gauge.Specification) {
 	filteredSpecs := make([]*gauge.Specification, 0)
 	otherSpecs := make([]*gauge.Specification, 0)
+	tagFilter := NewScenarioFilterBasedOnTags(nil, tagExpression)
 	for _, spec := range specs {
 		tagValues := make([]string, 0)
 		if spec.Tags != nil {
 			tagValues = spec.Tags.Values()
 		}
-		specWithFilteredItems, specWithOtherItems := spec.Filter(NewScenarioFilterBasedOnTags(tagValues, tagExpression))
+		specWithFilteredItems, specWithOtherItems := spec.Filter(tagFilter.ForSpec(tagValues))
 		if len(specWithFilteredItems.Scenarios) != 0 {
 			filteredSpecs = append(filteredSpecs, specWithFilteredItems)
 		}
@@ -211,10 +108,7 @@ func filterSpecsByTags(specs []*gauge.Specification, tagExpression string) ([]*g
 }
 
 func validateTagExpression(tagExpression string) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: tagExpression}
-	filter.replaceSpecialChar()
-	_, err := filter.formatAndEvaluateExpression(make(map[string]bool), func(a map[string]bool, b string) bool { return true })
-	if err != nil {
+	if err := ValidateTagExpression(tagExpression); err != nil {
 		logger.Fatalf(true, err.Error())
 	}
 }
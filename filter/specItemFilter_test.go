@@ -30,93 +30,156 @@ func after() {
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionWithTwoTags(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "tag1 & tag3"}
+	filter := NewScenarioFilterBasedOnTags(nil, "tag1 & tag3")
 	c.Assert(filter.filterTags([]string{"tag1", "tag2"}), Equals, false)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionWithComplexTagExpression(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "tag1 & ((tag3 | tag2) & (tag5 | tag4 | tag3) & tag7) | tag6"}
+	filter := NewScenarioFilterBasedOnTags(nil, "tag1 & ((tag3 | tag2) & (tag5 | tag4 | tag3) & tag7) | tag6")
 	c.Assert(filter.filterTags([]string{"tag1", "tag2", "tag7", "tag4"}), Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionWithFailingTagExpression(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "tag1 & ((tag3 | tag2) & (tag5 | tag4 | tag3) & tag7) & tag6"}
+	filter := NewScenarioFilterBasedOnTags(nil, "tag1 & ((tag3 | tag2) & (tag5 | tag4 | tag3) & tag7) & tag6")
 	c.Assert(filter.filterTags([]string{"tag1", "tag2", "tag7", "tag4"}), Equals, false)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionWithWrongTagExpression(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "tag1 & ((((tag3 | tag2) & (tag5 | tag4 | tag3) & tag7) & tag6"}
+	filter := NewScenarioFilterBasedOnTags(nil, "tag1 & ((((tag3 | tag2) & (tag5 | tag4 | tag3) & tag7) & tag6")
 	c.Assert(filter.filterTags([]string{"tag1", "tag2", "tag7", "tag4"}), Equals, false)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingOfSpaces(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "tag 1 & tag3"}
+	filter := NewScenarioFilterBasedOnTags(nil, "tag 1 & tag3")
 	c.Assert(filter.filterTags([]string{"tag 1", "tag3"}), Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingLogicalNotOperator(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "!tag 1 & tag3"}
+	filter := NewScenarioFilterBasedOnTags(nil, "!tag 1 & tag3")
 	c.Assert(filter.filterTags([]string{"tag2", "tag3"}), Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingManyLogicalNotOperator(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "!(!(tag 1 | !(tag6 | !(tag5))) & tag2)"}
-	value := filter.filterTags([]string{"tag2", "tag4"})
-	c.Assert(value, Equals, true)
+	filter := NewScenarioFilterBasedOnTags(nil, "!(!(tag 1 | !(tag6 | !(tag5))) & tag2)")
+	c.Assert(filter.filterTags([]string{"tag2", "tag4"}), Equals, false)
+	c.Assert(filter.filterTags([]string{"tag2", "tag1"}), Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingParallelLogicalNotOperator(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "!(tag1) & ! (tag3 & ! (tag3))"}
+	filter := NewScenarioFilterBasedOnTags(nil, "!(tag1) & ! (tag3 & ! (tag3))")
 	value := filter.filterTags([]string{"tag2", "tag4"})
 	c.Assert(value, Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingComma(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "tag 1 , tag3"}
+	filter := NewScenarioFilterBasedOnTags(nil, "tag 1 , tag3")
 	c.Assert(filter.filterTags([]string{"tag2", "tag3"}), Equals, false)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingCommaGivesTrue(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "tag 1 , tag3"}
+	filter := NewScenarioFilterBasedOnTags(nil, "tag 1 , tag3")
 	c.Assert(filter.filterTags([]string{"tag1", "tag3"}), Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingTrueAndFalseAsTagNames(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "true , false"}
+	filter := NewScenarioFilterBasedOnTags(nil, "true , false")
 	c.Assert(filter.filterTags([]string{"true", "false"}), Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingTrueAndFalseAsTagNamesWithNegation(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "!true"}
+	filter := NewScenarioFilterBasedOnTags(nil, "!true")
 	c.Assert(filter.filterTags(nil), Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionConsistingSpecialCharacters(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "a && b || c | b & b"}
+	filter := NewScenarioFilterBasedOnTags(nil, "a && b || c | b & b")
 	c.Assert(filter.filterTags([]string{"a", "b"}), Equals, true)
 }
 
 func (s *MySuite) TestToEvaluateTagExpressionWhenTagIsSubsetOfTrueOrFalse(c *C) {
 	// https://github.com/getgauge/gauge/issues/667
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "b || c | b & b && a"}
+	filter := NewScenarioFilterBasedOnTags(nil, "b || c | b & b && a")
 	c.Assert(filter.filterTags([]string{"a", "b"}), Equals, true)
 }
 
-func (s *MySuite) TestParseTagExpression(c *C) {
-	filter := &ScenarioFilterBasedOnTags{tagExpression: "b || c | b & b && a"}
-	txps, tags := filter.parseTagExpression()
+func (s *MySuite) TestLexTagExpression(c *C) {
+	tokens := lexTagExpression("b || c | b & b && a")
+
+	expectedValues := []string{"b", "||", "c", "|", "b", "&", "b", "&&", "a", ""}
+	expectedCols := []int{1, 3, 6, 8, 10, 12, 14, 16, 19, 20}
+
+	c.Assert(len(tokens), Equals, len(expectedValues))
+	for i, t := range tokens {
+		c.Assert(t.value, Equals, expectedValues[i])
+		c.Assert(t.col, Equals, expectedCols[i])
+	}
+	c.Assert(tokens[len(tokens)-1].kind, Equals, tokenEOF)
+}
+
+func (s *MySuite) TestToEvaluateTagExpressionWithPrecedence(c *C) {
+	filter := NewScenarioFilterBasedOnTags(nil, "a | b & !c")
+	c.Assert(filter.filterTags([]string{"a", "c"}), Equals, true)
+	c.Assert(filter.filterTags([]string{"b", "c"}), Equals, false)
+	c.Assert(filter.filterTags([]string{"b"}), Equals, true)
+}
+
+func (s *MySuite) TestToEvaluateTagExpressionWithWildcards(c *C) {
+	filter := NewScenarioFilterBasedOnTags(nil, "smoke-* & !wip?")
+	c.Assert(filter.filterTags([]string{"smoke-login"}), Equals, true)
+	c.Assert(filter.filterTags([]string{"smoke-login", "wip1"}), Equals, false)
+	c.Assert(filter.filterTags([]string{"smoke"}), Equals, false)
+	c.Assert(filter.filterTags([]string{"smoke.login"}), Equals, false)
+}
+
+func (s *MySuite) TestToEvaluateTagExpressionWithKeyValueTags(c *C) {
+	filter := NewScenarioFilterBasedOnTags(nil, "owner:payments | owner:bill*")
+	c.Assert(filter.filterTags([]string{"owner:payments"}), Equals, true)
+	c.Assert(filter.filterTags([]string{"owner:billing"}), Equals, true)
+	c.Assert(filter.filterTags([]string{"owner:search", "payments"}), Equals, false)
+}
 
-	expectedTxps := []string{"b", "|", "|", "c", "|", "b", "&", "b", "&", "&", "a"}
-	expectedTags := []string{"b", "c", "b", "b", "a"}
+func (s *MySuite) TestToEvaluateTagExpressionWithValueComparison(c *C) {
+	filter := NewScenarioFilterBasedOnTags(nil, "priority:>=2 & priority:<10")
+	c.Assert(filter.filterTags([]string{"priority:2"}), Equals, true)
+	c.Assert(filter.filterTags([]string{"priority:9.5"}), Equals, true)
+	c.Assert(filter.filterTags([]string{"priority:10"}), Equals, false)
+	c.Assert(filter.filterTags([]string{"priority:1"}), Equals, false)
+	c.Assert(filter.filterTags([]string{"severity:5"}), Equals, false)
 
-	for i, t := range txps {
-		c.Assert(expectedTxps[i], Equals, t)
+	filter = NewScenarioFilterBasedOnTags(nil, "release:>2020.b")
+	c.Assert(filter.filterTags([]string{"release:2020.c"}), Equals, true)
+	c.Assert(filter.filterTags([]string{"release:2020.a"}), Equals, false)
+}
+
+func (s *MySuite) TestValidateTagExpressionReportsColumn(c *C) {
+	cases := []struct {
+		exp     string
+		column  int
+		message string
+	}{
+		{"(tag1 & tag2", 13, "expected ')' to close '(' at column 1, found end of expression"},
+		{"tag1 & tag2)", 12, "unexpected ')'"},
+		{"tag1 & | tag2", 8, "expected a tag or '(', found '|'"},
+		{"tag1 &", 7, "expected a tag or '(', found end of expression"},
+		{"tag1 ()", 6, "unexpected '('"},
+		{"  ", 3, "expected a tag"},
+		{"tag1 & priority:>=", 8, "missing value to compare after '>=' in tag 'priority:>='"},
 	}
-	for i, t := range tags {
-		c.Assert(expectedTags[i], Equals, t)
+	for _, t := range cases {
+		err := ValidateTagExpression(t.exp)
+		c.Assert(err, NotNil, Commentf(t.exp))
+		e := err.(TagExpressionError)
+		c.Assert(e.Column, Equals, t.column, Commentf(t.exp))
+		c.Assert(e.Message, Equals, t.message, Commentf(t.exp))
 	}
 }
 
+func (s *MySuite) TestTagExpressionErrorPointsToColumn(c *C) {
+	err := ValidateTagExpression("a & | b")
+
+	c.Assert(err.Error(), Equals, "Invalid tag expression 'a & | b': expected a tag or '(', found '|' at column 5.\n  a & | b\n      ^")
+}
+
 func (s *MySuite) TestScenarioSpanFilter(c *C) {
 	scenario1 := &gauge.Scenario{
 		Heading: &gauge.Heading{Value: "First Scenario"},
@@ -543,6 +606,16 @@ func (s *MySuite) TestFilterMixedCaseTags(c *C) {
 	c.Assert(evaluateTrue, Equals, true)
 }
 
+func (s *MySuite) TestTagFilterForSpecReusesParsedExpression(c *C) {
+	tagFilter := NewScenarioFilterBasedOnTags(nil, "abcd & foo")
+	specFilter := tagFilter.ForSpec([]string{"foo"})
+
+	c.Assert(tagFilter.exp, NotNil)
+	c.Assert(specFilter.exp, Equals, tagFilter.exp)
+	c.Assert(specFilter.Filter(&gauge.Scenario{Tags: &gauge.Tags{RawValues: [][]string{{"abcd"}}}}), Equals, false)
+	c.Assert(NewScenarioFilterBasedOnTags(nil, "abcd &").exp, IsNil)
+}
+
 func (s *MySuite) TestSanitizeTags(c *C) {
 	specTags := []string{"abcd", "foo", "bar", "foo bar"}
 	tagFilter := NewScenarioFilterBasedOnTags(specTags, "abcd & foo bar | true")
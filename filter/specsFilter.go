@@ -76,10 +76,22 @@ func (scenarioFilter *scenariosFilter) filter(specs []*gauge.Specification) []*g
 	return specs
 }
 
+// DistributeSpecs splits the specs into the given number of collections. Specs are balanced by their previous
+// execution time if a timings file is given, else they are assigned in a round robin manner.
+// Specs sharing a lock are assigned to the same collection, so some of the collections can be nil.
 func DistributeSpecs(specifications []*gauge.Specification, distributions int) []*gauge.SpecCollection {
+	if t := timings(specifications); t != nil {
+		return distributeByTime(specifications, distributions, t)
+	}
 	s := make([]*gauge.SpecCollection, distributions)
+	collection := make(map[*gauge.Specification]int)
+	for i, group := range lockGroups(specifications) {
+		for _, spec := range group {
+			collection[spec] = i % distributions
+		}
+	}
 	for i := 0; i < len(specifications); i++ {
-		mod := i % distributions
+		mod := collection[specifications[i]]
 		if s[mod] == nil {
 			s[mod] = gauge.NewSpecCollection(make([]*gauge.Specification, 0), false)
 		}
@@ -0,0 +1,323 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+Tag expressions are parsed with the following grammar. Whitespace inside a tag is ignored, i.e. `foo bar` is same as `foobar`.
+
+	expression := and ( ('|' | '||') and )*
+	and        := unary ( ('&' | '&&' | ',') unary )*
+	unary      := '!' unary | primary
+	primary    := tag | '(' expression ')'
+	tag        := name | name ':' value | name ':' ('>' | '>=' | '<' | '<=') value
+
+Names and values can contain `*` and `?` wildcards, e.g. `smoke-*` or `owner:pay*`.
+*/
+
+type tokenKind int
+
+const (
+	tokenTag tokenKind = iota
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpenParen
+	tokenCloseParen
+	tokenEOF
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenTag:
+		return "tag"
+	case tokenAnd:
+		return "'&'"
+	case tokenOr:
+		return "'|'"
+	case tokenNot:
+		return "'!'"
+	case tokenOpenParen:
+		return "'('"
+	case tokenCloseParen:
+		return "')'"
+	}
+	return "end of expression"
+}
+
+type token struct {
+	kind  tokenKind
+	value string
+	col   int
+}
+
+// TagExpressionError is returned when a tag expression cannot be parsed. Column is 1 based.
+type TagExpressionError struct {
+	Expression string
+	Column     int
+	Message    string
+}
+
+func (e TagExpressionError) Error() string {
+	return fmt.Sprintf("Invalid tag expression '%s': %s at column %d.\n  %s\n  %s^", e.Expression, e.Message, e.Column, e.Expression, strings.Repeat(" ", e.Column-1))
+}
+
+func isTagOperator(r rune) bool {
+	return r == '&' || r == '|' || r == ',' || r == '!' || r == '(' || r == ')'
+}
+
+func lexTagExpression(exp string) []token {
+	var tokens []token
+	runes := []rune(exp)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		col := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r == '&' || r == '|':
+			kind := tokenAnd
+			if r == '|' {
+				kind = tokenOr
+			}
+			v := string(r)
+			if i+1 < len(runes) && runes[i+1] == r {
+				v += string(r)
+				i++
+			}
+			tokens = append(tokens, token{kind: kind, value: v, col: col})
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenAnd, value: ",", col: col})
+		case r == '!':
+			tokens = append(tokens, token{kind: tokenNot, value: "!", col: col})
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpenParen, value: "(", col: col})
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenCloseParen, value: ")", col: col})
+		default:
+			j := i
+			for j < len(runes) && !isTagOperator(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenTag, value: removeSpaces(string(runes[i:j])), col: col})
+			i = j - 1
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, col: len(runes) + 1})
+}
+
+func removeSpaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+type tagExpression interface {
+	eval(tags []string) bool
+}
+
+type orExpression struct{ left, right tagExpression }
+type andExpression struct{ left, right tagExpression }
+type notExpression struct{ operand tagExpression }
+
+func (e orExpression) eval(tags []string) bool  { return e.left.eval(tags) || e.right.eval(tags) }
+func (e andExpression) eval(tags []string) bool { return e.left.eval(tags) && e.right.eval(tags) }
+func (e notExpression) eval(tags []string) bool { return !e.operand.eval(tags) }
+
+type tagParser struct {
+	exp    string
+	tokens []token
+	pos    int
+}
+
+// parseTagExpression builds the syntax tree for the given tag expression.
+func parseTagExpression(exp string) (tagExpression, error) {
+	p := &tagParser{exp: exp, tokens: lexTagExpression(exp)}
+	if p.peek().kind == tokenEOF {
+		return nil, p.errorf(p.peek(), "expected a tag")
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %s", t.kind)
+	}
+	return e, nil
+}
+
+// ValidateTagExpression returns an error describing where the given tag expression is invalid, if it is.
+func ValidateTagExpression(exp string) error {
+	_, err := parseTagExpression(exp)
+	return err
+}
+
+func (p *tagParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *tagParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *tagParser) errorf(t token, format string, args ...interface{}) error {
+	return TagExpressionError{Expression: p.exp, Column: t.col, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *tagParser) parseOr() (tagExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) parseAnd() (tagExpression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) parseUnary() (tagExpression, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *tagParser) parsePrimary() (tagExpression, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenTag:
+		return p.newTagOperand(t)
+	case tokenOpenParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.next(); c.kind != tokenCloseParen {
+			return nil, p.errorf(c, "expected ')' to close '(' at column %d, found %s", t.col, c.kind)
+		}
+		return e, nil
+	}
+	return nil, p.errorf(t, "expected a tag or '(', found %s", t.kind)
+}
+
+var comparisonOperators = []string{">=", "<=", ">", "<"}
+
+// tagOperand matches a single tag. Key and comparison are set only for value comparisons, e.g. `priority:>=2`.
+type tagOperand struct {
+	pattern    *regexp.Regexp
+	key        string
+	comparison string
+	value      string
+}
+
+func (p *tagParser) newTagOperand(t token) (tagExpression, error) {
+	v := sanitize(t.value)
+	if i := strings.Index(v, ":"); i > 0 {
+		for _, op := range comparisonOperators {
+			if strings.HasPrefix(v[i+1:], op) {
+				value := v[i+1+len(op):]
+				if value == "" {
+					return nil, p.errorf(t, "missing value to compare after '%s' in tag '%s'", op, t.value)
+				}
+				return &tagOperand{key: v[:i], comparison: op, value: value}, nil
+			}
+		}
+	}
+	return &tagOperand{pattern: globToRegexp(v)}, nil
+}
+
+func globToRegexp(glob string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(glob)
+	quoted = strings.Replace(quoted, `\*`, ".*", -1)
+	quoted = strings.Replace(quoted, `\?`, ".", -1)
+	return regexp.MustCompile("^" + quoted + "$")
+}
+
+func (o *tagOperand) eval(tags []string) bool {
+	for _, tag := range tags {
+		if o.matches(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *tagOperand) matches(tag string) bool {
+	if o.comparison == "" {
+		return o.pattern.MatchString(tag)
+	}
+	i := strings.Index(tag, ":")
+	if i < 0 || tag[:i] != o.key {
+		return false
+	}
+	c := compareTagValues(tag[i+1:], o.value)
+	switch o.comparison {
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	}
+	return c <= 0
+}
+
+// compareTagValues compares the values numerically if both are numbers, else lexically.
+func compareTagValues(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
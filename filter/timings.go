@@ -0,0 +1,278 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package filter
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/env"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/util"
+	"google.golang.org/protobuf/proto"
+)
+
+// TimingsFile is the path of a saved suite result (e.g. .gauge/last_run_result). If set, specs are distributed
+// across streams and groups based on their previous execution time instead of their position.
+var TimingsFile string
+
+// TableRowIndexes are the indexes of the spec data table rows selected with --table-rows. All the rows are executed if it is empty.
+var TableRowIndexes []int
+
+// specTimings holds the average execution time of a row of the scenarios in a previous run, keyed by the
+// spec file path relative to project root and the scenario heading. The specs of a run in another checkout of
+// the project are keyed relative to its project directory.
+type specTimings struct {
+	scenarios    map[string]map[string]int64
+	meanScenario int64
+}
+
+func newSpecTimings(res *gauge_messages.ProtoSuiteResult) *specTimings {
+	t := &specTimings{scenarios: make(map[string]map[string]int64)}
+	type total struct{ time, count int64 }
+	totals := make(map[string]map[string]*total)
+	var sum, count int64
+	add := func(file string, scn *gauge_messages.ProtoScenario) {
+		if scn.GetExecutionStatus() == gauge_messages.ExecutionStatus_SKIPPED {
+			return
+		}
+		if _, ok := totals[file]; !ok {
+			totals[file] = make(map[string]*total)
+		}
+		if _, ok := totals[file][scn.GetScenarioHeading()]; !ok {
+			totals[file][scn.GetScenarioHeading()] = &total{}
+		}
+		tt := totals[file][scn.GetScenarioHeading()]
+		tt.time += scn.GetExecutionTime()
+		tt.count++
+		sum += scn.GetExecutionTime()
+		count++
+	}
+	specPath := result.SpecPaths(res)
+	for _, specRes := range res.GetSpecResults() {
+		file := specPath(specRes.GetProtoSpec().GetFileName())
+		for _, item := range specRes.GetProtoSpec().GetItems() {
+			switch item.GetItemType() {
+			case gauge_messages.ProtoItem_Scenario:
+				add(file, item.GetScenario())
+			case gauge_messages.ProtoItem_TableDrivenScenario:
+				add(file, item.GetTableDrivenScenario().GetScenario())
+			}
+		}
+	}
+	for file, scenarios := range totals {
+		t.scenarios[file] = make(map[string]int64)
+		for heading, tt := range scenarios {
+			t.scenarios[file][heading] = tt.time / tt.count
+		}
+	}
+	if count > 0 {
+		t.meanScenario = sum / count
+	}
+	return t
+}
+
+func loadSpecTimings(file string) (*specTimings, error) {
+	b, err := ioutil.ReadFile(util.GetPathToFile(file))
+	if err != nil {
+		return nil, err
+	}
+	res := &gauge_messages.ProtoSuiteResult{}
+	if err := proto.Unmarshal(b, res); err != nil {
+		return nil, err
+	}
+	return newSpecTimings(res), nil
+}
+
+func timingsKey(specFile string) string {
+	return filepath.ToSlash(util.RelPathToProjectRoot(specFile))
+}
+
+// estimate returns the expected execution time of a spec, i.e. the time of a row of each scenario times the rows it is
+// executed for. Scenarios without any previous timing are assumed to take as long as an average scenario of the previous run.
+func (t *specTimings) estimate(spec *gauge.Specification) int64 {
+	fallback := t.meanScenario
+	if fallback == 0 {
+		fallback = 1
+	}
+	scenarios := t.scenarios[timingsKey(spec.FileName)]
+	var d int64
+	for _, scn := range spec.Scenarios {
+		v, ok := scenarios[scn.Heading.Value]
+		if !ok {
+			v = fallback
+		}
+		d += v * rows(spec, scn)
+	}
+	return d
+}
+
+// estimates returns the expected execution time of each of the specs.
+func (t *specTimings) estimates(specs []*gauge.Specification) map[*gauge.Specification]int64 {
+	e := make(map[*gauge.Specification]int64, len(specs))
+	for _, spec := range specs {
+		e[spec] = t.estimate(spec)
+	}
+	return e
+}
+
+// matches tells if any of the specs was executed in the previous run.
+func (t *specTimings) matches(specs []*gauge.Specification) bool {
+	for _, spec := range specs {
+		if _, ok := t.scenarios[timingsKey(spec.FileName)]; ok {
+			return true
+		}
+	}
+	return len(specs) == 0
+}
+
+// rows returns the number of times a scenario is executed, once for each row of the spec data table used by it that is
+// selected with --table-rows and each row of its own data table. The specs are distributed before they are split by the
+// rows of their data tables, the split specs and scenarios have a single row.
+func rows(spec *gauge.Specification, scn *gauge.Scenario) int64 {
+	n := int64(1)
+	if scn.SpecDataTableRow.IsInitialized() {
+		if !selectedRow(scn.SpecDataTableRowIndex) {
+			return 0
+		}
+	} else if spec.DataTable.IsInitialized() {
+		headers := spec.DataTable.Table.Headers
+		if spec.UsesArgsInContextTeardown(headers...) || scn.UsesArgsInSteps(headers...) {
+			n = selectedRows(spec.DataTable.Table.GetRowCount())
+		}
+	}
+	if scn.DataTable.IsInitialized() && env.AllowScenarioDatatable() && scn.DataTable.Table.GetRowCount() > 1 {
+		n *= int64(scn.DataTable.Table.GetRowCount())
+	}
+	return n
+}
+
+// selectedRows returns the number of the rows of a spec data table with the given rows that are executed.
+func selectedRows(count int) int64 {
+	if len(TableRowIndexes) == 0 {
+		if count < 1 {
+			return 1
+		}
+		return int64(count)
+	}
+	var n int64
+	for i := 0; i < count; i++ {
+		if selectedRow(i) {
+			n++
+		}
+	}
+	return n
+}
+
+func selectedRow(i int) bool {
+	if len(TableRowIndexes) == 0 {
+		return true
+	}
+	for _, index := range TableRowIndexes {
+		if index == i {
+			return true
+		}
+	}
+	return false
+}
+
+// distributeByTime bin-packs the specs into the given number of collections, always assigning the
+// longest remaining spec to the collection with the least total time. Specs sharing a lock are assigned together.
+func distributeByTime(specs []*gauge.Specification, distributions int, t *specTimings) []*gauge.SpecCollection {
+	s := make([]*gauge.SpecCollection, distributions)
+	if distributions < 1 {
+		return s
+	}
+	totals := make([]int64, distributions)
+	estimates := t.estimates(specs)
+	for _, group := range groupsByTime(specs, estimates) {
+		min := 0
+		for i := 1; i < distributions; i++ {
+			if totals[i] < totals[min] {
+				min = i
+			}
+		}
+		if s[min] == nil {
+			s[min] = gauge.NewSpecCollection(make([]*gauge.Specification, 0), false)
+		}
+		for _, spec := range group {
+			s[min].Add(spec)
+			totals[min] += estimates[spec]
+		}
+	}
+	return s
+}
+
+// groupsByTime orders the lock groups of the specs by their total time, and the specs in each group by their time.
+func groupsByTime(specs []*gauge.Specification, estimates map[*gauge.Specification]int64) [][]*gauge.Specification {
+	groups := lockGroups(specs)
+	totals := make([]int64, len(groups))
+	for i, group := range groups {
+		groups[i] = orderByTime(group, estimates)
+		for _, spec := range group {
+			totals[i] += estimates[spec]
+		}
+	}
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		ei, ej := totals[order[i]], totals[order[j]]
+		if ei == ej {
+			return groups[order[i]][0].FileName < groups[order[j]][0].FileName
+		}
+		return ei > ej
+	})
+	sorted := make([][]*gauge.Specification, len(groups))
+	for i, g := range order {
+		sorted[i] = groups[g]
+	}
+	return sorted
+}
+
+func orderByTime(specs []*gauge.Specification, estimates map[*gauge.Specification]int64) []*gauge.Specification {
+	sorted := make([]*gauge.Specification, len(specs))
+	copy(sorted, specs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ei, ej := estimates[sorted[i]], estimates[sorted[j]]
+		if ei == ej {
+			return sorted[i].FileName < sorted[j].FileName
+		}
+		return ei > ej
+	})
+	return sorted
+}
+
+func timings(specs []*gauge.Specification) *specTimings {
+	if TimingsFile == "" {
+		return nil
+	}
+	t, err := loadSpecTimings(TimingsFile)
+	if err != nil {
+		logger.Warningf(true, "Unable to read execution times from %s, distributing specs by count. %s", TimingsFile, err.Error())
+		return nil
+	}
+	if !t.matches(specs) {
+		logger.Warningf(true, "None of the specs were executed in the run saved in %s, estimating their execution times by the number of their scenarios.", TimingsFile)
+	}
+	return t
+}
+
+// OrderByEstimatedTime orders the specs so that the ones which took the longest in the timings file come first.
+// The given specs are returned as is if there is no timings file.
+func OrderByEstimatedTime(specs []*gauge.Specification) []*gauge.Specification {
+	t := timings(specs)
+	if t == nil {
+		return specs
+	}
+	return orderByTime(specs, t.estimates(specs))
+}
@@ -0,0 +1,130 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package filter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/gauge"
+	"google.golang.org/protobuf/proto"
+	. "gopkg.in/check.v1"
+)
+
+func specWithScenarios(file string, headings ...string) *gauge.Specification {
+	spec := &gauge.Specification{FileName: file}
+	for _, h := range headings {
+		spec.Scenarios = append(spec.Scenarios, &gauge.Scenario{Heading: &gauge.Heading{Value: h}})
+	}
+	return spec
+}
+
+// tableDrivenSpec returns a spec whose scenario uses the given rows of the spec data table
+func tableDrivenSpec(file, heading string, rows ...string) *gauge.Specification {
+	var cells []gauge.TableCell
+	for _, r := range rows {
+		cells = append(cells, gauge.TableCell{Value: r, CellType: gauge.Static})
+	}
+	spec := &gauge.Specification{FileName: file, DataTable: gauge.DataTable{Table: gauge.NewTable([]string{"word"}, [][]gauge.TableCell{cells}, 1)}}
+	spec.Scenarios = append(spec.Scenarios, &gauge.Scenario{Heading: &gauge.Heading{Value: heading}, Steps: []*gauge.Step{
+		{Value: "say {}", Args: []*gauge.StepArg{{Value: "word", ArgType: gauge.Dynamic}}},
+	}})
+	return spec
+}
+
+func scenarioItem(heading string, time int64) *gauge_messages.ProtoItem {
+	return &gauge_messages.ProtoItem{ItemType: gauge_messages.ProtoItem_Scenario, Scenario: &gauge_messages.ProtoScenario{
+		ScenarioHeading: heading, ExecutionTime: time, ExecutionStatus: gauge_messages.ExecutionStatus_PASSED}}
+}
+
+func previousRun() *gauge_messages.ProtoSuiteResult {
+	return &gauge_messages.ProtoSuiteResult{SpecResults: []*gauge_messages.ProtoSpecResult{
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "slow.spec", Items: []*gauge_messages.ProtoItem{scenarioItem("s1", 900), scenarioItem("s2", 100)}}},
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "medium.spec", Items: []*gauge_messages.ProtoItem{scenarioItem("m1", 600)}}},
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "fast.spec", Items: []*gauge_messages.ProtoItem{scenarioItem("f1", 100),
+			{ItemType: gauge_messages.ProtoItem_TableDrivenScenario, TableDrivenScenario: &gauge_messages.ProtoTableDrivenScenario{Scenario: &gauge_messages.ProtoScenario{ScenarioHeading: "f2", ExecutionTime: 100}}},
+			{ItemType: gauge_messages.ProtoItem_TableDrivenScenario, TableDrivenScenario: &gauge_messages.ProtoTableDrivenScenario{Scenario: &gauge_messages.ProtoScenario{ScenarioHeading: "f2", ExecutionTime: 300}}},
+		}}},
+	}}
+}
+
+func (s *MySuite) TestEstimateSpecTimeFromPreviousRun(c *C) {
+	t := newSpecTimings(previousRun())
+
+	c.Assert(t.estimate(specWithScenarios("slow.spec", "s1", "s2")), Equals, int64(1000))
+	c.Assert(t.estimate(tableDrivenSpec("fast.spec", "f2", "gauge", "mingle")), Equals, int64(400))
+	c.Assert(t.estimate(tableDrivenSpec("fast.spec", "f2", "mingle")), Equals, int64(200))
+	c.Assert(t.estimate(specWithScenarios("slow.spec", "s1")), Equals, int64(900))
+	c.Assert(t.estimate(specWithScenarios("new.spec", "n1", "n2")), Equals, int64(700))
+}
+
+func (s *MySuite) TestEstimateSpecTimeForTheSelectedTableRows(c *C) {
+	t := newSpecTimings(previousRun())
+	TableRowIndexes = []int{0, 2}
+	defer func() { TableRowIndexes = nil }()
+	split := tableDrivenSpec("fast.spec", "f2", "mingle")
+	split.Scenarios[0].SpecDataTableRow = *split.DataTable.Table
+	split.Scenarios[0].SpecDataTableRowIndex = 1
+
+	c.Assert(t.estimate(tableDrivenSpec("fast.spec", "f2", "gauge", "mingle", "vowel")), Equals, int64(400))
+	c.Assert(t.estimate(split), Equals, int64(0))
+}
+
+func (s *MySuite) TestEstimateSpecTimeFromARunInAnotherWorkspace(c *C) {
+	root := config.ProjectRoot
+	config.ProjectRoot = filepath.Join(string(filepath.Separator)+"home", "shop")
+	defer func() { config.ProjectRoot = root }()
+	res := &gauge_messages.ProtoSuiteResult{ProjectName: "shop", SpecResults: []*gauge_messages.ProtoSpecResult{
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "/ci/workspace-1/shop/specs/slow.spec", Items: []*gauge_messages.ProtoItem{scenarioItem("s1", 900)}}},
+		{ProtoSpec: &gauge_messages.ProtoSpec{FileName: "/ci/workspace-1/shop/specs/fast.spec", Items: []*gauge_messages.ProtoItem{scenarioItem("f1", 100)}}},
+	}}
+	t := newSpecTimings(res)
+	slow := specWithScenarios(filepath.Join(config.ProjectRoot, "specs", "slow.spec"), "s1")
+	other := specWithScenarios(filepath.Join(config.ProjectRoot, "other.spec"), "o1")
+
+	c.Assert(t.estimate(slow), Equals, int64(900))
+	c.Assert(t.matches([]*gauge.Specification{slow, other}), Equals, true)
+	c.Assert(t.matches([]*gauge.Specification{other}), Equals, false)
+}
+
+func (s *MySuite) TestDistributeSpecsByTime(c *C) {
+	t := newSpecTimings(previousRun())
+	slow := specWithScenarios("slow.spec", "s1", "s2")
+	medium := specWithScenarios("medium.spec", "m1")
+	fast := specWithScenarios("fast.spec", "f1", "f2")
+	other := specWithScenarios("medium.spec", "m1")
+
+	collections := distributeByTime([]*gauge.Specification{fast, medium, slow, other}, 2, t)
+
+	c.Assert(len(collections), Equals, 2)
+	c.Assert(collections[0].Specs(), DeepEquals, []*gauge.Specification{slow, fast})
+	c.Assert(collections[1].Specs(), DeepEquals, []*gauge.Specification{medium, other})
+}
+
+func (s *MySuite) TestDistributeSpecsUsesTimingsFile(c *C) {
+	dir, err := ioutil.TempDir("", "timings")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	b, err := proto.Marshal(previousRun())
+	c.Assert(err, IsNil)
+	file := filepath.Join(dir, "last_run_result")
+	c.Assert(ioutil.WriteFile(file, b, 0644), IsNil)
+	TimingsFile = file
+	defer func() { TimingsFile = "" }()
+	slow := specWithScenarios("slow.spec", "s1", "s2")
+	medium := specWithScenarios("medium.spec", "m1")
+	fast := specWithScenarios("fast.spec", "f1")
+
+	collections := DistributeSpecs([]*gauge.Specification{fast, medium, slow}, 2)
+
+	c.Assert(collections[0].Specs(), DeepEquals, []*gauge.Specification{slow})
+	c.Assert(collections[1].Specs(), DeepEquals, []*gauge.Specification{medium, fast})
+	c.Assert(OrderByEstimatedTime([]*gauge.Specification{fast, medium, slow}), DeepEquals, []*gauge.Specification{slow, medium, fast})
+}
@@ -66,6 +66,13 @@ func (s *SpecCollection) Next() []*Specification {
 	return spec
 }
 
+// Remaining returns the number of spec groups yet to be returned by Next.
+func (s *SpecCollection) Remaining() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.specs) - s.index
+}
+
 func (s *SpecCollection) Size() int {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -19,8 +19,16 @@ import (
 )
 
 type Manifest struct {
-	Language string
-	Plugins  []string
+	Language              string
+	Plugins               []string
+	SpecialParamResolvers map[string]SpecialParamResolver `json:",omitempty"`
+}
+
+// SpecialParamResolver is a command which resolves a special param type that gauge does not know of.
+// Output is either `string` (default) or `table`, in which case the command should print CSV.
+type SpecialParamResolver struct {
+	Command []string
+	Output  string `json:",omitempty"`
 }
 
 func ProjectManifest() (*Manifest, error) {
@@ -0,0 +1,137 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package order
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getgauge/gauge/execution/rerun"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/util"
+)
+
+// Order is the order in which the specs are executed, can be 'random[:seed]' or 'failed-first'.
+// The specs are executed in the order they are found if not set.
+var Order string
+
+// ShuffleScenarios if true shuffles the scenarios within each spec as well, when executing in random order
+var ShuffleScenarios bool
+
+// Random shuffles the specs. The same seed gives the same order for the same set of specs.
+const Random = "random"
+
+// FailedFirst executes the specs and scenarios which failed in the previous run first.
+const FailedFirst = "failed-first"
+
+func parse(order string) (mode string, seed int64, hasSeed bool, err error) {
+	mode = strings.ToLower(order)
+	if i := strings.Index(mode, ":"); i != -1 {
+		mode, hasSeed = mode[:i], true
+		if seed, err = strconv.ParseInt(order[i+1:], 10, 64); err != nil {
+			return "", 0, false, fmt.Errorf("invalid seed %s in --order %s, the seed should be a number", order[i+1:], order)
+		}
+		if mode != Random {
+			return "", 0, false, fmt.Errorf("a seed can be given only with --order random")
+		}
+	}
+	if mode != "" && mode != Random && mode != FailedFirst {
+		return "", 0, false, fmt.Errorf("invalid order %s, should be random[:seed] or failed-first", order)
+	}
+	return mode, seed, hasSeed, nil
+}
+
+// Init validates the order and picks a seed for the random order if none is given. The seed is printed and kept in Order,
+// so that the order can be reproduced.
+func Init() error {
+	mode, seed, hasSeed, err := parse(Order)
+	if err != nil {
+		return err
+	}
+	if ShuffleScenarios && mode != Random {
+		return fmt.Errorf("--shuffle-scenarios can be used only with --order random")
+	}
+	if mode != Random {
+		return nil
+	}
+	if !hasSeed {
+		seed = time.Now().UnixNano()
+	}
+	Order = fmt.Sprintf("%s:%d", Random, seed)
+	logger.Infof(true, "Executing the specs in random order, seed: %d. Use --order %s to reproduce this order.", seed, Order)
+	return nil
+}
+
+// shuffle orders the specs by file name before shuffling them, as the specs are not always found in the same order.
+func shuffle(specs []*gauge.Specification, seed int64) {
+	sort.Sort(byFileName(specs))
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(specs), func(i, j int) { specs[i], specs[j] = specs[j], specs[i] })
+	if !ShuffleScenarios {
+		return
+	}
+	for _, spec := range specs {
+		r.Shuffle(len(spec.Scenarios), func(i, j int) { spec.Scenarios[i], spec.Scenarios[j] = spec.Scenarios[j], spec.Scenarios[i] })
+		reorderScenarioItems(spec)
+	}
+}
+
+// reorderScenarioItems places the scenarios in the items of the spec in the order of spec.Scenarios
+func reorderScenarioItems(spec *gauge.Specification) {
+	index := 0
+	for i, item := range spec.Items {
+		if item.Kind() == gauge.ScenarioKind && index < len(spec.Scenarios) {
+			spec.Items[i] = spec.Scenarios[index]
+			index++
+		}
+	}
+}
+
+// failedFirst moves the specs which failed in the previous run to the front, and the failed scenarios to the front of their spec.
+// The specs and scenarios are otherwise left in the same order.
+func failedFirst(specs []*gauge.Specification) {
+	failedItems := rerun.LastFailedItems()
+	if len(failedItems) == 0 {
+		return
+	}
+	failed := make(map[string]bool)
+	for _, item := range failedItems {
+		failed[filepath.ToSlash(item)] = true
+	}
+	failedScenario := func(spec *gauge.Specification, scn *gauge.Scenario) bool {
+		return failed[fmt.Sprintf("%s:%d", specPath(spec), scn.Span.Start)]
+	}
+	failedSpec := make(map[*gauge.Specification]bool)
+	for _, spec := range specs {
+		failedSpec[spec] = failed[specPath(spec)]
+		for _, scn := range spec.Scenarios {
+			if failedScenario(spec, scn) {
+				failedSpec[spec] = true
+			}
+		}
+		if !failedSpec[spec] {
+			continue
+		}
+		sort.SliceStable(spec.Scenarios, func(i, j int) bool {
+			return failedScenario(spec, spec.Scenarios[i]) && !failedScenario(spec, spec.Scenarios[j])
+		})
+		reorderScenarioItems(spec)
+	}
+	sort.SliceStable(specs, func(i, j int) bool {
+		return failedSpec[specs[i]] && !failedSpec[specs[j]]
+	})
+}
+
+func specPath(spec *gauge.Specification) string {
+	return filepath.ToSlash(util.RelPathToProjectRoot(spec.FileName))
+}
@@ -0,0 +1,140 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package order
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/gauge"
+)
+
+func specsNamed(names ...string) []*gauge.Specification {
+	var specs []*gauge.Specification
+	for _, n := range names {
+		specs = append(specs, &gauge.Specification{FileName: n})
+	}
+	return specs
+}
+
+func fileNames(specs []*gauge.Specification) string {
+	var names []string
+	for _, s := range specs {
+		names = append(names, filepath.Base(s.FileName))
+	}
+	return strings.Join(names, ",")
+}
+
+func withOrder(order string, shuffleScenarios bool) func() {
+	Order, ShuffleScenarios = order, shuffleScenarios
+	return func() { Order, ShuffleScenarios = "", false }
+}
+
+func TestInitValidatesOrder(t *testing.T) {
+	for _, o := range []string{"alphabetical", "random:abc", "failed-first:12"} {
+		Order = o
+		if err := Init(); err == nil {
+			t.Errorf("Expected order %s to be invalid", o)
+		}
+	}
+	defer withOrder("failed-first", true)()
+	if err := Init(); err == nil {
+		t.Error("Expected --shuffle-scenarios to be invalid without random order")
+	}
+}
+
+func TestInitPicksSeedForRandomOrder(t *testing.T) {
+	defer withOrder("random", false)()
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(Order, "random:") || len(Order) == len("random:") {
+		t.Errorf("Expected a seed to be picked, got %s", Order)
+	}
+
+	Order = "random:42"
+	if err := Init(); err != nil || Order != "random:42" {
+		t.Errorf("Expected the given seed to be kept, got %s, %v", Order, err)
+	}
+}
+
+func TestRandomOrderIsReproducible(t *testing.T) {
+	defer withOrder("random:7", false)()
+	first := fileNames(Sort(specsNamed("a", "b", "c", "d", "e", "f")))
+	second := fileNames(Sort(specsNamed("f", "e", "d", "c", "b", "a")))
+
+	if first != second {
+		t.Errorf("Expected the same order for the same seed, got %s and %s", first, second)
+	}
+	if first == "a,b,c,d,e,f" {
+		t.Errorf("Expected the specs to be shuffled, got %s", first)
+	}
+}
+
+func TestShuffledScenariosAreReorderedInItems(t *testing.T) {
+	defer withOrder("random:3", true)()
+	spec := &gauge.Specification{FileName: "a"}
+	comment := &gauge.Comment{Value: "a comment"}
+	spec.Items = append(spec.Items, comment)
+	for _, h := range []string{"1", "2", "3", "4", "5"} {
+		scn := &gauge.Scenario{Heading: &gauge.Heading{Value: h}}
+		spec.Scenarios = append(spec.Scenarios, scn)
+		spec.Items = append(spec.Items, scn)
+	}
+
+	Sort([]*gauge.Specification{spec})
+
+	if spec.Items[0] != comment {
+		t.Error("Expected the items other than scenarios to be left as is")
+	}
+	var headings []string
+	for i, scn := range spec.Scenarios {
+		headings = append(headings, scn.Heading.Value)
+		if spec.Items[i+1] != scn {
+			t.Errorf("Expected scenario %s at item %d", scn.Heading.Value, i+1)
+		}
+	}
+	if strings.Join(headings, "") == "12345" {
+		t.Error("Expected the scenarios to be shuffled")
+	}
+}
+
+func TestFailedFirstOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	oldRoot := config.ProjectRoot
+	config.ProjectRoot = dir
+	defer func() { config.ProjectRoot = oldRoot }()
+	if err := os.MkdirAll(filepath.Join(dir, ".gauge"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	failures := `{"Args": ["run"], "FailedItems": ["specs/c.spec:12", "specs/d.spec"]}`
+	if err := ioutil.WriteFile(filepath.Join(dir, ".gauge", "failures.json"), []byte(failures), 0640); err != nil {
+		t.Fatal(err)
+	}
+	defer withOrder("failed-first", false)()
+	specs := specsNamed(filepath.Join(dir, "specs", "a.spec"), filepath.Join(dir, "specs", "b.spec"), filepath.Join(dir, "specs", "c.spec"), filepath.Join(dir, "specs", "d.spec"))
+	c := specs[2]
+	passed, failed := &gauge.Scenario{Span: &gauge.Span{Start: 3}}, &gauge.Scenario{Span: &gauge.Span{Start: 12}}
+	c.Scenarios, c.Items = []*gauge.Scenario{passed, failed}, []gauge.Item{passed, failed}
+
+	got := fileNames(Sort(specs))
+
+	if got != "c.spec,d.spec,a.spec,b.spec" {
+		t.Errorf("Expected the failed specs first, got %s", got)
+	}
+	if c.Scenarios[0] != failed || c.Items[0] != failed {
+		t.Error("Expected the failed scenario to be executed first")
+	}
+}
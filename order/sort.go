@@ -32,5 +32,15 @@ func Sort(specs []*gauge.Specification) []*gauge.Specification {
 	if Sorted {
 		sort.Sort(byFileName(specs))
 	}
+	mode, seed, _, err := parse(Order)
+	if err != nil {
+		return specs
+	}
+	switch mode {
+	case Random:
+		shuffle(specs, seed)
+	case FailedFirst:
+		failedFirst(specs)
+	}
 	return specs
 }
@@ -330,7 +330,7 @@ func (s *MySuite) TestErrorParsingConceptHeadingWithStaticOrSpecialParameter(c *
 
 	_, parseRes = parser.Parse("# my concept with <table: foo> \n * first step \n * second step ", "foo2.spec")
 	c.Assert(len(parseRes.ParseErrors), Not(Equals), 0)
-	c.Assert(parseRes.ParseErrors[0].Error(), Equals, "foo2.spec:1 Dynamic parameter <table: foo> could not be resolved => 'my concept with <table: foo>'")
+	c.Assert(parseRes.ParseErrors[0].Error(), Equals, "foo2.spec:1 Dynamic parameter <table: foo> could not be resolved. File foo doesn't exist. => 'my concept with <table: foo>'")
 }
 
 func (s *MySuite) TestErrorParsingConceptWithoutHeading(c *C) {
@@ -666,7 +666,7 @@ func (s *MySuite) TestConceptHavingInvalidSpecialParameters(c *C) {
 		specHeading("create user <user:id> <table:name> and <file>").
 		step("a step <user:id>").String()
 	_, parseRes := new(ConceptParser).Parse(conceptText, "")
-	c.Assert(parseRes.ParseErrors[0].Message, Equals, "Dynamic parameter <table:name> could not be resolved")
+	c.Assert(parseRes.ParseErrors[0].Message, Equals, "Dynamic parameter <table:name> could not be resolved. File name doesn't exist.")
 }
 
 func (s *MySuite) TestConceptHavingStaticParameters(c *C) {
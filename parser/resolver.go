@@ -13,11 +13,15 @@ import (
 
 	"github.com/getgauge/gauge-proto/go/gauge_messages"
 	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
 	"github.com/getgauge/gauge/util"
 )
 
+// invalidSpecialParamError is returned when a special param cannot be resolved. A special param whose type has no
+// resolver, i.e. resolverFound is false, is treated as a dynamic param.
 type invalidSpecialParamError struct {
-	message string
+	message       string
+	resolverFound bool
 }
 
 type resolverFn func(string) (*gauge.StepArg, error)
@@ -141,7 +145,7 @@ func newSpecialTypeResolver() *specialTypeResolver {
 }
 
 func initializePredefinedResolvers() map[string]resolverFn {
-	return map[string]resolverFn{
+	resolvers := map[string]resolverFn{
 		"file": func(filePath string) (*gauge.StepArg, error) {
 			fileContent, err := util.GetFileContents(filePath)
 			if err != nil {
@@ -160,7 +164,18 @@ func initializePredefinedResolvers() map[string]resolverFn {
 			}
 			return &gauge.StepArg{Table: *csvTable, ArgType: gauge.SpecialTable}, nil
 		},
+		"tsv":  resolveTsv,
+		"json": resolveJSON,
+		"env":  resolveEnv,
 	}
+	for name, resolve := range projectResolvers() {
+		if _, found := resolvers[name]; found {
+			logger.Warningf(true, "Ignoring special param resolver '%s' in manifest as it is predefined.", name)
+			continue
+		}
+		resolvers[name] = resolve
+	}
+	return resolvers
 }
 
 func (resolver *specialTypeResolver) resolve(arg string) (*gauge.StepArg, error) {
@@ -180,10 +195,14 @@ func (resolver *specialTypeResolver) resolve(arg string) (*gauge.StepArg, error)
 
 func (resolver *specialTypeResolver) getStepArg(specialType string, value string, arg string) (*gauge.StepArg, error) {
 	resolveFunc, found := resolver.predefinedResolvers[specialType]
-	if found {
-		return resolveFunc(value)
+	if !found {
+		return nil, invalidSpecialParamError{message: fmt.Sprintf("Resolver not found for special param <%s>", arg)}
+	}
+	stepArg, err := resolveFunc(value)
+	if err != nil {
+		return nil, invalidSpecialParamError{message: fmt.Sprintf("Dynamic parameter <%s> could not be resolved. %s", arg, err.Error()), resolverFound: true}
 	}
-	return nil, invalidSpecialParamError{message: fmt.Sprintf("Resolver not found for special param <%s>", arg)}
+	return stepArg, nil
 }
 
 // PopulateConceptDynamicParams creates a copy of the lookup and populates table values
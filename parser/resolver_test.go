@@ -7,9 +7,16 @@
 package parser
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/getgauge/common"
+	"github.com/getgauge/gauge/config"
 	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/manifest"
 	"github.com/getgauge/gauge/util"
 	. "gopkg.in/check.v1"
 )
@@ -277,3 +284,161 @@ func (s *MySuite) TestGetResolveParameterFromDataTable(c *C) {
 	c.Assert(spec.DataTable.Table.Columns[1][0].Value, Equals, "123")
 	c.Assert(spec.DataTable.Table.Columns[1][1].Value, Equals, "007")
 }
+
+func writeFixture(c *C, name, contents string) string {
+	path := filepath.Join(c.MkDir(), name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		c.Fatal(err)
+	}
+	return path
+}
+
+func (s *MySuite) TestParsingJSONArrayOfObjectsAsSpecialTable(c *C) {
+	path := writeFixture(c, "users.json", `[{"name": "foo", "age": 21, "address": {"city": "Pune"}}, {"name": "bar", "admin": true, "address": null}]`)
+
+	stepArg, err := newSpecialTypeResolver().resolve("json:" + path)
+
+	c.Assert(err, IsNil)
+	c.Assert(stepArg.ArgType, Equals, gauge.SpecialTable)
+	c.Assert(stepArg.Table.Headers, DeepEquals, []string{"name", "age", "address.city", "admin", "address"})
+	c.Assert(stepArg.Table.Rows(), DeepEquals, [][]string{{"foo", "21", "Pune", "", ""}, {"bar", "", "", "true", ""}})
+}
+
+func (s *MySuite) TestParsingJSONObjectAsSpecialString(c *C) {
+	path := writeFixture(c, "user.json", `{"name": "foo"}`)
+
+	stepArg, err := newSpecialTypeResolver().resolve("json:" + path)
+
+	c.Assert(err, IsNil)
+	c.Assert(stepArg.ArgType, Equals, gauge.SpecialString)
+	c.Assert(stepArg.Value, Equals, `{"name": "foo"}`)
+}
+
+func (s *MySuite) TestParsingInvalidJSONSpecialType(c *C) {
+	path := writeFixture(c, "broken.json", `[{"name": }]`)
+
+	_, err := newSpecialTypeResolver().resolve("json:" + path)
+
+	e, ok := err.(invalidSpecialParamError)
+	c.Assert(ok, Equals, true)
+	c.Assert(e.resolverFound, Equals, true)
+	c.Assert(e.Error(), Equals, "Dynamic parameter <json:"+path+"> could not be resolved. Invalid JSON.")
+}
+
+func (s *MySuite) TestParsingTsvSpecialType(c *C) {
+	path := writeFixture(c, "users.tsv", "id\tname\n1\tfoo, bar\n")
+
+	stepArg, err := newSpecialTypeResolver().resolve("tsv:" + path)
+
+	c.Assert(err, IsNil)
+	c.Assert(stepArg.ArgType, Equals, gauge.SpecialTable)
+	c.Assert(stepArg.Table.Headers, DeepEquals, []string{"id", "name"})
+	c.Assert(stepArg.Table.Rows(), DeepEquals, [][]string{{"1", "foo, bar"}})
+}
+
+func (s *MySuite) TestParsingEnvSpecialType(c *C) {
+	os.Setenv("GAUGE_RESOLVER_TEST", "value")
+	defer os.Unsetenv("GAUGE_RESOLVER_TEST")
+
+	stepArg, err := newSpecialTypeResolver().resolve("env:GAUGE_RESOLVER_TEST")
+
+	c.Assert(err, IsNil)
+	c.Assert(stepArg.ArgType, Equals, gauge.SpecialString)
+	c.Assert(stepArg.Value, Equals, "value")
+}
+
+func (s *MySuite) TestParsingEnvSpecialTypeForUnsetVariable(c *C) {
+	_, err := newSpecialTypeResolver().resolve("env:GAUGE_RESOLVER_TEST_UNSET")
+
+	c.Assert(err.Error(), Equals, "Dynamic parameter <env:GAUGE_RESOLVER_TEST_UNSET> could not be resolved. Environment variable GAUGE_RESOLVER_TEST_UNSET is not set.")
+}
+
+func (s *MySuite) TestCommandResolverReturningTable(c *C) {
+	if util.IsWindows() {
+		c.Skip("uses echo from a posix shell")
+	}
+	resolve := commandResolver("csv-table", manifest.SpecialParamResolver{Command: []string{"sh", "-c", `echo "id,value"; echo "1,$0"`}, Output: "table"})
+
+	stepArg, err := resolve("foo")
+
+	c.Assert(err, IsNil)
+	c.Assert(stepArg.ArgType, Equals, gauge.SpecialTable)
+	c.Assert(stepArg.Table.Rows(), DeepEquals, [][]string{{"1", "foo"}})
+}
+
+func (s *MySuite) TestCommandResolverRunsTheCommandOnceForAValue(c *C) {
+	if util.IsWindows() {
+		c.Skip("uses a posix shell")
+	}
+	dir, err := ioutil.TempDir("", "resolver")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	runs := filepath.Join(dir, "runs")
+	resolve := commandResolver("counting", manifest.SpecialParamResolver{Command: []string{"sh", "-c", `echo run >> "$0"; echo resolved`}})
+
+	for i := 0; i < 3; i++ {
+		stepArg, err := resolve(runs)
+		c.Assert(err, IsNil)
+		c.Assert(stepArg.Value, Equals, "resolved")
+	}
+
+	b, err := ioutil.ReadFile(runs)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "run\n")
+}
+
+func (s *MySuite) TestCommandResolverFailsIfTheCommandDoesNotCompleteInTime(c *C) {
+	if util.IsWindows() {
+		c.Skip("uses sleep")
+	}
+	timeout := resolverTimeout
+	resolverTimeout = 100 * time.Millisecond
+	defer func() { resolverTimeout = timeout }()
+	resolver := newSpecialTypeResolver()
+	resolver.predefinedResolvers["slow"] = commandResolver("slow", manifest.SpecialParamResolver{Command: []string{"sleep"}})
+
+	start := time.Now()
+	_, err := resolver.resolve("slow:10")
+
+	c.Assert(time.Since(start) < 5*time.Second, Equals, true)
+	c.Assert(err, NotNil)
+	c.Assert(err.(invalidSpecialParamError).resolverFound, Equals, true)
+	c.Assert(err.Error(), Equals, "Dynamic parameter <slow:10> could not be resolved. sleep did not complete in 100ms.")
+}
+
+func (s *MySuite) TestProjectResolversAreReadAgainWhenTheManifestChanges(c *C) {
+	dir, err := ioutil.TempDir("", "resolver")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	root := config.ProjectRoot
+	config.ProjectRoot = dir
+	defer func() { config.ProjectRoot = root }()
+	writeManifest := func(resolver string) {
+		m := fmt.Sprintf(`{"Language": "java", "SpecialParamResolvers": {"%s": {"Command": ["echo"]}}}`, resolver)
+		c.Assert(ioutil.WriteFile(filepath.Join(dir, common.ManifestFile), []byte(m), 0644), IsNil)
+	}
+
+	writeManifest("yaml")
+	_, found := projectResolvers()["yaml"]
+	c.Assert(found, Equals, true)
+
+	writeManifest("toml-file")
+	resolvers := projectResolvers()
+	_, found = resolvers["toml-file"]
+	c.Assert(found, Equals, true)
+	_, found = resolvers["yaml"]
+	c.Assert(found, Equals, false)
+}
+
+func (s *MySuite) TestErrorWhenSpecialParamCannotBeResolvedHasFileAndLine(c *C) {
+	tokens := []*Token{
+		&Token{Kind: gauge.SpecKind, Value: "Spec Heading", LineNo: 1},
+		&Token{Kind: gauge.ScenarioKind, Value: "Scenario Heading", LineNo: 2},
+		&Token{Kind: gauge.StepKind, Value: "my step with {special}", LineNo: 3, Args: []string{"env:GAUGE_RESOLVER_TEST_UNSET"}, Lines: []string{"* my step with <env:GAUGE_RESOLVER_TEST_UNSET>"}},
+	}
+
+	_, res, _ := new(SpecParser).CreateSpecification(tokens, gauge.NewConceptDictionary(), "foo.spec")
+
+	c.Assert(len(res.ParseErrors), Equals, 1)
+	c.Assert(res.ParseErrors[0].Error(), Equals, "foo.spec:3 Dynamic parameter <env:GAUGE_RESOLVER_TEST_UNSET> could not be resolved. Environment variable GAUGE_RESOLVER_TEST_UNSET is not set. => '* my step with <env:GAUGE_RESOLVER_TEST_UNSET>'")
+}
@@ -0,0 +1,286 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getgauge/common"
+	"github.com/getgauge/gauge/config"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/logger"
+	"github.com/getgauge/gauge/manifest"
+	"github.com/getgauge/gauge/util"
+)
+
+const tableOutput = "table"
+
+// resolvedParamTTL is how long the output of a resolver command is reused.
+const resolvedParamTTL = 30 * time.Second
+
+// resolverTimeout is how long a resolver command is waited for, a command which hangs fails the step using it.
+var resolverTimeout = 30 * time.Second
+
+// customResolvers holds the resolvers of the project manifest, which are read again once the manifest is changed,
+// e.g. while the language server or watch mode is running.
+var customResolvers = struct {
+	sync.Mutex
+	manifest  string
+	modTime   time.Time
+	size      int64
+	resolvers map[string]resolverFn
+}{}
+
+// resolvedParams caches the output of the resolver commands per resolver and value, as the specs are parsed by many
+// commands, and on every change by the language server. A command is run once for a value used by many steps or rows,
+// and the changes to its inputs are picked up once the output expires.
+var resolvedParams = struct {
+	sync.Mutex
+	params map[resolvedParamKey]*resolvedParam
+}{params: make(map[resolvedParamKey]*resolvedParam)}
+
+type resolvedParamKey struct {
+	resolver string
+	command  string
+	value    string
+}
+
+type resolvedParam struct {
+	once    sync.Once
+	expires time.Time
+	arg     *gauge.StepArg
+	err     error
+}
+
+func cachedParam(resolver string, r manifest.SpecialParamResolver, value string) *resolvedParam {
+	resolvedParams.Lock()
+	defer resolvedParams.Unlock()
+	key := resolvedParamKey{resolver: resolver, command: fmt.Sprintf("%q %s", r.Command, r.Output), value: value}
+	p, ok := resolvedParams.params[key]
+	if !ok || time.Now().After(p.expires) {
+		p = &resolvedParam{expires: time.Now().Add(resolvedParamTTL)}
+		resolvedParams.params[key] = p
+	}
+	return p
+}
+
+// projectResolvers returns the resolvers registered in the project manifest, e.g.
+//
+//	"SpecialParamResolvers": {
+//	  "yaml": {"Command": ["python", "yaml_to_csv.py"], "Output": "table"}
+//	}
+//
+// The command is run from the project root with the value of the special param, i.e. the part after `yaml:`, as its last argument.
+// Its output is passed to the step as a special string, or as a special table if the output is `table` and the command prints CSV.
+// The command fails if it does not complete in 30 seconds.
+func projectResolvers() map[string]resolverFn {
+	file := filepath.Join(config.ProjectRoot, common.ManifestFile)
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(file); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+	customResolvers.Lock()
+	defer customResolvers.Unlock()
+	if customResolvers.resolvers != nil && customResolvers.manifest == file && customResolvers.modTime.Equal(modTime) && customResolvers.size == size {
+		return customResolvers.resolvers
+	}
+	customResolvers.manifest, customResolvers.modTime, customResolvers.size = file, modTime, size
+	customResolvers.resolvers = make(map[string]resolverFn)
+	m, err := manifest.ProjectManifest()
+	if err != nil {
+		return customResolvers.resolvers
+	}
+	for name, r := range m.SpecialParamResolvers {
+		if len(r.Command) == 0 {
+			logger.Warningf(true, "Ignoring special param resolver '%s' in manifest as it has no command.", name)
+			continue
+		}
+		customResolvers.resolvers[name] = commandResolver(name, r)
+	}
+	return customResolvers.resolvers
+}
+
+func commandResolver(name string, r manifest.SpecialParamResolver) resolverFn {
+	return func(value string) (*gauge.StepArg, error) {
+		p := cachedParam(name, r, value)
+		p.once.Do(func() {
+			p.arg, p.err = runResolverCommand(r, value)
+		})
+		if p.err != nil {
+			return nil, p.err
+		}
+		arg := *p.arg
+		return &arg, nil
+	}
+}
+
+func runResolverCommand(r manifest.SpecialParamResolver, value string) (*gauge.StepArg, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolverTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, r.Command[0], append(r.Command[1:], value)...)
+	cmd.Dir = config.ProjectRoot
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%s failed. %s", strings.Join(r.Command, " "), err.Error())
+	}
+	// the output of a killed command is not waited for, as the processes started by it can keep it open
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s did not complete in %s.", strings.Join(r.Command, " "), resolverTimeout)
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("%s failed. %s %s", strings.Join(r.Command, " "), err.Error(), strings.TrimSpace(stderr.String()))
+		}
+	}
+	out := strings.TrimRight(stdout.String(), "\r\n")
+	if r.Output != tableOutput {
+		return &gauge.StepArg{Value: out, ArgType: gauge.SpecialString}, nil
+	}
+	table, err := convertCsvToTable(out)
+	if err != nil {
+		return nil, err
+	}
+	return &gauge.StepArg{Table: *table, ArgType: gauge.SpecialTable}, nil
+}
+
+func resolveEnv(name string) (*gauge.StepArg, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("Environment variable %s is not set.", name)
+	}
+	return &gauge.StepArg{Value: value, ArgType: gauge.SpecialString}, nil
+}
+
+func resolveTsv(filePath string) (*gauge.StepArg, error) {
+	tsv, err := util.GetFileContents(filePath)
+	if err != nil {
+		return nil, err
+	}
+	table, err := convertDelimitedToTable(tsv, '\t')
+	if err != nil {
+		return nil, err
+	}
+	return &gauge.StepArg{Table: *table, ArgType: gauge.SpecialTable}, nil
+}
+
+// resolveJSON passes an array of objects as a table, with nested objects flattened into columns like `address.city`.
+// Any other JSON is passed as a special string.
+func resolveJSON(filePath string) (*gauge.StepArg, error) {
+	contents, err := util.GetFileContents(filePath)
+	if err != nil {
+		return nil, err
+	}
+	table, err := convertJSONToTable(contents)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return &gauge.StepArg{Value: contents, ArgType: gauge.SpecialString}, nil
+	}
+	return &gauge.StepArg{Table: *table, ArgType: gauge.SpecialTable}, nil
+}
+
+// convertJSONToTable returns nil if the JSON is not a non empty array of objects.
+func convertJSONToTable(contents string) (*gauge.Table, error) {
+	var rows []json.RawMessage
+	if !json.Valid([]byte(contents)) {
+		return nil, fmt.Errorf("Invalid JSON.")
+	}
+	if err := json.Unmarshal([]byte(contents), &rows); err != nil || len(rows) == 0 {
+		return nil, nil
+	}
+	for _, row := range rows {
+		if !isJSONObject(row) {
+			return nil, nil
+		}
+	}
+	var headers []string
+	seen := make(map[string]bool)
+	values := make([]map[string]string, 0, len(rows))
+	for _, row := range rows {
+		v := make(map[string]string)
+		if err := flattenJSONObject(row, "", v, func(key string) {
+			if !seen[key] {
+				seen[key] = true
+				headers = append(headers, key)
+			}
+		}); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	table := new(gauge.Table)
+	table.AddHeaders(headers)
+	for _, v := range values {
+		row := make([]string, 0, len(headers))
+		for _, h := range headers {
+			row = append(row, v[h])
+		}
+		table.AddRowValues(table.CreateTableCells(row))
+	}
+	return table, nil
+}
+
+// flattenJSONObject reads the object keys in the order they are written, which a map would lose.
+func flattenJSONObject(object json.RawMessage, prefix string, values map[string]string, addHeader func(string)) error {
+	dec := json.NewDecoder(bytes.NewReader(object))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + t.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		if isJSONObject(value) {
+			if err := flattenJSONObject(value, key+".", values, addHeader); err != nil {
+				return err
+			}
+			continue
+		}
+		addHeader(key)
+		values[key] = jsonCellValue(value)
+	}
+	return nil
+}
+
+func isJSONObject(value json.RawMessage) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(value), []byte("{"))
+}
+
+func jsonCellValue(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	if string(bytes.TrimSpace(value)) == "null" {
+		return ""
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, value); err != nil {
+		return string(value)
+	}
+	return compact.String()
+}
@@ -224,8 +224,11 @@ func createStepArg(argValue string, typeOfArg string, token *Token, lookup *gaug
 	case "special":
 		resolvedArgValue, err := newSpecialTypeResolver().resolve(argValue)
 		if err != nil {
-			switch err.(type) {
+			switch e := err.(type) {
 			case invalidSpecialParamError:
+				if e.resolverFound {
+					return &gauge.StepArg{ArgType: gauge.Dynamic, Value: argValue, Name: argValue}, &ParseResult{ParseErrors: []ParseError{ParseError{FileName: fileName, LineNo: token.LineNo, SpanEnd: token.SpanEnd, Message: e.Error(), LineText: token.LineText()}}}
+				}
 				return treatArgAsDynamic(argValue, token, lookup, fileName)
 			default:
 				return &gauge.StepArg{ArgType: gauge.Dynamic, Value: argValue, Name: argValue}, &ParseResult{ParseErrors: []ParseError{ParseError{FileName: fileName, LineNo: token.LineNo, SpanEnd: token.SpanEnd, Message: fmt.Sprintf("Dynamic parameter <%s> could not be resolved", argValue), LineText: token.LineText()}}}
@@ -16,11 +16,17 @@ import (
 )
 
 func convertCsvToTable(csvContents string) (*gauge.Table, error) {
-	r := csv.NewReader(strings.NewReader(csvContents))
+	delimiter := ','
 	var de = os.Getenv(env.CsvDelimiter)
 	if de != "" {
-		r.Comma = []rune(os.Getenv(env.CsvDelimiter))[0]
+		delimiter = []rune(os.Getenv(env.CsvDelimiter))[0]
 	}
+	return convertDelimitedToTable(csvContents, delimiter)
+}
+
+func convertDelimitedToTable(contents string, delimiter rune) (*gauge.Table, error) {
+	r := csv.NewReader(strings.NewReader(contents))
+	r.Comma = delimiter
 	r.Comment = '#'
 	lines, err := r.ReadAll()
 	if err != nil {
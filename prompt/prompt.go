@@ -0,0 +1,180 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+// Package prompt reads the lines typed in a terminal, with history and tab completion. The lines are read as is
+// if the input is not a terminal which can be put in raw mode.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine if Ctrl+C is pressed.
+var ErrInterrupted = errors.New("interrupted")
+
+// Completer returns the candidates to complete the line typed so far.
+type Completer func(line string) []string
+
+const (
+	ctrlC     = 3
+	ctrlD     = 4
+	ctrlH     = 8
+	tab       = 9
+	ctrlU     = 21
+	esc       = 27
+	backspace = 127
+)
+
+// Prompt reads lines from the input, echoing them to the output if the input is a terminal.
+type Prompt struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       int
+	terminal bool
+	complete Completer
+	history  []string
+}
+
+// New creates a prompt reading from the given input. Completion and history are available only if it is a terminal.
+func New(in io.Reader, out io.Writer, complete Completer) *Prompt {
+	p := &Prompt{in: bufio.NewReader(in), out: out, fd: -1, complete: complete}
+	if f, ok := in.(*os.File); ok {
+		p.fd = int(f.Fd())
+		p.terminal = isTerminal(p.fd)
+	}
+	return p
+}
+
+// ReadLine prints the prompt and returns the line typed, without the line ending. It returns io.EOF once the input
+// ends or Ctrl+D is pressed on an empty line.
+func (p *Prompt) ReadLine(prompt string) (string, error) {
+	if p.terminal {
+		if restore, err := makeRaw(p.fd); err == nil {
+			defer restore()
+			return p.edit(prompt)
+		}
+	}
+	fmt.Fprint(p.out, prompt)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// edit reads a line from a terminal in raw mode, where the keys pressed are read as they are typed.
+func (p *Prompt) edit(prompt string) (string, error) {
+	var line []rune
+	next := len(p.history)
+	redraw := func() {
+		fmt.Fprintf(p.out, "\r\x1b[K%s%s", prompt, string(line))
+	}
+	fmt.Fprint(p.out, prompt)
+	for {
+		r, _, err := p.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(p.out, "\r\n")
+			p.remember(string(line))
+			return string(line), nil
+		case ctrlC:
+			fmt.Fprint(p.out, "^C\r\n")
+			return "", ErrInterrupted
+		case ctrlD:
+			if len(line) == 0 {
+				fmt.Fprint(p.out, "\r\n")
+				return "", io.EOF
+			}
+		case ctrlU:
+			line = line[:0]
+			redraw()
+		case backspace, ctrlH:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+		case tab:
+			line = p.completeLine(line)
+			redraw()
+		case esc:
+			// only the up and down arrows, which go through the history, are handled
+			if b, err := p.in.ReadByte(); err != nil || b != '[' {
+				continue
+			}
+			key, err := p.in.ReadByte()
+			if err != nil {
+				continue
+			}
+			switch {
+			case key == 'A' && next > 0:
+				next--
+				line = []rune(p.history[next])
+			case key == 'B' && next < len(p.history)-1:
+				next++
+				line = []rune(p.history[next])
+			case key == 'B':
+				next = len(p.history)
+				line = line[:0]
+			}
+			redraw()
+		default:
+			if unicode.IsPrint(r) {
+				line = append(line, r)
+				fmt.Fprint(p.out, string(r))
+			}
+		}
+	}
+}
+
+func (p *Prompt) remember(line string) {
+	if strings.TrimSpace(line) == "" || (len(p.history) > 0 && p.history[len(p.history)-1] == line) {
+		return
+	}
+	p.history = append(p.history, line)
+}
+
+// completeLine replaces the line with the only candidate, or with the prefix common to all the candidates.
+// The candidates are listed if the line cannot be completed any further.
+func (p *Prompt) completeLine(line []rune) []rune {
+	if p.complete == nil {
+		return line
+	}
+	candidates := p.complete(string(line))
+	switch len(candidates) {
+	case 0:
+		fmt.Fprint(p.out, "\a")
+		return line
+	case 1:
+		return []rune(candidates[0])
+	}
+	if prefix := commonPrefix(candidates); len(prefix) > len(line) {
+		return prefix
+	}
+	fmt.Fprintf(p.out, "\r\n%s\r\n", strings.Join(candidates, "\r\n"))
+	return line
+}
+
+func commonPrefix(values []string) []rune {
+	prefix := []rune(values[0])
+	for _, v := range values[1:] {
+		r := []rune(v)
+		i := 0
+		for i < len(prefix) && i < len(r) && prefix[i] == r[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return prefix
+}
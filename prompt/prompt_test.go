@@ -0,0 +1,98 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package prompt
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestPrompt(input string, complete Completer) (*Prompt, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	return New(strings.NewReader(input), out, complete), out
+}
+
+func TestReadLineWithoutTerminal(t *testing.T) {
+	p, out := newTestPrompt("first\r\nsecond", nil)
+
+	for _, want := range []string{"first", "second"} {
+		got, err := p.ReadLine("> ")
+		if err != nil || got != want {
+			t.Errorf("Expected %q, got %q (%v)", want, got, err)
+		}
+	}
+	if _, err := p.ReadLine("> "); err != io.EOF {
+		t.Errorf("Expected EOF at the end of the input, got %v", err)
+	}
+	if out.String() != "> > > " {
+		t.Errorf("Expected only the prompts to be written, got %q", out.String())
+	}
+}
+
+func TestEditHandlesBackspaceAndClearLine(t *testing.T) {
+	p, _ := newTestPrompt("abc\x7fd\x15xy\x08z\r", nil)
+
+	got, err := p.edit("> ")
+
+	if err != nil || got != "xz" {
+		t.Errorf("Expected %q, got %q (%v)", "xz", got, err)
+	}
+}
+
+func TestEditCompletesTheOnlyCandidate(t *testing.T) {
+	p, _ := newTestPrompt("Say\t\r", func(line string) []string {
+		return []string{line + " hello"}
+	})
+
+	got, _ := p.edit("> ")
+
+	if got != "Say hello" {
+		t.Errorf("Expected the line to be completed, got %q", got)
+	}
+}
+
+func TestEditCompletesTheCommonPrefixAndListsTheCandidates(t *testing.T) {
+	candidates := []string{"Open the browser", "Open the page"}
+	p, out := newTestPrompt("O\t\t\r", func(line string) []string { return candidates })
+
+	got, _ := p.edit("> ")
+
+	if got != "Open the " {
+		t.Errorf("Expected the line to be completed till the common prefix, got %q", got)
+	}
+	if !strings.Contains(out.String(), "Open the browser\r\nOpen the page") {
+		t.Errorf("Expected the candidates to be listed, got %q", out.String())
+	}
+}
+
+func TestEditGoesThroughTheHistory(t *testing.T) {
+	p, _ := newTestPrompt("one\rtwo\r\x1b[A\x1b[A\x1b[B\r", nil)
+
+	p.edit("> ")
+	p.edit("> ")
+	got, _ := p.edit("> ")
+
+	if got != "two" {
+		t.Errorf("Expected the second line from the history, got %q", got)
+	}
+	if len(p.history) != 2 {
+		t.Errorf("Expected a repeated line not to be added to the history, got %v", p.history)
+	}
+}
+
+func TestEditInterruptAndEOF(t *testing.T) {
+	p, _ := newTestPrompt("ab\x03\x04", nil)
+
+	if _, err := p.edit("> "); err != ErrInterrupted {
+		t.Errorf("Expected Ctrl+C to interrupt, got %v", err)
+	}
+	if _, err := p.edit("> "); err != io.EOF {
+		t.Errorf("Expected Ctrl+D on an empty line to end the input, got %v", err)
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"fmt"
 	"strings"
 
+	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
 	"github.com/getgauge/gauge/util"
 )
 
@@ -78,3 +81,73 @@ func prepStacktrace(stacktrace string) string {
 func formatErrorFragment(fragment string, indentation int) string {
 	return indent(fragment, indentation+errorIndentation) + newline
 }
+
+// failure is a failed step or hook, reported by the CI consoles against the file and line where it occurred.
+type failure struct {
+	title      string
+	message    string
+	stackTrace string
+	file       string
+	line       int
+}
+
+// stepFailure returns the failure of the step or its hooks, or nil if the step passed.
+func stepFailure(step gauge.Step, res *result.StepResult) *failure {
+	r := res.ProtoStepExecResult()
+	f := &failure{title: "Failed Step: " + res.GetStepActualText(), file: step.FileName, line: step.LineNo}
+	switch {
+	case r.GetPreHookFailure() != nil:
+		f.title = "BeforeStep hook for step: " + res.GetStepActualText()
+		f.message, f.stackTrace = r.GetPreHookFailure().GetErrorMessage(), r.GetPreHookFailure().GetStackTrace()
+	case r.GetExecutionResult().GetFailed():
+		f.message, f.stackTrace = res.GetErrorMessage(), res.GetStackTrace()
+	case r.GetPostHookFailure() != nil:
+		f.title = "AfterStep hook for step: " + res.GetStepActualText()
+		f.message, f.stackTrace = r.GetPostHookFailure().GetErrorMessage(), r.GetPostHookFailure().GetStackTrace()
+	default:
+		return nil
+	}
+	return f
+}
+
+// hookFailure returns the failure of a spec or suite level hook, or nil if the hook passed.
+func hookFailure(hooks []*gm.ProtoHookFailure, title, file string, line int) *failure {
+	if len(hooks) == 0 {
+		return nil
+	}
+	return &failure{title: title, message: hooks[0].GetErrorMessage(), stackTrace: hooks[0].GetStackTrace(), file: file, line: line}
+}
+
+// scenarioFailure returns the failure which failed the scenario, given its first failed step if any.
+func scenarioFailure(scenario *gauge.Scenario, res result.Result, failedStep *failure, fileName string) *failure {
+	if f := hookFailure(res.GetPreHook(), "Before Scenario hook", fileName, scenario.Heading.LineNo); f != nil {
+		return f
+	}
+	if failedStep != nil {
+		return failedStep
+	}
+	if f := hookFailure(res.GetPostHook(), "After Scenario hook", fileName, scenario.Heading.LineNo); f != nil {
+		return f
+	}
+	return &failure{title: "Failed Scenario: " + scenario.Heading.Value, message: "The scenario failed.", file: fileName, line: scenario.Heading.LineNo}
+}
+
+// scenarioName is the name of the scenario for the CI consoles, which tells apart the rows of the data tables.
+func scenarioName(scenario *gauge.Scenario) string {
+	var specRow, scenarioRow int
+	if scenario.SpecDataTableRow.IsInitialized() {
+		specRow = scenario.SpecDataTableRowIndex + 1
+	}
+	if scenario.ScenarioDataTableRow.IsInitialized() {
+		scenarioRow = scenario.ScenarioDataTableRowIndex + 1
+	}
+	return result.WithTableRows(scenario.Heading.Value, result.TableRows(specRow, scenarioRow))
+}
+
+func skipReason(res *result.ScenarioResult) string {
+	reasons := res.ProtoScenario.GetSkipErrors()
+	if len(reasons) == 0 {
+		return "The scenario was skipped."
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,104 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/util"
+)
+
+var (
+	gitHubMessageEscaper  = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	gitHubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// gitHubConsole adds GitHub Actions workflow annotations to the output of a console, so that the failures are shown
+// against the lines of the specs and concepts where they occurred. The annotations are written as is to the output,
+// even if the console prefixes its lines with the stream of a parallel execution.
+type gitHubConsole struct {
+	Reporter
+	mu     *sync.Mutex
+	writer io.Writer
+}
+
+func newGitHubConsole(r Reporter, out io.Writer) *gitHubConsole {
+	return &gitHubConsole{Reporter: r, mu: &sync.Mutex{}, writer: out}
+}
+
+func (c *gitHubConsole) StepEnd(step gauge.Step, res result.Result, i *gm.ExecutionInfo) {
+	c.Reporter.StepEnd(step, res, i)
+	if f := stepFailure(step, res.(*result.StepResult)); f != nil {
+		c.annotate("error", f)
+	}
+}
+
+func (c *gitHubConsole) ScenarioEnd(scenario *gauge.Scenario, res result.Result, i *gm.ExecutionInfo) {
+	c.Reporter.ScenarioEnd(scenario, res, i)
+	file := i.CurrentSpec.GetFileName()
+	for _, f := range []*failure{
+		hookFailure(res.GetPreHook(), "Before Scenario hook", file, scenario.Heading.LineNo),
+		hookFailure(res.GetPostHook(), "After Scenario hook", file, scenario.Heading.LineNo),
+	} {
+		if f != nil {
+			c.annotate("error", f)
+		}
+	}
+	if sRes := res.(*result.ScenarioResult); sRes.IsFlaky() {
+		c.annotate("warning", &failure{
+			title:   "Flaky Scenario: " + scenarioName(scenario),
+			message: fmt.Sprintf("The scenario passed after %d attempts.", sRes.ProtoScenario.GetRetriesCount()),
+			file:    file,
+			line:    scenario.Heading.LineNo,
+		})
+	}
+}
+
+func (c *gitHubConsole) SpecEnd(spec *gauge.Specification, res result.Result) {
+	c.Reporter.SpecEnd(spec, res)
+	for _, f := range []*failure{
+		hookFailure(res.GetPreHook(), "Before Specification hook", spec.FileName, spec.Heading.LineNo),
+		hookFailure(res.GetPostHook(), "After Specification hook", spec.FileName, spec.Heading.LineNo),
+	} {
+		if f != nil {
+			c.annotate("error", f)
+		}
+	}
+}
+
+func (c *gitHubConsole) SuiteEnd(res result.Result) {
+	c.Reporter.SuiteEnd(res)
+	for _, f := range []*failure{
+		hookFailure(res.GetPreHook(), "Before Suite hook", "", 0),
+		hookFailure(res.GetPostHook(), "After Suite hook", "", 0),
+	} {
+		if f != nil {
+			c.annotate("error", f)
+		}
+	}
+}
+
+func (c *gitHubConsole) annotate(level string, f *failure) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var properties []string
+	if f.file != "" {
+		properties = append(properties, "file="+gitHubPropertyEscaper.Replace(filepath.ToSlash(util.RelPathToProjectRoot(f.file))))
+		if f.line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", f.line))
+		}
+	}
+	properties = append(properties, "title="+gitHubPropertyEscaper.Replace(f.title))
+	fmt.Fprintf(c.writer, "::%s %s::%s%s", level, strings.Join(properties, ","), gitHubMessageEscaper.Replace(f.message), newline)
+}
@@ -0,0 +1,42 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package reporter
+
+import (
+	"strings"
+
+	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestGitHubConsoleAnnotatesTheLineOfTheFailedStep(c *C) {
+	annotations := newDummyWriter()
+	gc := newGitHubConsole(newSimpleConsole(newDummyWriter()), annotations)
+	i := ciExecutionInfo()
+
+	gc.StepEnd(gauge.Step{FileName: "specs/concepts/vowels.cpt", LineNo: 5}, failedStepResult("Vowels in gauge, again", "expected: 3\nactual: 2", ""), i)
+	gc.StepEnd(gauge.Step{FileName: "specs/example.spec", LineNo: 6}, result.NewStepResult(&gm.ProtoStep{StepExecutionResult: &gm.ProtoStepExecutionResult{ExecutionResult: &gm.ProtoExecutionResult{}}}), i)
+
+	c.Assert(annotations.output, Equals, "::error file=specs/concepts/vowels.cpt,line=5,title=Failed Step%3A Vowels in gauge%2C again::expected: 3%0Aactual: 2\n")
+}
+
+func (s *MySuite) TestGitHubConsoleAnnotatesHookFailuresAndFlakyScenarios(c *C) {
+	console, annotations := newDummyWriter(), newDummyWriter()
+	gc := newGitHubConsole(newTAPConsole(console), annotations)
+	i := ciExecutionInfo()
+	scn := ciScenario("Vowel counts", 10)
+
+	gc.ScenarioEnd(scn, result.NewScenarioResult(&gm.ProtoScenario{ExecutionStatus: gm.ExecutionStatus_PASSED, RetriesCount: 2}), i)
+	gc.SuiteEnd(&result.SuiteResult{PostSuite: &gm.ProtoHookFailure{ErrorMessage: "100% disk usage"}})
+
+	c.Assert(annotations.output, Equals, `::warning file=specs/example.spec,line=10,title=Flaky Scenario%3A Vowel counts::The scenario passed after 2 attempts.
+::error title=After Suite hook::100%25 disk usage
+`)
+	c.Assert(strings.HasPrefix(console.output, "ok 1 - Example: Vowel counts (attempt 2)\nnot ok 2 - After Suite hook\n"), Equals, true)
+}
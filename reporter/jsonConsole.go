@@ -15,6 +15,7 @@ import (
 	"sync"
 
 	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/quarantine"
 	"github.com/getgauge/gauge/execution/result"
 	"github.com/getgauge/gauge/formatter"
 	"github.com/getgauge/gauge/gauge"
@@ -35,6 +36,7 @@ const (
 	pass          status    = "pass"
 	fail          status    = "fail"
 	skip          status    = "skip"
+	interrupted   status    = "interrupted"
 )
 
 type jsonConsole struct {
@@ -69,6 +71,8 @@ type executionResult struct {
 	BeforeHookFailure *executionError  `json:"beforeHookFailure,omitempty"`
 	AfterHookFailure  *executionError  `json:"afterHookFailure,omitempty"`
 	Table             *tableInfo       `json:"table,omitempty"`
+	Flaky             bool             `json:"flaky,omitempty"`
+	Quarantined       bool             `json:"quarantined,omitempty"`
 }
 
 type tableInfo struct {
@@ -98,11 +102,15 @@ func (c *jsonConsole) SuiteEnd(res result.Result) {
 	c.Lock()
 	defer c.Unlock()
 	sRes := res.(*result.SuiteResult)
+	s := getStatus(sRes.IsFailed, false)
+	if sRes.Interrupted {
+		s = interrupted
+	}
 	c.write(executionEvent{
 		EventType: suiteEnd,
 		Stream:    c.stream,
 		Res: &executionResult{
-			Status:            getStatus(sRes.IsFailed, false),
+			Status:            s,
 			BeforeHookFailure: getHookFailure(res.GetPreHook(), "Before Suite"),
 			AfterHookFailure:  getHookFailure(res.GetPostHook(), "After Suite"),
 		},
@@ -183,11 +191,20 @@ func (c *jsonConsole) ScenarioEnd(scenario *gauge.Scenario, res result.Result, i
 			BeforeHookFailure: getHookFailure(res.GetPreHook(), "Before Scenario"),
 			AfterHookFailure:  getHookFailure(res.GetPostHook(), "After Scenario"),
 			Table:             getTable(scenario),
+			Flaky:             res.(*result.ScenarioResult).IsFlaky(),
+			Quarantined:       quarantine.Current().Contains(i.CurrentSpec.FileName, i.CurrentSpec.Tags, scenario.Heading.Value, getTagValues(scenario.Tags)),
 		},
 	}
 	c.write(e)
 }
 
+func getTagValues(tags *gauge.Tags) []string {
+	if tags == nil {
+		return nil
+	}
+	return tags.Values()
+}
+
 func getAllStepsFromScenario(scenario *gm.ProtoScenario) []*gm.ProtoItem {
 	return append(scenario.GetContexts(), append(scenario.GetScenarioItems(), scenario.GetTearDownSteps()...)...)
 }
@@ -782,3 +782,42 @@ func (s *MySuite) TestSuiteEndWithBeforeAndAfterHookFailure_JSONConsole(c *C) {
 	jc.SuiteEnd(res)
 	c.Assert(dw.output, Equals, expected)
 }
+
+func (s *MySuite) TestScenarioEndForFlakyQuarantinedScenario_JSONConsole(c *C) {
+	dw, jc := setupJSONConsole()
+
+	protoScenario := &gauge_messages.ProtoScenario{
+		ScenarioHeading: "Scenario",
+		ExecutionStatus: gauge_messages.ExecutionStatus_PASSED,
+		RetriesCount:    2,
+	}
+
+	scenario := &gauge.Scenario{
+		Heading: &gauge.Heading{
+			Value:       "Scenario",
+			LineNo:      2,
+			HeadingType: 1,
+		},
+		Span: &gauge.Span{
+			Start: 2,
+			End:   3,
+		},
+		Tags: &gauge.Tags{RawValues: [][]string{{"quarantine"}}},
+	}
+
+	info := &gauge_messages.ExecutionInfo{
+		CurrentSpec: &gauge_messages.SpecInfo{
+			Name:     "Specification",
+			FileName: "file",
+		},
+		CurrentScenario: &gauge_messages.ScenarioInfo{
+			Name: "Scenario",
+		},
+	}
+
+	expected := `{"type":"scenarioEnd","id":"file:2","parentId":"file","name":"Scenario","filename":"file","line":2,"result":{"status":"pass","time":0,"flaky":true,"quarantined":true}}
+`
+
+	jc.ScenarioEnd(scenario, &result.ScenarioResult{ProtoScenario: protoScenario}, info)
+	c.Assert(dw.output, Equals, expected)
+}
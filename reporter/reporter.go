@@ -37,8 +37,35 @@ var Verbose bool
 // MachineReadable represents if output should be in JSON format.
 var MachineReadable bool
 
+// Format is the format of the console output understood by a CI server. The console is chosen by the other flags if it is empty.
+var Format string
+
 const newline = "\n"
 
+const (
+	tapFormat      = "tap"
+	teamCityFormat = "teamcity"
+	gitHubFormat   = "github"
+)
+
+// Formats returns the formats of the console output understood by the CI servers.
+func Formats() []string {
+	return []string{tapFormat, teamCityFormat, gitHubFormat}
+}
+
+// IsValidFormat returns true if the format is empty or one of the supported console formats.
+func IsValidFormat(format string) bool {
+	if format == "" {
+		return true
+	}
+	for _, f := range Formats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
 // Reporter reports the progress of spec execution. It reports
 // 1. Which spec / scenarion / step (if verbose) is currently executing.
 // 2. Status (pass/fail) of the spec / scenario / step (if verbose) once its executed.
@@ -74,6 +101,10 @@ func Current() Reporter {
 	if currentReporter == nil {
 		if MachineReadable {
 			currentReporter = newJSONConsole(os.Stdout, IsParallel, 0)
+		} else if Format == tapFormat {
+			currentReporter = newTAPConsole(os.Stdout)
+		} else if Format == teamCityFormat {
+			currentReporter = newTeamCityConsole(os.Stdout, 0)
 		} else if SimpleConsoleOutput {
 			currentReporter = newSimpleConsole(os.Stdout)
 		} else if Verbose {
@@ -81,6 +112,9 @@ func Current() Reporter {
 		} else {
 			currentReporter = newColoredConsole(os.Stdout)
 		}
+		if Format == gitHubFormat {
+			currentReporter = newGitHubConsole(currentReporter, os.Stdout)
+		}
 	}
 	return currentReporter
 }
@@ -108,9 +142,17 @@ func initParallelReporters() {
 	for i := 1; i <= NumberOfExecutionStreams; i++ {
 		if MachineReadable {
 			parallelReporters[i] = newJSONConsole(os.Stdout, true, i)
+		} else if Format == tapFormat {
+			// the test points of all the streams are numbered in a single plan
+			parallelReporters[i] = Current()
+		} else if Format == teamCityFormat {
+			parallelReporters[i] = newTeamCityConsole(os.Stdout, i)
 		} else {
 			writer := &parallelReportWriter{nRunner: i}
 			parallelReporters[i] = newSimpleConsole(writer)
+			if Format == gitHubFormat {
+				parallelReporters[i] = newGitHubConsole(parallelReporters[i], os.Stdout)
+			}
 		}
 	}
 }
@@ -161,6 +203,7 @@ func ListenExecutionEvents(wg *sync.WaitGroup) {
 			case event.SuiteEnd:
 				r.SuiteEnd(e.Result)
 				wg.Done()
+				return
 			}
 		}
 	}()
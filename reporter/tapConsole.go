@@ -0,0 +1,228 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	"github.com/getgauge/gauge/util"
+)
+
+// tapConsole reports each scenario as a TAP 13 test point, with a YAML block describing the failures.
+// The streams of a parallel execution share it, as the test points are numbered in the order they end.
+// Only the last attempt of a retried scenario is reported, so the test point of a failed scenario is held back
+// till the scenario starts again, or its stream moves on to another scenario or spec.
+type tapConsole struct {
+	mu          *sync.Mutex
+	writer      io.Writer
+	count       int
+	failedSteps map[*gm.ScenarioInfo]*failure
+	pending     map[*gauge.Scenario]*tapTestPoint
+}
+
+type tapTestPoint struct {
+	stream      int32
+	description string
+	failure     *failure
+	ms          int64
+}
+
+func newTAPConsole(out io.Writer) *tapConsole {
+	return &tapConsole{mu: &sync.Mutex{}, writer: out, failedSteps: make(map[*gm.ScenarioInfo]*failure), pending: make(map[*gauge.Scenario]*tapTestPoint)}
+}
+
+func (c *tapConsole) SuiteStart() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.writer, "TAP version 13%s", newline)
+}
+
+func (c *tapConsole) SpecStart(spec *gauge.Specification, res result.Result) {
+}
+
+func (c *tapConsole) SpecEnd(spec *gauge.Specification, res result.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, scn := range spec.Scenarios {
+		c.flush(scn)
+	}
+	c.hookFailure(res.GetPreHook(), spec.Heading.Value+": Before Specification hook", spec.FileName, spec.Heading.LineNo)
+	c.hookFailure(res.GetPostHook(), spec.Heading.Value+": After Specification hook", spec.FileName, spec.Heading.LineNo)
+}
+
+func (c *tapConsole) ScenarioStart(scenario *gauge.Scenario, i *gm.ExecutionInfo, res result.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the failed attempt is retried
+	delete(c.pending, scenario)
+	c.flushStream(i.GetRunnerId())
+}
+
+func (c *tapConsole) ScenarioEnd(scenario *gauge.Scenario, res result.Result, i *gm.ExecutionInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, scenario)
+	c.flushStream(i.GetRunnerId())
+	sRes := res.(*result.ScenarioResult)
+	failedStep := c.failedSteps[i.CurrentScenario]
+	delete(c.failedSteps, i.CurrentScenario)
+	description := i.CurrentSpec.GetName() + ": " + scenarioName(scenario)
+	if attempt := sRes.ProtoScenario.GetRetriesCount(); attempt > 1 {
+		description += fmt.Sprintf(" (attempt %d)", attempt)
+	}
+	switch sRes.ProtoScenario.GetExecutionStatus() {
+	case gm.ExecutionStatus_FAILED:
+		c.pending[scenario] = &tapTestPoint{stream: i.GetRunnerId(), description: description,
+			failure: scenarioFailure(scenario, res, failedStep, i.CurrentSpec.GetFileName()), ms: res.ExecTime()}
+	case gm.ExecutionStatus_SKIPPED:
+		c.testPoint(true, description, "SKIP "+singleLine(skipReason(sRes)), nil, 0)
+	default:
+		c.testPoint(true, description, "", nil, res.ExecTime())
+	}
+}
+
+func (c *tapConsole) StepStart(stepText string) {
+}
+
+func (c *tapConsole) StepEnd(step gauge.Step, res result.Result, i *gm.ExecutionInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.failedSteps[i.CurrentScenario]; ok {
+		return
+	}
+	if f := stepFailure(step, res.(*result.StepResult)); f != nil {
+		c.failedSteps[i.CurrentScenario] = f
+	}
+}
+
+func (c *tapConsole) ConceptStart(conceptHeading string) {
+}
+
+func (c *tapConsole) ConceptEnd(res result.Result) {
+}
+
+func (c *tapConsole) DataTable(table string) {
+}
+
+func (c *tapConsole) SuiteEnd(res result.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var held []*gauge.Scenario
+	for scn := range c.pending {
+		held = append(held, scn)
+	}
+	sort.Slice(held, func(i, j int) bool { return c.pending[held[i]].description < c.pending[held[j]].description })
+	for _, scn := range held {
+		c.flush(scn)
+	}
+	c.hookFailure(res.GetPreHook(), "Before Suite hook", "", 0)
+	c.hookFailure(res.GetPostHook(), "After Suite hook", "", 0)
+	if res.(*result.SuiteResult).Interrupted {
+		fmt.Fprintf(c.writer, "Bail out! The execution was interrupted.%s", newline)
+		return
+	}
+	fmt.Fprintf(c.writer, "1..%d%s", c.count, newline)
+}
+
+func (c *tapConsole) Errorf(text string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprint(c.writer, comment(fmt.Sprintf(text, args...)))
+}
+
+// Write prints the output of the runner as TAP comments, so that it is not taken for test points.
+func (c *tapConsole) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprint(c.writer, comment(string(b))); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *tapConsole) hookFailure(hooks []*gm.ProtoHookFailure, title, file string, line int) {
+	if f := hookFailure(hooks, title, file, line); f != nil {
+		c.testPoint(false, title, "", f, 0)
+	}
+}
+
+// flush writes the held back test point of the failed scenario, if any
+func (c *tapConsole) flush(scenario *gauge.Scenario) {
+	if p, ok := c.pending[scenario]; ok {
+		delete(c.pending, scenario)
+		c.testPoint(false, p.description, "", p.failure, p.ms)
+	}
+}
+
+func (c *tapConsole) flushStream(stream int32) {
+	for scn, p := range c.pending {
+		if p.stream == stream {
+			c.flush(scn)
+		}
+	}
+}
+
+func (c *tapConsole) testPoint(ok bool, description, directive string, f *failure, ms int64) {
+	c.count++
+	status := "ok"
+	if !ok {
+		status = "not ok"
+	}
+	fmt.Fprintf(c.writer, "%s %d - %s", status, c.count, singleLine(strings.ReplaceAll(description, "#", "\\#")))
+	if directive != "" {
+		fmt.Fprintf(c.writer, " # %s", directive)
+	}
+	fmt.Fprint(c.writer, newline)
+	if f != nil {
+		fmt.Fprint(c.writer, diagnostics(f, ms))
+	}
+}
+
+// diagnostics is the YAML block following a failed test point.
+func diagnostics(f *failure, ms int64) string {
+	var b strings.Builder
+	b.WriteString("  ---" + newline)
+	b.WriteString("  message: " + strconv.Quote(f.message) + newline)
+	b.WriteString("  severity: fail" + newline)
+	b.WriteString("  title: " + strconv.Quote(f.title) + newline)
+	if f.file != "" {
+		b.WriteString("  at:" + newline)
+		b.WriteString("    file: " + strconv.Quote(util.RelPathToProjectRoot(f.file)) + newline)
+		b.WriteString(fmt.Sprintf("    line: %d%s", f.line, newline))
+	}
+	if stack := strings.TrimRight(f.stackTrace, "\r\n"); stack != "" {
+		b.WriteString("  stack: |-" + newline)
+		for _, l := range strings.Split(stack, newline) {
+			b.WriteString("    " + strings.TrimRight(l, "\r") + newline)
+		}
+	}
+	if ms > 0 {
+		b.WriteString(fmt.Sprintf("  duration_ms: %d%s", ms, newline))
+	}
+	b.WriteString("  ..." + newline)
+	return b.String()
+}
+
+func comment(text string) string {
+	var b strings.Builder
+	for _, l := range strings.Split(strings.TrimRight(text, "\r\n"), newline) {
+		b.WriteString(strings.TrimRight("# "+strings.TrimRight(l, "\r"), " ") + newline)
+	}
+	return b.String()
+}
+
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,128 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package reporter
+
+import (
+	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	. "gopkg.in/check.v1"
+)
+
+func ciExecutionInfo() *gm.ExecutionInfo {
+	return &gm.ExecutionInfo{
+		CurrentSpec:     &gm.SpecInfo{Name: "Example", FileName: "specs/example.spec"},
+		CurrentScenario: &gm.ScenarioInfo{Name: "Vowel counts"},
+	}
+}
+
+func failedStepResult(text, message, stackTrace string) *result.StepResult {
+	return result.NewStepResult(&gm.ProtoStep{ActualText: text, StepExecutionResult: &gm.ProtoStepExecutionResult{
+		ExecutionResult: &gm.ProtoExecutionResult{Failed: true, ErrorMessage: message, StackTrace: stackTrace},
+	}})
+}
+
+func ciScenario(heading string, line int) *gauge.Scenario {
+	return &gauge.Scenario{Heading: &gauge.Heading{Value: heading, LineNo: line}}
+}
+
+func (s *MySuite) TestTAPConsoleReportsEachScenarioAsATestPoint(c *C) {
+	dw := newDummyWriter()
+	tc := newTAPConsole(dw)
+	i := ciExecutionInfo()
+
+	tc.SuiteStart()
+	tc.ScenarioEnd(ciScenario("Passing", 3), result.NewScenarioResult(&gm.ProtoScenario{ExecutionStatus: gm.ExecutionStatus_PASSED}), i)
+	tc.ScenarioEnd(ciScenario("Skipped", 8), result.NewScenarioResult(&gm.ProtoScenario{ExecutionStatus: gm.ExecutionStatus_SKIPPED, SkipErrors: []string{"Step implementation not found"}}), i)
+	tc.SuiteEnd(&result.SuiteResult{})
+
+	c.Assert(dw.output, Equals, `TAP version 13
+ok 1 - Example: Passing
+ok 2 - Example: Skipped # SKIP Step implementation not found
+1..2
+`)
+}
+
+func (s *MySuite) TestTAPConsoleDescribesTheFailedStepInTheDiagnostics(c *C) {
+	dw := newDummyWriter()
+	tc := newTAPConsole(dw)
+	i := ciExecutionInfo()
+
+	tc.StepEnd(gauge.Step{FileName: "specs/example.spec", LineNo: 12}, failedStepResult("Vowels in \"gauge\" are \"eu\"", "expected 3 but was 2", "at Steps.vowels\nat Runner.run"), i)
+	tc.ScenarioEnd(ciScenario("Vowel counts", 10), result.NewScenarioResult(&gm.ProtoScenario{ExecutionStatus: gm.ExecutionStatus_FAILED, ExecutionTime: 25}), i)
+	tc.SuiteEnd(&result.SuiteResult{})
+
+	c.Assert(dw.output, Equals, `not ok 1 - Example: Vowel counts
+  ---
+  message: "expected 3 but was 2"
+  severity: fail
+  title: "Failed Step: Vowels in \"gauge\" are \"eu\""
+  at:
+    file: "specs/example.spec"
+    line: 12
+  stack: |-
+    at Steps.vowels
+    at Runner.run
+  duration_ms: 25
+  ...
+1..1
+`)
+}
+
+func (s *MySuite) TestTAPConsoleReportsOnlyTheLastAttemptOfARetriedScenario(c *C) {
+	dw := newDummyWriter()
+	tc := newTAPConsole(dw)
+	flaky, broken, next := ciScenario("Flaky", 3), ciScenario("Broken", 8), ciScenario("Next", 13)
+	attempt := func(scn *gauge.Scenario, status gm.ExecutionStatus, count int64) {
+		i := ciExecutionInfo()
+		res := result.NewScenarioResult(&gm.ProtoScenario{ExecutionStatus: status, RetriesCount: count})
+		tc.ScenarioStart(scn, i, res)
+		tc.ScenarioEnd(scn, res, i)
+	}
+
+	tc.SuiteStart()
+	attempt(flaky, gm.ExecutionStatus_FAILED, 1)
+	attempt(flaky, gm.ExecutionStatus_PASSED, 2)
+	attempt(broken, gm.ExecutionStatus_FAILED, 1)
+	attempt(broken, gm.ExecutionStatus_FAILED, 2)
+	attempt(next, gm.ExecutionStatus_PASSED, 1)
+	tc.SpecEnd(&gauge.Specification{Heading: &gauge.Heading{Value: "Example"}, Scenarios: []*gauge.Scenario{flaky, broken, next}}, &result.SpecResult{ProtoSpec: &gm.ProtoSpec{}})
+	tc.SuiteEnd(&result.SuiteResult{})
+
+	c.Assert(dw.output, Equals, `TAP version 13
+ok 1 - Example: Flaky (attempt 2)
+not ok 2 - Example: Broken (attempt 2)
+  ---
+  message: "The scenario failed."
+  severity: fail
+  title: "Failed Scenario: Broken"
+  at:
+    file: "specs/example.spec"
+    line: 8
+  ...
+ok 3 - Example: Next
+1..3
+`)
+}
+
+func (s *MySuite) TestTAPConsoleBailsOutIfTheExecutionIsInterrupted(c *C) {
+	dw := newDummyWriter()
+	tc := newTAPConsole(dw)
+
+	tc.Write([]byte("runner output\n"))
+	tc.SuiteEnd(&result.SuiteResult{Interrupted: true, PreSuite: &gm.ProtoHookFailure{ErrorMessage: "db is down"}})
+
+	c.Assert(dw.output, Equals, `# runner output
+not ok 1 - Before Suite hook
+  ---
+  message: "db is down"
+  severity: fail
+  title: "Before Suite hook"
+  ...
+Bail out! The execution was interrupted.
+`)
+}
@@ -0,0 +1,144 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+)
+
+var teamCityEscaper = strings.NewReplacer("|", "||", "'", "|'", "\n", "|n", "\r", "|r", "[", "|[", "]", "|]", "\u0085", "|x", "\u2028", "|l", "\u2029", "|p")
+
+// teamCityConsole reports the specs as test suites and the scenarios as tests using TeamCity service messages.
+// Each stream of a parallel execution has its own console, whose messages carry the stream as the flow id.
+type teamCityConsole struct {
+	mu         *sync.Mutex
+	writer     io.Writer
+	flowID     string
+	failedStep *failure
+}
+
+func newTeamCityConsole(out io.Writer, stream int) *teamCityConsole {
+	c := &teamCityConsole{mu: &sync.Mutex{}, writer: out}
+	if stream > 0 {
+		c.flowID = strconv.Itoa(stream)
+	}
+	return c
+}
+
+func (c *teamCityConsole) SuiteStart() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the last attempt of a retried scenario decides its status
+	c.message("testRetrySupport", "enabled", "true")
+}
+
+func (c *teamCityConsole) SpecStart(spec *gauge.Specification, res result.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.message("testSuiteStarted", "name", spec.Heading.Value)
+}
+
+func (c *teamCityConsole) SpecEnd(spec *gauge.Specification, res result.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hookFailure(res.GetPreHook(), "Before Specification hook")
+	c.hookFailure(res.GetPostHook(), "After Specification hook")
+	c.message("testSuiteFinished", "name", spec.Heading.Value)
+}
+
+func (c *teamCityConsole) ScenarioStart(scenario *gauge.Scenario, i *gm.ExecutionInfo, res result.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failedStep = nil
+	c.message("testStarted", "name", scenarioName(scenario), "captureStandardOutput", "true")
+}
+
+func (c *teamCityConsole) ScenarioEnd(scenario *gauge.Scenario, res result.Result, i *gm.ExecutionInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := scenarioName(scenario)
+	sRes := res.(*result.ScenarioResult)
+	switch sRes.ProtoScenario.GetExecutionStatus() {
+	case gm.ExecutionStatus_FAILED:
+		f := scenarioFailure(scenario, res, c.failedStep, i.CurrentSpec.GetFileName())
+		c.message("testFailed", "name", name, "message", f.title+": "+f.message, "details", f.stackTrace)
+	case gm.ExecutionStatus_SKIPPED:
+		c.message("testIgnored", "name", name, "message", skipReason(sRes))
+	}
+	c.message("testFinished", "name", name, "duration", strconv.FormatInt(res.ExecTime(), 10))
+}
+
+func (c *teamCityConsole) StepStart(stepText string) {
+}
+
+func (c *teamCityConsole) StepEnd(step gauge.Step, res result.Result, i *gm.ExecutionInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failedStep == nil {
+		c.failedStep = stepFailure(step, res.(*result.StepResult))
+	}
+}
+
+func (c *teamCityConsole) ConceptStart(conceptHeading string) {
+}
+
+func (c *teamCityConsole) ConceptEnd(res result.Result) {
+}
+
+func (c *teamCityConsole) DataTable(table string) {
+}
+
+func (c *teamCityConsole) SuiteEnd(res result.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hookFailure(res.GetPreHook(), "Before Suite hook")
+	c.hookFailure(res.GetPostHook(), "After Suite hook")
+	if res.(*result.SuiteResult).Interrupted {
+		c.message("buildProblem", "description", "The execution was interrupted.")
+	}
+}
+
+func (c *teamCityConsole) Errorf(text string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.message("message", "text", fmt.Sprintf(text, args...), "status", "ERROR")
+}
+
+// Write prints the output of the runner as is, which TeamCity adds to the output of the test in progress.
+func (c *teamCityConsole) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writer.Write(b)
+}
+
+func (c *teamCityConsole) hookFailure(hooks []*gm.ProtoHookFailure, title string) {
+	if f := hookFailure(hooks, title, "", 0); f != nil {
+		c.message("message", "text", f.title+" failed: "+f.message, "errorDetails", f.stackTrace, "status", "ERROR")
+	}
+}
+
+// message prints a service message with the given attribute names and values, adding the flow id of the stream.
+func (c *teamCityConsole) message(name string, attributes ...string) {
+	if c.flowID != "" {
+		attributes = append(attributes, "flowId", c.flowID)
+	}
+	var b strings.Builder
+	b.WriteString("##teamcity[" + name)
+	for i := 0; i+1 < len(attributes); i += 2 {
+		fmt.Fprintf(&b, " %s='%s'", attributes[i], teamCityEscaper.Replace(attributes[i+1]))
+	}
+	b.WriteString("]" + newline)
+	fmt.Fprint(c.writer, b.String())
+}
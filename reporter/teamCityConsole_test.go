@@ -0,0 +1,53 @@
+/*----------------------------------------------------------------
+ *  Copyright (c) ThoughtWorks, Inc.
+ *  Licensed under the Apache License, Version 2.0
+ *  See LICENSE in the project root for license information.
+ *----------------------------------------------------------------*/
+
+package reporter
+
+import (
+	gm "github.com/getgauge/gauge-proto/go/gauge_messages"
+	"github.com/getgauge/gauge/execution/result"
+	"github.com/getgauge/gauge/gauge"
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestTeamCityConsoleReportsTheScenariosAsTests(c *C) {
+	dw := newDummyWriter()
+	tc := newTeamCityConsole(dw, 0)
+	i := ciExecutionInfo()
+	spec := &gauge.Specification{Heading: &gauge.Heading{Value: "Example"}}
+	scn := ciScenario("Vowel counts", 10)
+	scn.SpecDataTableRow = *gauge.NewTable([]string{"word"}, [][]gauge.TableCell{{{Value: "gauge", CellType: gauge.Static}}}, 0)
+	scn.SpecDataTableRowIndex = 1
+
+	tc.SpecStart(spec, &result.SpecResult{})
+	tc.ScenarioStart(scn, i, result.NewScenarioResult(&gm.ProtoScenario{}))
+	tc.StepEnd(gauge.Step{}, failedStepResult("Vowels in [gauge]", "it's 2", "line 1\nline 2"), i)
+	tc.ScenarioEnd(scn, result.NewScenarioResult(&gm.ProtoScenario{ExecutionStatus: gm.ExecutionStatus_FAILED, ExecutionTime: 7}), i)
+	tc.SpecEnd(spec, &result.SpecResult{ProtoSpec: &gm.ProtoSpec{}})
+
+	c.Assert(dw.output, Equals, `##teamcity[testSuiteStarted name='Example']
+##teamcity[testStarted name='Vowel counts |[spec row 2|]' captureStandardOutput='true']
+##teamcity[testFailed name='Vowel counts |[spec row 2|]' message='Failed Step: Vowels in |[gauge|]: it|'s 2' details='line 1|nline 2']
+##teamcity[testFinished name='Vowel counts |[spec row 2|]' duration='7']
+##teamcity[testSuiteFinished name='Example']
+`)
+}
+
+func (s *MySuite) TestTeamCityConsoleAddsTheStreamAsTheFlowID(c *C) {
+	dw := newDummyWriter()
+	tc := newTeamCityConsole(dw, 2)
+	i := ciExecutionInfo()
+	scn := ciScenario("Skipped", 4)
+	res := result.NewScenarioResult(&gm.ProtoScenario{ExecutionStatus: gm.ExecutionStatus_SKIPPED, SkipErrors: []string{"Step implementation not found"}})
+
+	tc.ScenarioStart(scn, i, res)
+	tc.ScenarioEnd(scn, res, i)
+
+	c.Assert(dw.output, Equals, `##teamcity[testStarted name='Skipped' captureStandardOutput='true' flowId='2']
+##teamcity[testIgnored name='Skipped' message='Step implementation not found' flowId='2']
+##teamcity[testFinished name='Skipped' duration='0' flowId='2']
+`)
+}
@@ -300,13 +300,17 @@ func (r *GrpcRunner) Pid() int {
 
 // StartGrpcRunner makes a connection with grpc server
 func StartGrpcRunner(m *manifest.Manifest, stdout, stderr io.Writer, timeout time.Duration, shouldWriteToStdout bool) (*GrpcRunner, error) {
+	return startGrpcRunner(m, stdout, stderr, timeout, nil)
+}
+
+func startGrpcRunner(m *manifest.Manifest, stdout, stderr io.Writer, timeout time.Duration, envVars []string) (*GrpcRunner, error) {
 	portChan := make(chan string)
 	errChan := make(chan error)
 	logWriter := &logger.LogWriter{
 		Stderr: logger.NewCustomWriter(portChan, stderr, m.Language, true),
 		Stdout: logger.NewCustomWriter(portChan, stdout, m.Language, false),
 	}
-	cmd, info, err := runRunnerCommand(m, "0", false, logWriter)
+	cmd, info, err := runRunnerCommand(m, "0", false, logWriter, envVars)
 	if err != nil {
 		return nil, fmt.Errorf("Error occurred while starting runner process.\nError : %w", err)
 	}
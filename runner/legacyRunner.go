@@ -154,7 +154,11 @@ func (r *LegacyRunner) ExecuteMessageWithTimeout(message *gauge_messages.Message
 // StartLegacyRunner looks for a runner configuration inside the runner directory
 // finds the runner configuration matching to the manifest and executes the commands for the current OS
 func StartLegacyRunner(manifest *manifest.Manifest, port string, outputStreamWriter *logger.LogWriter, killChannel chan bool, debug bool) (*LegacyRunner, error) {
-	cmd, r, err := runRunnerCommand(manifest, port, debug, outputStreamWriter)
+	return startLegacyRunner(manifest, port, outputStreamWriter, killChannel, debug, nil)
+}
+
+func startLegacyRunner(manifest *manifest.Manifest, port string, outputStreamWriter *logger.LogWriter, killChannel chan bool, debug bool, envVars []string) (*LegacyRunner, error) {
+	cmd, r, err := runRunnerCommand(manifest, port, debug, outputStreamWriter, envVars)
 	if err != nil {
 		return nil, err
 	}
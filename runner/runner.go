@@ -21,6 +21,7 @@ import (
 	"github.com/getgauge/gauge-proto/go/gauge_messages"
 	"github.com/getgauge/gauge/config"
 	"github.com/getgauge/gauge/conn"
+	"github.com/getgauge/gauge/env"
 	"github.com/getgauge/gauge/logger"
 	"github.com/getgauge/gauge/manifest"
 	"github.com/getgauge/gauge/plugin"
@@ -119,7 +120,8 @@ func errorResult(message string) *gauge_messages.ProtoExecutionResult {
 	return &gauge_messages.ProtoExecutionResult{Failed: true, ErrorMessage: message, RecoverableError: false}
 }
 
-func runRunnerCommand(manifest *manifest.Manifest, port string, debug bool, writer *logger.LogWriter) (*exec.Cmd, *RunnerInfo, error) {
+// runRunnerCommand starts the runner process with the env of gauge, overridden by the given env vars.
+func runRunnerCommand(manifest *manifest.Manifest, port string, debug bool, writer *logger.LogWriter, envVars []string) (*exec.Cmd, *RunnerInfo, error) {
 	var r RunnerInfo
 	runnerDir, err := getLanguageJSONFilePath(manifest, &r)
 	if err != nil {
@@ -130,7 +132,7 @@ func runRunnerCommand(manifest *manifest.Manifest, port string, debug bool, writ
 		return nil, nil, fmt.Errorf("Compatibility error. %s", compatibilityErr.Error())
 	}
 	command := getOsSpecificCommand(&r)
-	env := getCleanEnv(port, os.Environ(), debug, getPluginPaths())
+	env := append(getCleanEnv(port, os.Environ(), debug, getPluginPaths()), envVars...)
 	cmd, err := common.ExecuteCommandWithEnv(command, runnerDir, writer.Stdout, writer.Stderr, env)
 	return cmd, &r, err
 }
@@ -213,9 +215,13 @@ func getOsSpecificCommand(r *RunnerInfo) []string {
 }
 
 func Start(manifest *manifest.Manifest, stream int, killChannel chan bool, debug bool) (Runner, error) {
+	envVars, err := streamEnv(stream)
+	if err != nil {
+		return nil, err
+	}
 	ri, err := GetRunnerInfo(manifest.Language)
 	if err == nil && ri.GRPCSupport {
-		return StartGrpcRunner(manifest, os.Stdout, os.Stderr, config.RunnerRequestTimeout(), true)
+		return startGrpcRunner(manifest, os.Stdout, os.Stderr, config.RunnerRequestTimeout(), envVars)
 	}
 
 	writer := logger.NewLogWriter(manifest.Language, true, stream)
@@ -228,7 +234,7 @@ func Start(manifest *manifest.Manifest, stream int, killChannel chan bool, debug
 		return nil, err
 	}
 	logger.Debugf(true, "Staring %s runner", manifest.Language)
-	runner, err := StartLegacyRunner(manifest, strconv.Itoa(handler.ConnectionPortNumber()), writer, killChannel, debug)
+	runner, err := startLegacyRunner(manifest, strconv.Itoa(handler.ConnectionPortNumber()), writer, killChannel, debug, envVars)
 	if err != nil {
 		return nil, err
 	}
@@ -236,6 +242,18 @@ func Start(manifest *manifest.Manifest, stream int, killChannel chan bool, debug
 	return runner, err
 }
 
+// streamEnv returns the env vars specific to the runner of a parallel stream, streams are numbered from 1.
+func streamEnv(stream int) ([]string, error) {
+	if stream < 1 {
+		return nil, nil
+	}
+	envVars, err := env.StreamEnv(stream)
+	if err != nil {
+		return nil, err
+	}
+	return append(envVars, fmt.Sprintf("%s=%d", env.StreamID, stream)), nil
+}
+
 func connect(h *conn.GaugeConnectionHandler, runner *LegacyRunner) error {
 	connection, connErr := h.AcceptConnection(config.RunnerConnectionTimeout(), runner.errorChannel)
 	if connErr != nil {
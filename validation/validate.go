@@ -135,8 +135,8 @@ func Validate(args []string) {
 	logger.Infof(true, "No errors found.")
 }
 
-//TODO : duplicate in execute.go. Need to fix runner init.
-func startAPI(debug bool) runner.Runner {
+// StartRunner starts the gauge API along with the language runner and waits for the runner to connect.
+func StartRunner(debug bool) runner.Runner {
 	sc := api.StartAPI(debug)
 	select {
 	case runner := <-sc.RunnerChan:
@@ -170,12 +170,8 @@ func ValidateSpecs(specsToValidate []string, debug bool) *ValidationResult {
 	errMap := gauge.NewBuildErrors()
 	specs, specsFailed := parser.ParseSpecs(specsToValidate, conceptDict, errMap)
 	logger.Debug(true, "Parsing completed.")
-	r := startAPI(debug)
-	validationErrors := NewValidator(specs, r, conceptDict).Validate()
-	errMap = getErrMap(errMap, validationErrors)
-	specs = parser.GetSpecsForDataTableRows(specs, errMap)
-	printValidationFailures(validationErrors)
-	showSuggestion(validationErrors)
+	r := StartRunner(debug)
+	specs, errMap = ValidateParsedSpecs(specs, r, conceptDict, errMap)
 	if !res.Ok {
 		err := r.Kill()
 		if err != nil {
@@ -189,6 +185,17 @@ func ValidateSpecs(specsToValidate []string, debug bool) *ValidationResult {
 	return NewValidationResult(gauge.NewSpecCollection(specs, false), errMap, r, true)
 }
 
+// ValidateParsedSpecs validates the given specs using an already started runner and prints the validation failures.
+// It returns the specs to execute, i.e. with the selected data table rows, along with the build errors.
+func ValidateParsedSpecs(specs []*gauge.Specification, r runner.Runner, conceptDict *gauge.ConceptDictionary, errMap *gauge.BuildErrors) ([]*gauge.Specification, *gauge.BuildErrors) {
+	validationErrors := NewValidator(specs, r, conceptDict).Validate()
+	errMap = getErrMap(errMap, validationErrors)
+	specs = parser.GetSpecsForDataTableRows(specs, errMap)
+	printValidationFailures(validationErrors)
+	showSuggestion(validationErrors)
+	return specs, errMap
+}
+
 func getErrMap(errMap *gauge.BuildErrors, validationErrors validationErrors) *gauge.BuildErrors {
 	for spec, valErrors := range validationErrors {
 		for _, err := range valErrors {